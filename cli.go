@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wind/skill-router/internal/manifest"
+	"github.com/wind/skill-router/internal/mcp"
+	"github.com/wind/skill-router/internal/profile"
+	"github.com/wind/skill-router/internal/service"
+)
+
+// runCommand handles the command-line mode of skill-router and returns the
+// process exit code.
+func runCommand(svc *service.SkillService, store *profile.Store, name string, args []string) int {
+	switch name {
+	case "plan", "apply":
+		return runManifest(svc, name, args)
+	case "export":
+		return runExport(svc)
+	case "orphans":
+		return runOrphans(svc, args)
+	case "backup":
+		return runBackup(svc, args)
+	case "restore":
+		return runRestore(svc, args)
+	case "mcp":
+		return runMCP(svc, store)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n", name)
+		fmt.Fprintln(os.Stderr, "usage: skill-router [plan|apply|export|orphans|backup|restore|mcp] [flags]")
+		return 2
+	}
+}
+
+func runManifest(svc *service.SkillService, name string, args []string) int {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	file := fs.String("f", manifest.DefaultFile, "manifest file")
+	prune := fs.Bool("prune", false, "delete user skills not listed in the manifest")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	m, err := manifest.Load(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load manifest: %v\n", err)
+		return 1
+	}
+
+	plan, err := manifest.Diff(svc, m, manifest.Options{Prune: *prune})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "plan: %v\n", err)
+		return 1
+	}
+
+	if len(plan.Actions) == 0 {
+		fmt.Println("Nothing to do, skills match the manifest.")
+		return 0
+	}
+
+	if name == "plan" {
+		for _, a := range plan.Actions {
+			fmt.Println("  " + a.String())
+		}
+		fmt.Printf("%d change(s). Run 'skill-router apply' to apply them.\n", len(plan.Actions))
+		return 0
+	}
+
+	failed := 0
+	for _, r := range manifest.Apply(svc, plan) {
+		if r.Error != "" {
+			failed++
+			fmt.Printf("  FAIL %s: %s\n", r.Action, r.Error)
+			continue
+		}
+		fmt.Printf("  ok   %s\n", r.Action)
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d change(s) failed\n", failed, len(plan.Actions))
+		return 1
+	}
+	return 0
+}
+
+func runExport(svc *service.SkillService) int {
+	m, err := manifest.Current(svc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func runOrphans(svc *service.SkillService, args []string) int {
+	fs := flag.NewFlagSet("orphans", flag.ContinueOnError)
+	prune := fs.Bool("prune", false, "remove the orphaned entries")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	orphans, err := svc.FindOrphanedOverrides()
+	if *prune {
+		orphans, err = svc.PruneOrphanedOverrides()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "orphans: %v\n", err)
+		return 1
+	}
+
+	if orphans.Empty() {
+		fmt.Println("No orphaned overrides.")
+		return 0
+	}
+
+	for _, name := range orphans.DisabledPlugins {
+		fmt.Printf("  plugin %s\n", name)
+	}
+	for _, key := range orphans.Disabled {
+		fmt.Printf("  skill  %s\n", key)
+	}
+	if *prune {
+		fmt.Printf("Removed %d orphaned override(s).\n", len(orphans.Disabled)+len(orphans.DisabledPlugins))
+	} else {
+		fmt.Println("Run 'skill-router orphans -prune' to remove them.")
+	}
+	return 0
+}
+
+func runBackup(svc *service.SkillService, args []string) int {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	out := fs.String("o", "", "archive file to write (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "backup: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := svc.ExportBackup(w); err != nil {
+		fmt.Fprintf(os.Stderr, "backup: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func runRestore(svc *service.SkillService, args []string) int {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	file := fs.String("f", "", "archive file to restore")
+	mode := fs.String("mode", service.RestoreMerge, "merge or replace")
+	dryRun := fs.Bool("dry-run", false, "only report what would change")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "restore: -f is required")
+		return 2
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "restore: %v\n", err)
+		return 1
+	}
+	defer f.Close()
+
+	report, err := svc.RestoreBackup(f, service.RestoreOptions{Mode: *mode, DryRun: *dryRun})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "restore: %v\n", err)
+		return 1
+	}
+
+	for _, skill := range report.Skills {
+		fmt.Printf("  %-7s %s\n", skill.Action, skill.Name)
+	}
+	for _, key := range report.Overrides.AddedDisabled {
+		fmt.Printf("  disable %s\n", key)
+	}
+	for _, key := range report.Overrides.RemovedDisabled {
+		fmt.Printf("  enable  %s\n", key)
+	}
+	for _, name := range report.Overrides.AddedDisabledPlugins {
+		fmt.Printf("  disable plugin %s\n", name)
+	}
+	for _, name := range report.Overrides.RemovedDisabledPlugins {
+		fmt.Printf("  enable  plugin %s\n", name)
+	}
+	for _, p := range report.MissingPlugins {
+		fmt.Printf("Plugin %s %s (%s) is not installed here.\n", p.Name, p.Version, p.Org)
+	}
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	if *dryRun {
+		fmt.Println("Dry run, nothing was changed.")
+	}
+	return 0
+}
+
+// runMCP serves the Model Context Protocol on stdin and stdout until stdin
+// is closed.
+func runMCP(svc *service.SkillService, store *profile.Store) int {
+	if err := mcp.NewServer(svc, store).Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "mcp: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,157 @@
+// Package client drives a running Skill Router over its HTTP API. The
+// methods and types in client_gen.go are generated from the OpenAPI
+// document served at /api/openapi.json.
+package client
+
+//go:generate go run ../internal/openapi/genclient -o client_gen.go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultBaseURL is where Skill Router listens unless told otherwise.
+const DefaultBaseURL = "http://localhost:9527"
+
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client // http.DefaultClient when nil
+}
+
+// New returns a client for the Skill Router at baseURL, such as
+// DefaultBaseURL.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Error is returned for a response with an unexpected status. Problem holds
+// the problem details the server sent, if any.
+type Error struct {
+	StatusCode int
+	Problem    *Problem
+}
+
+func (e *Error) Error() string {
+	if e.Problem != nil && e.Problem.Detail != "" {
+		return fmt.Sprintf("skill-router: %d %s: %s", e.StatusCode, e.Problem.Code, e.Problem.Detail)
+	}
+	return fmt.Sprintf("skill-router: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Code returns the problem code of err if it wraps an *Error, or "".
+func Code(err error) ErrorCode {
+	var e *Error
+	if errors.As(err, &e) && e.Problem != nil {
+		return e.Problem.Code
+	}
+	return ""
+}
+
+// File is a file sent in a multipart upload.
+type File struct {
+	Name    string
+	Content io.Reader
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body io.Reader, contentType string) (*http.Response, error) {
+	u := strings.TrimSuffix(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if body != nil && contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return httpClient.Do(req)
+}
+
+// responseError reads the problem details of a failed response.
+func responseError(resp *http.Response) error {
+	e := &Error{StatusCode: resp.StatusCode}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/problem+json") {
+		var p Problem
+		if err := json.NewDecoder(resp.Body).Decode(&p); err == nil {
+			e.Problem = &p
+		}
+	}
+	return e
+}
+
+func decodeJSON(resp *http.Response, out any) error {
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("skill-router: decode response: %w", err)
+	}
+	return nil
+}
+
+func jsonBody(v any) (io.Reader, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// escapeSegments escapes each segment of a slash-separated path.
+func escapeSegments(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// form builds a multipart body, remembering the first error.
+type form struct {
+	buf bytes.Buffer
+	w   *multipart.Writer
+	err error
+}
+
+func newForm() *form {
+	f := &form{}
+	f.w = multipart.NewWriter(&f.buf)
+	return f
+}
+
+func (f *form) file(field string, file File) {
+	if f.err != nil || file.Content == nil {
+		return
+	}
+	fw, err := f.w.CreateFormFile(field, file.Name)
+	if err == nil {
+		_, err = io.Copy(fw, file.Content)
+	}
+	f.err = err
+}
+
+func (f *form) field(field, value string) {
+	if f.err == nil {
+		f.err = f.w.WriteField(field, value)
+	}
+}
+
+func (f *form) encode() (io.Reader, string, error) {
+	if f.err == nil {
+		f.err = f.w.Close()
+	}
+	return &f.buf, f.w.FormDataContentType(), f.err
+}
@@ -0,0 +1,1655 @@
+// Code generated by genclient from internal/openapi/openapi.json. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Action struct {
+	Op     string `json:"op"`
+	Target string `json:"target"`
+	Source string `json:"source,omitempty"`
+	Ref    string `json:"ref,omitempty"`
+}
+
+type ActivateResult struct {
+	Applied []Action `json:"applied"`
+}
+
+type ApplyResult struct {
+	Results []ManifestResult `json:"results"`
+}
+
+// BudgetGroup is the enabled skills of one source or plugin.
+type BudgetGroup struct {
+	Name   string `json:"name"`
+	Skills int    `json:"skills"`
+	Tokens int    `json:"tokens"`
+}
+
+// BudgetWarning is skill is the ID of the skill a long-description warning is
+// about.
+type BudgetWarning struct {
+	Kind    string `json:"kind"`
+	Skill   string `json:"skill,omitempty"`
+	Message string `json:"message"`
+}
+
+type BulkRequest struct {
+	Action  string       `json:"action"`
+	Targets []BulkTarget `json:"targets"`
+}
+
+// BulkResponse is the outcome of a bulk action. When applied is false nothing
+// was changed.
+type BulkResponse struct {
+	Applied bool         `json:"applied"`
+	Error   string       `json:"error,omitempty"`
+	Results []BulkResult `json:"results"`
+}
+
+type BulkResult struct {
+	Target BulkTarget `json:"target"`
+	Status string     `json:"status"`
+	Error  string     `json:"error,omitempty"`
+}
+
+type BulkTarget struct {
+	Type   string `json:"type"`
+	Name   string `json:"name,omitempty"`
+	Plugin string `json:"plugin,omitempty"`
+}
+
+type ChmodRequest struct {
+	Executable bool `json:"executable"`
+}
+
+// Collision is a set of skills that share a name or look alike.
+type Collision struct {
+	Kind        string       `json:"kind"`
+	Name        string       `json:"name"`
+	Similarity  float64      `json:"similarity"`
+	Skills      []SkillRef   `json:"skills"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// ContextBudget is estimated tokens taken up by the names and descriptions of
+// the enabled skills.
+type ContextBudget struct {
+	Budget   int             `json:"budget"`
+	Total    int             `json:"total"`
+	Skills   int             `json:"skills"`
+	BySource []BudgetGroup   `json:"bySource"`
+	ByPlugin []BudgetGroup   `json:"byPlugin"`
+	Warnings []BudgetWarning `json:"warnings"`
+}
+
+type CreateProfileRequest struct {
+	Name      string `json:"name"`
+	Overwrite bool   `json:"overwrite,omitempty"`
+}
+
+// ErrorCode is a stable code for the kind of error.
+type ErrorCode string
+
+const (
+	ErrorCodeNotFound             ErrorCode = "not-found"
+	ErrorCodeAlreadyExists        ErrorCode = "already-exists"
+	ErrorCodeInvalidName          ErrorCode = "invalid-name"
+	ErrorCodeInvalidFrontmatter   ErrorCode = "invalid-frontmatter"
+	ErrorCodeInvalidRequest       ErrorCode = "invalid-request"
+	ErrorCodeUpstreamFailure      ErrorCode = "upstream-failure"
+	ErrorCodeRateLimited          ErrorCode = "rate-limited"
+	ErrorCodeStaleContent         ErrorCode = "stale-content"
+	ErrorCodePreconditionRequired ErrorCode = "precondition-required"
+	ErrorCodePayloadTooLarge      ErrorCode = "payload-too-large"
+	ErrorCodeConflict             ErrorCode = "conflict"
+	ErrorCodeInternalError        ErrorCode = "internal-error"
+)
+
+type ForkRequest struct {
+	Name            string `json:"name,omitempty"`
+	DisableOriginal bool   `json:"disableOriginal,omitempty"`
+}
+
+// History is a page of journal entries, newest first.
+type History struct {
+	Entries []HistoryEntry `json:"entries"`
+	CanUndo int            `json:"canUndo"`
+	CanRedo int            `json:"canRedo"`
+}
+
+type HistoryEntry struct {
+	Seq  int       `json:"seq"`
+	Time time.Time `json:"time"`
+	Kind string    `json:"kind"`
+	Ref  int       `json:"ref,omitempty"`
+	Op   Operation `json:"op"`
+}
+
+type InstallRequest struct {
+	URL string `json:"url"`
+}
+
+type InstallResult struct {
+	Installed int `json:"installed"`
+}
+
+// Manifest is the desired set of user skills and plugin overrides.
+type Manifest struct {
+	Skills          []SkillSpec `json:"skills,omitempty"`
+	Disabled        []string    `json:"disabled,omitempty"`
+	DisabledPlugins []string    `json:"disabledPlugins,omitempty"`
+}
+
+type ManifestResult struct {
+	Action Action `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Operation is a journaled change. Skill contents are left out of API
+// responses.
+type Operation struct {
+	Type         string           `json:"type"`
+	Target       string           `json:"target,omitempty"`
+	Path         string           `json:"path,omitempty"`
+	NewName      string           `json:"newName,omitempty"`
+	Enabled      bool             `json:"enabled,omitempty"`
+	TrashID      string           `json:"trashId,omitempty"`
+	Version      string           `json:"version,omitempty"`
+	Saved        string           `json:"saved,omitempty"`
+	Content      []byte           `json:"content,omitempty"`
+	Previous     []byte           `json:"previous,omitempty"`
+	ContentBlob  string           `json:"contentBlob,omitempty"`
+	PreviousBlob string           `json:"previousBlob,omitempty"`
+	Change       *OverridesChange `json:"change,omitempty"`
+	Steps        []Operation      `json:"steps,omitempty"`
+}
+
+// OrphanedOverrides is the overrides naming plugins or plugin skills that are
+// not installed.
+type OrphanedOverrides struct {
+	Disabled        []string `json:"disabled"`
+	DisabledPlugins []string `json:"disabledPlugins"`
+}
+
+type OverridesChange struct {
+	AddedDisabled          []string `json:"addedDisabled,omitempty"`
+	RemovedDisabled        []string `json:"removedDisabled,omitempty"`
+	AddedDisabledPlugins   []string `json:"addedDisabledPlugins,omitempty"`
+	RemovedDisabledPlugins []string `json:"removedDisabledPlugins,omitempty"`
+}
+
+type Plan struct {
+	Actions []Action `json:"actions"`
+}
+
+type PluginInfo struct {
+	Org     string `json:"org"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Problem is an RFC 9457 problem details body, sent with every error response.
+type Problem struct {
+	Title  string    `json:"title"`
+	Status int       `json:"status"`
+	Code   ErrorCode `json:"code"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// Profile is a saved set of enabled skills and plugin overrides.
+type Profile struct {
+	Name            string    `json:"name"`
+	CreatedAt       time.Time `json:"createdAt"`
+	EnabledSkills   []string  `json:"enabledSkills"`
+	DisabledSkills  []string  `json:"disabledSkills"`
+	Disabled        []string  `json:"disabled"`
+	DisabledPlugins []string  `json:"disabledPlugins"`
+}
+
+// ProfileInfo is a profile and whether it matches the current state.
+type ProfileInfo struct {
+	Name            string    `json:"name"`
+	CreatedAt       time.Time `json:"createdAt"`
+	EnabledSkills   []string  `json:"enabledSkills"`
+	DisabledSkills  []string  `json:"disabledSkills"`
+	Disabled        []string  `json:"disabled"`
+	DisabledPlugins []string  `json:"disabledPlugins"`
+	Active          bool      `json:"active"`
+}
+
+// ProjectOverrideRequest is a request to set the state of a skill or plugin in
+// one project. project is the absolute path of a project with a .claude
+// directory or that Claude has been run in.
+type ProjectOverrideRequest struct {
+	Project string `json:"project"`
+	Plugin  string `json:"plugin,omitempty"`
+	Skill   string `json:"skill,omitempty"`
+	State   string `json:"state"`
+}
+
+// RenameRequest is the new name of a renamed or duplicated skill.
+type RenameRequest struct {
+	Name string `json:"name"`
+}
+
+type RestoreReport struct {
+	Mode           string          `json:"mode"`
+	DryRun         bool            `json:"dryRun"`
+	Skills         []RestoreSkill  `json:"skills"`
+	Overrides      OverridesChange `json:"overrides"`
+	MissingPlugins []PluginInfo    `json:"missingPlugins"`
+	Warnings       []string        `json:"warnings"`
+}
+
+type RestoreSkill struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Action  string `json:"action"`
+}
+
+type RevertRequest struct {
+	Version string `json:"version"`
+}
+
+type SearchResult struct {
+	Skill   Skill         `json:"skill"`
+	Score   int           `json:"score"`
+	Snippet []SnippetPart `json:"snippet"`
+}
+
+// Skill is an installed skill. tokens estimates the context its name and
+// description take up while enabled. conflicts lists the IDs of enabled skills
+// this one collides with.
+type Skill struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	FileName     string   `json:"fileName"`
+	FilePath     string   `json:"filePath"`
+	Enabled      bool     `json:"enabled"`
+	Source       string   `json:"source"`
+	PluginName   string   `json:"pluginName"`
+	StateLayer   string   `json:"stateLayer"`
+	Tokens       int      `json:"tokens"`
+	AllowedTools []string `json:"allowedTools,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Conflicts    []string `json:"conflicts,omitempty"`
+}
+
+// SkillFile is a file or directory inside a skill directory.
+type SkillFile struct {
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	Mode       string    `json:"mode"`
+	IsDir      bool      `json:"isDir"`
+	Executable bool      `json:"executable"`
+	ModTime    time.Time `json:"modTime"`
+}
+
+// SkillOrigin is the plugin skill a user skill was forked from.
+type SkillOrigin struct {
+	Plugin   string    `json:"plugin"`
+	Skill    string    `json:"skill"`
+	Version  string    `json:"version"`
+	Path     string    `json:"path"`
+	ForkedAt time.Time `json:"forkedAt"`
+}
+
+type SkillRef struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	FileName   string `json:"fileName"`
+	Source     string `json:"source"`
+	PluginName string `json:"pluginName,omitempty"`
+}
+
+type SkillSpec struct {
+	Name    string `json:"name"`
+	Source  string `json:"source,omitempty"`
+	Ref     string `json:"ref,omitempty"`
+	Enabled *bool  `json:"enabled,omitempty"`
+}
+
+// SkillUsage is how often a skill was invoked in the recorded transcripts.
+// unused marks a skill enabled for longer than the threshold without being
+// used in it.
+type SkillUsage struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	FileName     string    `json:"fileName"`
+	Source       string    `json:"source"`
+	PluginName   string    `json:"pluginName,omitempty"`
+	Enabled      bool      `json:"enabled"`
+	EnabledSince time.Time `json:"enabledSince,omitempty"`
+	LastUsed     time.Time `json:"lastUsed,omitempty"`
+	Last7Days    int       `json:"last7Days"`
+	Last30Days   int       `json:"last30Days"`
+	Last90Days   int       `json:"last90Days"`
+	Total        int       `json:"total"`
+	Unused       bool      `json:"unused"`
+}
+
+type SkillVersion struct {
+	Hash    string    `json:"hash"`
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+type SnippetPart struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match,omitempty"`
+}
+
+type Suggestion struct {
+	Action string `json:"action"`
+	Skill  string `json:"skill"`
+	Reason string `json:"reason"`
+}
+
+type TrashItem struct {
+	ID           string             `json:"id"`
+	Kind         string             `json:"kind"`
+	Name         string             `json:"name"`
+	Source       string             `json:"source"`
+	OriginalPath string             `json:"originalPath"`
+	Enabled      bool               `json:"enabled"`
+	DeletedAt    time.Time          `json:"deletedAt"`
+	Overrides    *OrphanedOverrides `json:"overrides,omitempty"`
+}
+
+// UnknownUsage is invocations of a name no installed skill has.
+type UnknownUsage struct {
+	Skill    string    `json:"skill"`
+	Total    int       `json:"total"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// UsageReport is skill usage found in Claude Code transcripts, unused skills
+// first, then the most used.
+type UsageReport struct {
+	UnusedAfterDays int            `json:"unusedAfterDays"`
+	FirstRecorded   time.Time      `json:"firstRecorded,omitempty"`
+	Skills          []SkillUsage   `json:"skills"`
+	Unknown         []UnknownUsage `json:"unknown"`
+}
+
+type VersioningStatus struct {
+	Enabled bool `json:"enabled"`
+}
+
+// ExportBackup downloads a backup of user skills and overrides. The caller
+// must close the returned body.
+func (c *Client) ExportBackup(ctx context.Context) (io.ReadCloser, error) {
+	urlPath := "/api/backup"
+	resp, err := c.do(ctx, "GET", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		defer resp.Body.Close()
+		return nil, responseError(resp)
+	}
+	return resp.Body, nil
+}
+
+// RestoreBackupForm is the multipart form sent by RestoreBackup.
+type RestoreBackupForm struct {
+	File File
+}
+
+// RestoreBackupParams holds the optional parameters of RestoreBackup.
+type RestoreBackupParams struct {
+	// Defaults to merge.
+	Mode   string
+	DryRun *bool
+}
+
+// RestoreBackup restores a backup archive.
+func (c *Client) RestoreBackup(ctx context.Context, body RestoreBackupForm, params *RestoreBackupParams) (*RestoreReport, error) {
+	urlPath := "/api/backup/restore"
+	query := url.Values{}
+	if params != nil {
+		if params.Mode != "" {
+			query.Set("mode", params.Mode)
+		}
+		if params.DryRun != nil {
+			query.Set("dryRun", strconv.FormatBool(*params.DryRun))
+		}
+	}
+	form := newForm()
+	form.file("file", body.File)
+	reqBody, contentType, err := form.encode()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, "POST", urlPath, query, nil, reqBody, contentType)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out RestoreReport
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StreamEvents streams a change event whenever skills or overrides change on
+// disk. The caller must close the returned body.
+func (c *Client) StreamEvents(ctx context.Context) (io.ReadCloser, error) {
+	urlPath := "/api/events"
+	resp, err := c.do(ctx, "GET", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		defer resp.Body.Close()
+		return nil, responseError(resp)
+	}
+	return resp.Body, nil
+}
+
+// GetHistoryParams holds the optional parameters of GetHistory.
+type GetHistoryParams struct {
+	Limit *int
+}
+
+// GetHistory lists journaled changes, newest first.
+func (c *Client) GetHistory(ctx context.Context, params *GetHistoryParams) (*History, error) {
+	urlPath := "/api/history"
+	query := url.Values{}
+	if params != nil {
+		if params.Limit != nil {
+			query.Set("limit", strconv.Itoa(*params.Limit))
+		}
+	}
+	resp, err := c.do(ctx, "GET", urlPath, query, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out History
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Redo reapplies the latest undone change.
+func (c *Client) Redo(ctx context.Context) (*HistoryEntry, error) {
+	urlPath := "/api/history/redo"
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out HistoryEntry
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Undo reverts the latest change.
+func (c *Client) Undo(ctx context.Context) (*HistoryEntry, error) {
+	urlPath := "/api/history/undo"
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out HistoryEntry
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetManifest describes the current skills as a manifest.
+func (c *Client) GetManifest(ctx context.Context) (*Manifest, error) {
+	urlPath := "/api/manifest"
+	resp, err := c.do(ctx, "GET", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out Manifest
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ApplyManifestParams holds the optional parameters of ApplyManifest.
+type ApplyManifestParams struct {
+	// Delete user skills the manifest does not list.
+	Prune *bool
+}
+
+// ApplyManifest makes the current state match a manifest.
+func (c *Client) ApplyManifest(ctx context.Context, body Manifest, params *ApplyManifestParams) (*ApplyResult, error) {
+	urlPath := "/api/manifest/apply"
+	query := url.Values{}
+	if params != nil {
+		if params.Prune != nil {
+			query.Set("prune", strconv.FormatBool(*params.Prune))
+		}
+	}
+	reqBody, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, "POST", urlPath, query, nil, reqBody, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out ApplyResult
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PlanManifestParams holds the optional parameters of PlanManifest.
+type PlanManifestParams struct {
+	// Delete user skills the manifest does not list.
+	Prune *bool
+}
+
+// PlanManifest lists the actions that would make the current state match a
+// manifest.
+func (c *Client) PlanManifest(ctx context.Context, body Manifest, params *PlanManifestParams) (*Plan, error) {
+	urlPath := "/api/manifest/plan"
+	query := url.Values{}
+	if params != nil {
+		if params.Prune != nil {
+			query.Set("prune", strconv.FormatBool(*params.Prune))
+		}
+	}
+	reqBody, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, "POST", urlPath, query, nil, reqBody, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out Plan
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOpenAPI returns this document.
+func (c *Client) GetOpenAPI(ctx context.Context) (json.RawMessage, error) {
+	urlPath := "/api/openapi.json"
+	resp, err := c.do(ctx, "GET", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out json.RawMessage
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListOrphanedOverrides lists overrides for plugins or skills that are not
+// installed.
+func (c *Client) ListOrphanedOverrides(ctx context.Context) (*OrphanedOverrides, error) {
+	urlPath := "/api/overrides/orphans"
+	resp, err := c.do(ctx, "GET", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out OrphanedOverrides
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PruneOrphanedOverrides removes overrides for plugins or skills that are not
+// installed.
+func (c *Client) PruneOrphanedOverrides(ctx context.Context) (*OrphanedOverrides, error) {
+	urlPath := "/api/overrides/orphans/prune"
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out OrphanedOverrides
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeletePlugin moves a plugin to the trash.
+func (c *Client) DeletePlugin(ctx context.Context, plugin string) error {
+	urlPath := "/api/plugins/" + url.PathEscape(plugin)
+	resp, err := c.do(ctx, "DELETE", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// DisablePlugin disables every skill of a plugin.
+func (c *Client) DisablePlugin(ctx context.Context, plugin string) error {
+	urlPath := "/api/plugins/" + url.PathEscape(plugin) + "/disable"
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// EnablePlugin re-enables a disabled plugin.
+func (c *Client) EnablePlugin(ctx context.Context, plugin string) error {
+	urlPath := "/api/plugins/" + url.PathEscape(plugin) + "/enable"
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// GetPluginSkillContentParams holds the optional parameters of GetPluginSkillContent.
+type GetPluginSkillContentParams struct {
+	IfNoneMatch string
+}
+
+// GetPluginSkillContentResult is the response of GetPluginSkillContent.
+type GetPluginSkillContentResult struct {
+	StatusCode int
+	ETag       string
+	Body       []byte
+}
+
+// GetPluginSkillContent reads the SKILL.md of a plugin skill.
+func (c *Client) GetPluginSkillContent(ctx context.Context, plugin string, skill string, params *GetPluginSkillContentParams) (*GetPluginSkillContentResult, error) {
+	urlPath := "/api/plugins/" + url.PathEscape(plugin) + "/skills/" + url.PathEscape(skill) + "/content"
+	header := http.Header{}
+	if params != nil {
+		if params.IfNoneMatch != "" {
+			header.Set("If-None-Match", params.IfNoneMatch)
+		}
+	}
+	resp, err := c.do(ctx, "GET", urlPath, nil, header, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 && resp.StatusCode != 304 {
+		return nil, responseError(resp)
+	}
+	out := &GetPluginSkillContentResult{StatusCode: resp.StatusCode}
+	out.ETag = resp.Header.Get("ETag")
+	if resp.StatusCode == 200 {
+		if out.Body, err = io.ReadAll(resp.Body); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// DisablePluginSkill disables one skill of a plugin.
+func (c *Client) DisablePluginSkill(ctx context.Context, plugin string, skill string) error {
+	urlPath := "/api/plugins/" + url.PathEscape(plugin) + "/skills/" + url.PathEscape(skill) + "/disable"
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// EnablePluginSkill enables one skill of a plugin.
+func (c *Client) EnablePluginSkill(ctx context.Context, plugin string, skill string) error {
+	urlPath := "/api/plugins/" + url.PathEscape(plugin) + "/skills/" + url.PathEscape(skill) + "/enable"
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// ForkPluginSkill copies a plugin skill into the user skills.
+func (c *Client) ForkPluginSkill(ctx context.Context, plugin string, skill string, body ForkRequest) (*SkillOrigin, error) {
+	urlPath := "/api/plugins/" + url.PathEscape(plugin) + "/skills/" + url.PathEscape(skill) + "/fork"
+	reqBody, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, reqBody, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return nil, responseError(resp)
+	}
+	var out SkillOrigin
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListProfiles lists saved profiles.
+func (c *Client) ListProfiles(ctx context.Context) ([]ProfileInfo, error) {
+	urlPath := "/api/profiles"
+	resp, err := c.do(ctx, "GET", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out []ProfileInfo
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateProfile saves the current state as a profile.
+func (c *Client) CreateProfile(ctx context.Context, body CreateProfileRequest) (*Profile, error) {
+	urlPath := "/api/profiles"
+	reqBody, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, reqBody, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return nil, responseError(resp)
+	}
+	var out Profile
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteProfile deletes a profile.
+func (c *Client) DeleteProfile(ctx context.Context, name string) error {
+	urlPath := "/api/profiles/" + url.PathEscape(name)
+	resp, err := c.do(ctx, "DELETE", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// ActivateProfile makes the current state match a profile.
+func (c *Client) ActivateProfile(ctx context.Context, name string) (*ActivateResult, error) {
+	urlPath := "/api/profiles/" + url.PathEscape(name) + "/activate"
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out ActivateResult
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DiffProfile lists the actions that activating a profile would take.
+func (c *Client) DiffProfile(ctx context.Context, name string) (*Plan, error) {
+	urlPath := "/api/profiles/" + url.PathEscape(name) + "/diff"
+	resp, err := c.do(ctx, "GET", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out Plan
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetProjectOverride enables or disables a skill or plugin in one project.
+func (c *Client) SetProjectOverride(ctx context.Context, body ProjectOverrideRequest) error {
+	urlPath := "/api/project/overrides"
+	reqBody, err := jsonBody(body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, reqBody, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// ListSkillsParams holds the optional parameters of ListSkills.
+type ListSkillsParams struct {
+	// Absolute path of a project, with a .claude directory or that Claude has
+	// been run in, whose skills and overrides are included.
+	Project string
+}
+
+// ListSkills lists user, project and plugin skills.
+func (c *Client) ListSkills(ctx context.Context, params *ListSkillsParams) ([]Skill, error) {
+	urlPath := "/api/skills"
+	query := url.Values{}
+	if params != nil {
+		if params.Project != "" {
+			query.Set("project", params.Project)
+		}
+	}
+	resp, err := c.do(ctx, "GET", urlPath, query, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out []Skill
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetContextBudgetParams holds the optional parameters of GetContextBudget.
+type GetContextBudgetParams struct {
+	// Absolute path of a project, with a .claude directory or that Claude has
+	// been run in, whose skills and overrides are included.
+	Project string
+	// Token budget to warn above.
+	Budget *int
+}
+
+// GetContextBudget estimates the context taken up by enabled skill metadata
+// per source and plugin.
+func (c *Client) GetContextBudget(ctx context.Context, params *GetContextBudgetParams) (*ContextBudget, error) {
+	urlPath := "/api/skills/budget"
+	query := url.Values{}
+	if params != nil {
+		if params.Project != "" {
+			query.Set("project", params.Project)
+		}
+		if params.Budget != nil {
+			query.Set("budget", strconv.Itoa(*params.Budget))
+		}
+	}
+	resp, err := c.do(ctx, "GET", urlPath, query, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out ContextBudget
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BulkAction enables, disables or deletes several skills and plugins in one
+// transaction. A 422 response is decoded too and returned along with an
+// *Error.
+func (c *Client) BulkAction(ctx context.Context, body BulkRequest) (*BulkResponse, error) {
+	urlPath := "/api/skills/bulk"
+	reqBody, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, reqBody, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 && resp.StatusCode != 422 {
+		return nil, responseError(resp)
+	}
+	var out BulkResponse
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == 422 {
+		return &out, &Error{StatusCode: resp.StatusCode}
+	}
+	return &out, nil
+}
+
+// ListCollisionsParams holds the optional parameters of ListCollisions.
+type ListCollisionsParams struct {
+	// Absolute path of a project, with a .claude directory or that Claude has
+	// been run in, whose skills and overrides are included.
+	Project string
+}
+
+// ListCollisions lists skills that share a name or look alike.
+func (c *Client) ListCollisions(ctx context.Context, params *ListCollisionsParams) ([]Collision, error) {
+	urlPath := "/api/skills/collisions"
+	query := url.Values{}
+	if params != nil {
+		if params.Project != "" {
+			query.Set("project", params.Project)
+		}
+	}
+	resp, err := c.do(ctx, "GET", urlPath, query, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out []Collision
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InstallSkills installs the skills of a GitHub repository.
+func (c *Client) InstallSkills(ctx context.Context, body InstallRequest) (*InstallResult, error) {
+	urlPath := "/api/skills/install"
+	reqBody, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, reqBody, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out InstallResult
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SearchSkillsParams holds the optional parameters of SearchSkills.
+type SearchSkillsParams struct {
+	// Text to match against names, descriptions and bodies.
+	Q string
+	// Absolute path of a project, with a .claude directory or that Claude has
+	// been run in, whose skills and overrides are included.
+	Project string
+	Source  string
+	Plugin  string
+	Enabled *bool
+	// Tools every result must allow. Repeated or comma separated.
+	Tool []string
+	// Tags every result must have. Repeated or comma separated.
+	Tag []string
+}
+
+// SearchSkills searches skills by text and filters, best match first.
+func (c *Client) SearchSkills(ctx context.Context, params *SearchSkillsParams) ([]SearchResult, error) {
+	urlPath := "/api/skills/search"
+	query := url.Values{}
+	if params != nil {
+		if params.Q != "" {
+			query.Set("q", params.Q)
+		}
+		if params.Project != "" {
+			query.Set("project", params.Project)
+		}
+		if params.Source != "" {
+			query.Set("source", params.Source)
+		}
+		if params.Plugin != "" {
+			query.Set("plugin", params.Plugin)
+		}
+		if params.Enabled != nil {
+			query.Set("enabled", strconv.FormatBool(*params.Enabled))
+		}
+		for _, v := range params.Tool {
+			query.Add("tool", v)
+		}
+		for _, v := range params.Tag {
+			query.Add("tag", v)
+		}
+	}
+	resp, err := c.do(ctx, "GET", urlPath, query, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out []SearchResult
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UploadSkillForm is the multipart form sent by UploadSkill.
+type UploadSkillForm struct {
+	File      File
+	Overwrite bool
+}
+
+// UploadSkill installs a user skill from an uploaded SKILL.md.
+func (c *Client) UploadSkill(ctx context.Context, body UploadSkillForm) error {
+	urlPath := "/api/skills/upload"
+	form := newForm()
+	form.file("file", body.File)
+	form.field("overwrite", strconv.FormatBool(body.Overwrite))
+	reqBody, contentType, err := form.encode()
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, reqBody, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// GetSkillUsageParams holds the optional parameters of GetSkillUsage.
+type GetSkillUsageParams struct {
+	// Days a skill may stay enabled without use before it is flagged.
+	UnusedDays *int
+}
+
+// GetSkillUsage reports how often and how recently each skill was used in
+// Claude Code transcripts.
+func (c *Client) GetSkillUsage(ctx context.Context, params *GetSkillUsageParams) (*UsageReport, error) {
+	urlPath := "/api/skills/usage"
+	query := url.Values{}
+	if params != nil {
+		if params.UnusedDays != nil {
+			query.Set("unusedDays", strconv.Itoa(*params.UnusedDays))
+		}
+	}
+	resp, err := c.do(ctx, "GET", urlPath, query, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out UsageReport
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteSkillParams holds the optional parameters of DeleteSkill.
+type DeleteSkillParams struct {
+	// Whether the skill is currently enabled.
+	Enabled *bool
+}
+
+// DeleteSkill moves a user skill to the trash.
+func (c *Client) DeleteSkill(ctx context.Context, name string, params *DeleteSkillParams) error {
+	urlPath := "/api/skills/" + url.PathEscape(name)
+	query := url.Values{}
+	if params != nil {
+		if params.Enabled != nil {
+			query.Set("enabled", strconv.FormatBool(*params.Enabled))
+		}
+	}
+	resp, err := c.do(ctx, "DELETE", urlPath, query, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// GetSkillContentParams holds the optional parameters of GetSkillContent.
+type GetSkillContentParams struct {
+	IfNoneMatch string
+}
+
+// GetSkillContentResult is the response of GetSkillContent.
+type GetSkillContentResult struct {
+	StatusCode int
+	ETag       string
+	Body       []byte
+}
+
+// GetSkillContent reads the SKILL.md of a user skill.
+func (c *Client) GetSkillContent(ctx context.Context, name string, params *GetSkillContentParams) (*GetSkillContentResult, error) {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/content"
+	header := http.Header{}
+	if params != nil {
+		if params.IfNoneMatch != "" {
+			header.Set("If-None-Match", params.IfNoneMatch)
+		}
+	}
+	resp, err := c.do(ctx, "GET", urlPath, nil, header, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 && resp.StatusCode != 304 {
+		return nil, responseError(resp)
+	}
+	out := &GetSkillContentResult{StatusCode: resp.StatusCode}
+	out.ETag = resp.Header.Get("ETag")
+	if resp.StatusCode == 200 {
+		if out.Body, err = io.ReadAll(resp.Body); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// PutSkillContentResult is the response of PutSkillContent.
+type PutSkillContentResult struct {
+	ETag string
+}
+
+// PutSkillContent replaces the SKILL.md of a user skill.
+func (c *Client) PutSkillContent(ctx context.Context, name string, ifMatch string, body io.Reader) (*PutSkillContentResult, error) {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/content"
+	header := http.Header{}
+	header.Set("If-Match", ifMatch)
+	reqBody := body
+	resp, err := c.do(ctx, "PUT", urlPath, nil, header, reqBody, "text/markdown")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 204 {
+		return nil, responseError(resp)
+	}
+	out := &PutSkillContentResult{}
+	out.ETag = resp.Header.Get("ETag")
+	return out, nil
+}
+
+// DiffSkillParams holds the optional parameters of DiffSkill.
+type DiffSkillParams struct {
+	// Defaults to the working copy.
+	To string
+}
+
+// DiffSkill diffs two versions of a user skill.
+func (c *Client) DiffSkill(ctx context.Context, name string, from string, params *DiffSkillParams) ([]byte, error) {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/diff"
+	query := url.Values{}
+	query.Set("from", from)
+	if params != nil {
+		if params.To != "" {
+			query.Set("to", params.To)
+		}
+	}
+	resp, err := c.do(ctx, "GET", urlPath, query, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// DisableSkill disables a user skill.
+func (c *Client) DisableSkill(ctx context.Context, name string) error {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/disable"
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// DuplicateSkill copies a user skill under a new name.
+func (c *Client) DuplicateSkill(ctx context.Context, name string, body RenameRequest) error {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/duplicate"
+	reqBody, err := jsonBody(body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, reqBody, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// EnableSkill enables a user skill.
+func (c *Client) EnableSkill(ctx context.Context, name string) error {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/enable"
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// ListSkillFiles lists the files of a user skill.
+func (c *Client) ListSkillFiles(ctx context.Context, name string) ([]SkillFile, error) {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/files"
+	resp, err := c.do(ctx, "GET", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out []SkillFile
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UploadSkillFilesForm is the multipart form sent by UploadSkillFiles.
+type UploadSkillFilesForm struct {
+	File []File
+}
+
+// UploadSkillFiles uploads files into the top of a user skill.
+func (c *Client) UploadSkillFiles(ctx context.Context, name string, body UploadSkillFilesForm) ([]SkillFile, error) {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/files"
+	form := newForm()
+	for _, f := range body.File {
+		form.file("file", f)
+	}
+	reqBody, contentType, err := form.encode()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, reqBody, contentType)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return nil, responseError(resp)
+	}
+	var out []SkillFile
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReadSkillFile reads a file of a user skill.
+func (c *Client) ReadSkillFile(ctx context.Context, name string, path string) ([]byte, error) {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/files/" + escapeSegments(path)
+	resp, err := c.do(ctx, "GET", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// WriteSkillFile creates or replaces a file of a user skill.
+func (c *Client) WriteSkillFile(ctx context.Context, name string, path string, body io.Reader) (*SkillFile, error) {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/files/" + escapeSegments(path)
+	reqBody := body
+	resp, err := c.do(ctx, "PUT", urlPath, nil, nil, reqBody, "application/octet-stream")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out SkillFile
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UploadSkillFilesToForm is the multipart form sent by UploadSkillFilesTo.
+type UploadSkillFilesToForm struct {
+	File []File
+}
+
+// UploadSkillFilesTo uploads files into a directory of a user skill.
+func (c *Client) UploadSkillFilesTo(ctx context.Context, name string, path string, body UploadSkillFilesToForm) ([]SkillFile, error) {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/files/" + escapeSegments(path)
+	form := newForm()
+	for _, f := range body.File {
+		form.file("file", f)
+	}
+	reqBody, contentType, err := form.encode()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, reqBody, contentType)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return nil, responseError(resp)
+	}
+	var out []SkillFile
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChmodSkillFile sets whether a file of a user skill is executable.
+func (c *Client) ChmodSkillFile(ctx context.Context, name string, path string, body ChmodRequest) (*SkillFile, error) {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/files/" + escapeSegments(path)
+	reqBody, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, "PATCH", urlPath, nil, nil, reqBody, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out SkillFile
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteSkillFile moves a file or directory of a user skill to the trash.
+func (c *Client) DeleteSkillFile(ctx context.Context, name string, path string) error {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/files/" + escapeSegments(path)
+	resp, err := c.do(ctx, "DELETE", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// GetSkillOrigin tells which plugin skill a user skill was forked from.
+func (c *Client) GetSkillOrigin(ctx context.Context, name string) (*SkillOrigin, error) {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/origin"
+	resp, err := c.do(ctx, "GET", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out SkillOrigin
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RenameSkill renames a user skill.
+func (c *Client) RenameSkill(ctx context.Context, name string, body RenameRequest) error {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/rename"
+	reqBody, err := jsonBody(body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, reqBody, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// RevertSkill restores a user skill to an earlier version.
+func (c *Client) RevertSkill(ctx context.Context, name string, body RevertRequest) error {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/revert"
+	reqBody, err := jsonBody(body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, reqBody, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// ListSkillVersions lists the committed versions of a user skill.
+func (c *Client) ListSkillVersions(ctx context.Context, name string) ([]SkillVersion, error) {
+	urlPath := "/api/skills/" + url.PathEscape(name) + "/versions"
+	resp, err := c.do(ctx, "GET", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out []SkillVersion
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListTrash lists deleted skills, plugins and skill files.
+func (c *Client) ListTrash(ctx context.Context) ([]TrashItem, error) {
+	urlPath := "/api/trash"
+	resp, err := c.do(ctx, "GET", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out []TrashItem
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EmptyTrash permanently deletes everything in the trash.
+func (c *Client) EmptyTrash(ctx context.Context) error {
+	urlPath := "/api/trash"
+	resp, err := c.do(ctx, "DELETE", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// DeleteTrashItem permanently deletes one item from the trash.
+func (c *Client) DeleteTrashItem(ctx context.Context, id string) error {
+	urlPath := "/api/trash/" + url.PathEscape(id)
+	resp, err := c.do(ctx, "DELETE", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// RestoreTrashItem moves an item back to where it was deleted from.
+func (c *Client) RestoreTrashItem(ctx context.Context, id string) (*TrashItem, error) {
+	urlPath := "/api/trash/" + url.PathEscape(id) + "/restore"
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out TrashItem
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetVersioning tells whether user skills are versioned with git.
+func (c *Client) GetVersioning(ctx context.Context) (*VersioningStatus, error) {
+	urlPath := "/api/versioning"
+	resp, err := c.do(ctx, "GET", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out VersioningStatus
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// EnableVersioning starts versioning user skills with git.
+func (c *Client) EnableVersioning(ctx context.Context) (*VersioningStatus, error) {
+	urlPath := "/api/versioning"
+	resp, err := c.do(ctx, "POST", urlPath, nil, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, responseError(resp)
+	}
+	var out VersioningStatus
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/handler"
+	"github.com/wind/skill-router/internal/history"
+	"github.com/wind/skill-router/internal/openapi"
+	"github.com/wind/skill-router/internal/profile"
+	"github.com/wind/skill-router/internal/service"
+)
+
+func TestGeneratedClientIsUpToDate(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := openapi.GenerateClient(doc, "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("client_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("client_gen.go is stale, run go generate ./client")
+	}
+}
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+	t.Cleanup(func() { config.SetChangeHook(nil) })
+
+	skillDir := filepath.Join(tmpDir, "skills", "notes")
+	os.MkdirAll(skillDir, 0755)
+	os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: notes\ndescription: Take notes\n---\nBody"), 0644)
+
+	svc := service.NewSkillService(tmpDir)
+	svc.EnableHistory(history.Open(tmpDir))
+	router := handler.NewRouter(handler.NewSkillHandler(svc), handler.NewProfileHandler(svc, profile.NewStore(tmpDir)), nil)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return New(srv.URL)
+}
+
+func TestClient_Skills(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	skills, err := c.ListSkills(ctx, nil)
+	if err != nil || len(skills) != 1 || skills[0].Name != "notes" {
+		t.Fatalf("expected the notes skill, got %+v, %v", skills, err)
+	}
+
+	enabled := true
+	results, err := c.SearchSkills(ctx, &SearchSkillsParams{Q: "notes", Enabled: &enabled})
+	if err != nil || len(results) != 1 || results[0].Skill.FileName != "notes" {
+		t.Fatalf("expected one search result, got %+v, %v", results, err)
+	}
+
+	content, err := c.GetSkillContent(ctx, "notes", nil)
+	if err != nil || content.StatusCode != 200 || content.ETag == "" || !bytes.Contains(content.Body, []byte("Take notes")) {
+		t.Fatalf("unexpected content %+v, %v", content, err)
+	}
+	cached, err := c.GetSkillContent(ctx, "notes", &GetSkillContentParams{IfNoneMatch: content.ETag})
+	if err != nil || cached.StatusCode != 304 || cached.Body != nil {
+		t.Fatalf("expected 304, got %+v, %v", cached, err)
+	}
+
+	updated := "---\nname: notes\ndescription: Edited\n---\n"
+	saved, err := c.PutSkillContent(ctx, "notes", content.ETag, strings.NewReader(updated))
+	if err != nil || saved.ETag == "" || saved.ETag == content.ETag {
+		t.Fatalf("unexpected save result %+v, %v", saved, err)
+	}
+	_, err = c.PutSkillContent(ctx, "notes", content.ETag, strings.NewReader(updated))
+	if Code(err) != ErrorCodeStaleContent {
+		t.Fatalf("expected a stale-content error, got %v", err)
+	}
+
+	err = c.UploadSkill(ctx, UploadSkillForm{File: File{Name: "todo.md", Content: strings.NewReader("---\nname: todo\ndescription: Todos\n---\n")}})
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	err = c.DisableSkill(ctx, "missing")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 404 || Code(err) != ErrorCodeNotFound {
+		t.Fatalf("expected a not-found error, got %v", err)
+	}
+}
+
+func TestClient_Files(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	files, err := c.UploadSkillFilesTo(ctx, "notes", "docs/deep", UploadSkillFilesToForm{File: []File{
+		{Name: "a.txt", Content: strings.NewReader("a")},
+		{Name: "b.txt", Content: strings.NewReader("b")},
+	}})
+	if err != nil || len(files) != 2 || files[0].Path != "docs/deep/a.txt" {
+		t.Fatalf("unexpected upload result %+v, %v", files, err)
+	}
+	data, err := c.ReadSkillFile(ctx, "notes", "docs/deep/b.txt")
+	if err != nil || string(data) != "b" {
+		t.Fatalf("expected b, got %q, %v", data, err)
+	}
+	file, err := c.ChmodSkillFile(ctx, "notes", "docs/deep/a.txt", ChmodRequest{Executable: true})
+	if err != nil || !file.Executable {
+		t.Fatalf("expected an executable file, got %+v, %v", file, err)
+	}
+}
+
+func TestClient_BulkFailure(t *testing.T) {
+	c := newTestClient(t)
+
+	resp, err := c.BulkAction(context.Background(), BulkRequest{
+		Action:  "disable",
+		Targets: []BulkTarget{{Type: "user", Name: "notes"}, {Type: "user", Name: "missing"}},
+	})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 422 {
+		t.Fatalf("expected a 422 error, got %v", err)
+	}
+	if resp == nil || resp.Applied || len(resp.Results) != 2 || resp.Results[1].Status != "failed" {
+		t.Fatalf("expected the failed target in the results, got %+v", resp)
+	}
+}
+
+func TestClient_Streams(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	backup, err := c.ExportBackup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(backup)
+	if err != nil {
+		t.Fatalf("expected a gzip archive: %v", err)
+	}
+	io.Copy(io.Discard, zr)
+	backup.Close()
+
+	report, err := c.RestoreBackup(ctx, RestoreBackupForm{File: File{Name: "b.tar.gz", Content: bytes.NewReader(nil)}}, nil)
+	if Code(err) != ErrorCodeInvalidRequest || report != nil {
+		t.Fatalf("expected an invalid-request error for an empty archive, got %+v, %v", report, err)
+	}
+
+	doc, err := c.GetOpenAPI(ctx)
+	if err != nil || !bytes.Equal(doc, bytes.TrimSpace(openapi.Spec)) {
+		t.Fatalf("expected the OpenAPI document, got %v", err)
+	}
+	if _, err := c.StreamEvents(ctx); Code(err) != ErrorCodeNotFound {
+		t.Fatalf("expected a not-found error without a watcher, got %v", err)
+	}
+}
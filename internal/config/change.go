@@ -0,0 +1,94 @@
+package config
+
+import "slices"
+
+// OverridesChange is what a write to the overrides file added and removed.
+type OverridesChange struct {
+	AddedDisabled          []string `json:"addedDisabled,omitempty"`
+	RemovedDisabled        []string `json:"removedDisabled,omitempty"`
+	AddedDisabledPlugins   []string `json:"addedDisabledPlugins,omitempty"`
+	RemovedDisabledPlugins []string `json:"removedDisabledPlugins,omitempty"`
+}
+
+func (c OverridesChange) Empty() bool {
+	return len(c.AddedDisabled) == 0 && len(c.RemovedDisabled) == 0 &&
+		len(c.AddedDisabledPlugins) == 0 && len(c.RemovedDisabledPlugins) == 0
+}
+
+// Inverse returns the change that undoes c.
+func (c OverridesChange) Inverse() OverridesChange {
+	return OverridesChange{
+		AddedDisabled:          c.RemovedDisabled,
+		RemovedDisabled:        c.AddedDisabled,
+		AddedDisabledPlugins:   c.RemovedDisabledPlugins,
+		RemovedDisabledPlugins: c.AddedDisabledPlugins,
+	}
+}
+
+var changeHook func(OverridesChange)
+
+// SetChangeHook registers fn to be called after every write that changes the
+// overrides file, except those made by ApplyOverridesChange. fn runs after
+// the file has been unlocked and must not block for long.
+func SetChangeHook(fn func(OverridesChange)) {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	changeHook = fn
+}
+
+// ApplyOverridesChange adds and removes the entries in c without calling the
+// change hook, for replaying or reverting a change that was recorded before.
+func ApplyOverridesChange(c OverridesChange) error {
+	_, err := updateOverrides(func(overrides *SkillOverrides) error {
+		overrides.Disabled = applyEntries(overrides.Disabled, c.AddedDisabled, c.RemovedDisabled)
+		overrides.DisabledPlugins = applyEntries(overrides.DisabledPlugins, c.AddedDisabledPlugins, c.RemovedDisabledPlugins)
+		return nil
+	})
+	return err
+}
+
+func notifyChange(c OverridesChange) {
+	overridesMu.RLock()
+	hook := changeHook
+	overridesMu.RUnlock()
+
+	if hook != nil && !c.Empty() {
+		hook(c)
+	}
+}
+
+// DiffOverrides returns the change that turns before into after.
+func DiffOverrides(before, after *SkillOverrides) OverridesChange {
+	return OverridesChange{
+		AddedDisabled:          missing(after.Disabled, before.Disabled),
+		RemovedDisabled:        missing(before.Disabled, after.Disabled),
+		AddedDisabledPlugins:   missing(after.DisabledPlugins, before.DisabledPlugins),
+		RemovedDisabledPlugins: missing(before.DisabledPlugins, after.DisabledPlugins),
+	}
+}
+
+// missing returns the items of a that are not in b.
+func missing(a, b []string) []string {
+	var out []string
+	for _, item := range a {
+		if !slices.Contains(b, item) && !slices.Contains(out, item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func applyEntries(items, add, drop []string) []string {
+	out := make([]string, 0, len(items)+len(add))
+	for _, item := range items {
+		if !slices.Contains(drop, item) {
+			out = append(out, item)
+		}
+	}
+	for _, item := range add {
+		if !slices.Contains(out, item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestChangeHook(t *testing.T) {
+	Init(t.TempDir())
+
+	var changes []OverridesChange
+	SetChangeHook(func(c OverridesChange) { changes = append(changes, c) })
+	defer SetChangeHook(nil)
+
+	DisablePluginSkill("tools", "lint")
+	DisablePluginSkill("tools", "lint") // no change, not reported
+	DisablePlugin("tools")
+
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	if !slices.Equal(changes[0].AddedDisabled, []string{"tools:lint"}) {
+		t.Errorf("unexpected first change: %+v", changes[0])
+	}
+	second := changes[1]
+	if !slices.Equal(second.AddedDisabledPlugins, []string{"tools"}) || !slices.Equal(second.RemovedDisabled, []string{"tools:lint"}) {
+		t.Errorf("unexpected second change: %+v", second)
+	}
+
+	// Reverting is not reported again
+	if err := ApplyOverridesChange(second.Inverse()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Errorf("expected ApplyOverridesChange not to call the hook, got %+v", changes)
+	}
+	if !IsPluginSkillDisabled("tools", "lint") || IsPluginDisabled("tools") {
+		t.Error("expected the plugin change to be reverted")
+	}
+}
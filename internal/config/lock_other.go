@@ -0,0 +1,13 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package config
+
+import "os"
+
+// Platforms without flock or LockFileEx only get the in-process locking.
+
+func lockFile(f *os.File) error { return nil }
+
+func unlockFile(f *os.File) error { return nil }
+
+func syncDir(dir string) {}
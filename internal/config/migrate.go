@@ -0,0 +1,115 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// CurrentVersion is the schema version of skill-overrides.json written by
+// this build. Files without a version field are version 0.
+const CurrentVersion = 1
+
+// ErrUnsupportedVersion is returned for overrides files written by a newer
+// build. They are never read or rewritten, so nothing is lost on downgrade.
+var ErrUnsupportedVersion = errors.New("unsupported skill-overrides.json version")
+
+// migrations[i] upgrades a document from version i to version i+1. Steps
+// work on the raw JSON object so fields unknown to the current struct
+// survive until a later step handles them.
+var migrations = []func(doc map[string]json.RawMessage) error{
+	migrateV0ToV1,
+}
+
+// migrateV0ToV1 normalizes missing or null lists to empty ones. Version 0 is
+// everything written before the version field existed.
+func migrateV0ToV1(doc map[string]json.RawMessage) error {
+	for _, key := range []string{"disabled", "disabledPlugins"} {
+		raw, ok := doc[key]
+		if !ok || string(raw) == "null" {
+			doc[key] = json.RawMessage("[]")
+		}
+	}
+	return nil
+}
+
+// migrate upgrades an overrides document to CurrentVersion and reports the
+// version it started from.
+func migrate(data []byte) ([]byte, int, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, err
+	}
+
+	version := 0
+	if raw, ok := doc["version"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return nil, 0, fmt.Errorf("invalid version: %s", raw)
+		}
+	}
+	if version < 0 || version > CurrentVersion {
+		return nil, 0, fmt.Errorf("%w %d (this build supports up to %d)", ErrUnsupportedVersion, version, CurrentVersion)
+	}
+	if version == CurrentVersion {
+		return data, version, nil
+	}
+
+	for v := version; v < CurrentVersion; v++ {
+		if err := migrations[v](doc); err != nil {
+			return nil, 0, fmt.Errorf("migrate from version %d: %w", v, err)
+		}
+		doc["version"] = json.RawMessage(fmt.Sprint(v + 1))
+	}
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	return out, version, nil
+}
+
+// MigrateOverrides upgrades the overrides file on disk to CurrentVersion. The
+// original is kept as skill-overrides.json.v<N>.bak. It reports whether the
+// file needed upgrading.
+func MigrateOverrides() (bool, error) {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+
+	unlock, err := LockPath(overridesPath)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	overrides, fromVersion, err := readOverrides()
+	if err != nil {
+		return false, err
+	}
+	if fromVersion == CurrentVersion {
+		return false, nil
+	}
+
+	if err := backupOverrides(fromVersion); err != nil {
+		return false, err
+	}
+	return true, writeOverrides(overrides)
+}
+
+// backupOverrides copies the current file aside before it is rewritten in a
+// newer format. An existing backup of the same version is kept.
+func backupOverrides(version int) error {
+	backupPath := fmt.Sprintf("%s.v%d.bak", overridesPath, version)
+	if _, err := os.Stat(backupPath); err == nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(overridesPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(backupPath, data, 0644)
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// canonical re-encodes a JSON document with sorted keys and indentation so
+// golden files can be compared independently of formatting.
+func canonical(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("invalid JSON %q: %v", data, err)
+	}
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(out, '\n')
+}
+
+// TestMigrationSteps runs each migration on testdata/migrations/v<N>.json and
+// compares the result with v<N+1>.json.
+func TestMigrationSteps(t *testing.T) {
+	for v := range migrations {
+		t.Run(fmt.Sprintf("v%d-to-v%d", v, v+1), func(t *testing.T) {
+			input, err := os.ReadFile(filepath.Join("testdata", "migrations", fmt.Sprintf("v%d.json", v)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var doc map[string]json.RawMessage
+			if err := json.Unmarshal(input, &doc); err != nil {
+				t.Fatal(err)
+			}
+			if err := migrations[v](doc); err != nil {
+				t.Fatalf("migration failed: %v", err)
+			}
+			doc["version"] = json.RawMessage(fmt.Sprint(v + 1))
+
+			got, err := json.Marshal(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = canonical(t, got)
+
+			goldenPath := filepath.Join("testdata", "migrations", fmt.Sprintf("v%d.json", v+1))
+			if *update {
+				if err := os.WriteFile(goldenPath, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, canonical(t, want)) {
+				t.Errorf("migration output mismatch\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestMigrationSteps_GoldenForCurrentVersion(t *testing.T) {
+	path := filepath.Join("testdata", "migrations", fmt.Sprintf("v%d.json", CurrentVersion))
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("missing golden file for current version: %v", err)
+	}
+}
+
+func TestMigrateOverrides_UpgradesInPlaceWithBackup(t *testing.T) {
+	dir := t.TempDir()
+	Init(dir)
+
+	original, err := os.ReadFile(filepath.Join("testdata", "migrations", "v0.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "skill-overrides.json")
+	if err := os.WriteFile(path, original, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	migrated, err := MigrateOverrides()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !migrated {
+		t.Fatal("expected file to be migrated")
+	}
+
+	backup, err := os.ReadFile(path + ".v0.bak")
+	if err != nil {
+		t.Fatalf("expected backup: %v", err)
+	}
+	if !bytes.Equal(backup, original) {
+		t.Error("backup should hold the original content")
+	}
+
+	overrides, err := LoadOverrides()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if overrides.Version != CurrentVersion {
+		t.Errorf("expected version %d, got %d", CurrentVersion, overrides.Version)
+	}
+	if overrides.DisabledPlugins == nil || len(overrides.Disabled) != 2 {
+		t.Errorf("unexpected overrides after migration: %+v", overrides)
+	}
+
+	migrated, err = MigrateOverrides()
+	if err != nil || migrated {
+		t.Errorf("second migration should be a no-op, got migrated=%v err=%v", migrated, err)
+	}
+}
+
+func TestLoadOverrides_RejectsFutureVersion(t *testing.T) {
+	dir := t.TempDir()
+	Init(dir)
+
+	future := []byte(fmt.Sprintf(`{"version": %d, "disabled": [], "disabledPlugins": []}`, CurrentVersion+1))
+	path := filepath.Join(dir, "skill-overrides.json")
+	if err := os.WriteFile(path, future, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadOverrides(); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+	}
+	if err := DisablePlugin("x"); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion on write, got %v", err)
+	}
+	if err := SaveOverrides(&SkillOverrides{}); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion on save, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, future) {
+		t.Error("file from a newer version must not be rewritten")
+	}
+}
+
+func TestOverrides_KeepUnknownFields(t *testing.T) {
+	dir := t.TempDir()
+	Init(dir)
+
+	path := filepath.Join(dir, "skill-overrides.json")
+	if err := os.WriteFile(path, []byte(`{"disabled": [], "disabledPlugins": null, "pinned": {"tools": "1.2.0"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := MigrateOverrides(); err != nil {
+		t.Fatal(err)
+	}
+	if err := DisablePlugin("tools"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveOverrides(&SkillOverrides{Disabled: []string{"a:b"}, DisabledPlugins: []string{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(canonical(t, doc["pinned"]), canonical(t, []byte(`{"tools": "1.2.0"}`))) {
+		t.Errorf("expected the unknown field to survive, got %s", data)
+	}
+	if string(doc["version"]) != fmt.Sprint(CurrentVersion) {
+		t.Errorf("expected version %d, got %s", CurrentVersion, data)
+	}
+}
@@ -4,13 +4,19 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 )
 
 type SkillOverrides struct {
+	Version         int      `json:"version"`
 	Disabled        []string `json:"disabled"`
 	DisabledPlugins []string `json:"disabledPlugins"`
+
+	// extra holds top-level fields this build does not know, such as ones
+	// added by other tools, so they are written back unchanged
+	extra map[string]json.RawMessage
 }
 
 var (
@@ -22,36 +28,167 @@ func Init(baseDir string) {
 	overridesPath = filepath.Join(baseDir, "skill-overrides.json")
 }
 
+func OverridesPath() string {
+	return overridesPath
+}
+
 func LoadOverrides() (*SkillOverrides, error) {
 	overridesMu.RLock()
 	defer overridesMu.RUnlock()
 
+	overrides, _, err := readOverrides()
+	return overrides, err
+}
+
+func SaveOverrides(overrides *SkillOverrides) error {
+	change, err := saveOverrides(overrides)
+	if err != nil {
+		return err
+	}
+	notifyChange(change)
+	return nil
+}
+
+func saveOverrides(overrides *SkillOverrides) (OverridesChange, error) {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+
+	unlock, err := LockPath(overridesPath)
+	if err != nil {
+		return OverridesChange{}, err
+	}
+	defer unlock()
+
+	before, fromVersion, err := readOverrides()
+	if err != nil {
+		return OverridesChange{}, err
+	}
+	if overrides.extra == nil {
+		overrides.extra = before.extra
+	}
+	if fromVersion < CurrentVersion {
+		if err := backupOverrides(fromVersion); err != nil {
+			return OverridesChange{}, err
+		}
+	}
+	if err := writeOverrides(overrides); err != nil {
+		return OverridesChange{}, err
+	}
+	return DiffOverrides(before, overrides), nil
+}
+
+// UpdateOverrides runs a read-modify-write cycle on the overrides file. The
+// file stays locked against other goroutines and other processes from the
+// read until the new content has been written, so concurrent updates are
+// never lost.
+func UpdateOverrides(fn func(*SkillOverrides) error) error {
+	change, err := updateOverrides(fn)
+	if err != nil {
+		return err
+	}
+	notifyChange(change)
+	return nil
+}
+
+// ChangeOverrides is UpdateOverrides for callers that record the change as
+// part of an operation of their own: it returns the change instead of
+// reporting it to the change hook.
+func ChangeOverrides(fn func(*SkillOverrides) error) (OverridesChange, error) {
+	return updateOverrides(fn)
+}
+
+func updateOverrides(fn func(*SkillOverrides) error) (OverridesChange, error) {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+
+	unlock, err := LockPath(overridesPath)
+	if err != nil {
+		return OverridesChange{}, err
+	}
+	defer unlock()
+
+	overrides, fromVersion, err := readOverrides()
+	if err != nil {
+		return OverridesChange{}, err
+	}
+	before := &SkillOverrides{
+		Disabled:        slices.Clone(overrides.Disabled),
+		DisabledPlugins: slices.Clone(overrides.DisabledPlugins),
+	}
+	if err := fn(overrides); err != nil {
+		return OverridesChange{}, err
+	}
+	if fromVersion < CurrentVersion {
+		if err := backupOverrides(fromVersion); err != nil {
+			return OverridesChange{}, err
+		}
+	}
+	if err := writeOverrides(overrides); err != nil {
+		return OverridesChange{}, err
+	}
+	return DiffOverrides(before, overrides), nil
+}
+
+// readOverrides loads the overrides file, migrating it in memory to
+// CurrentVersion. It also returns the version found on disk.
+func readOverrides() (*SkillOverrides, int, error) {
 	data, err := os.ReadFile(overridesPath)
 	if os.IsNotExist(err) {
-		return &SkillOverrides{Disabled: []string{}, DisabledPlugins: []string{}}, nil
+		return &SkillOverrides{Version: CurrentVersion, Disabled: []string{}, DisabledPlugins: []string{}}, CurrentVersion, nil
 	}
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	data, fromVersion, err := migrate(data)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	var overrides SkillOverrides
 	if err := json.Unmarshal(data, &overrides); err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, err
+	}
+	for _, key := range knownFields {
+		delete(doc, key)
+	}
+	if len(doc) > 0 {
+		overrides.extra = doc
 	}
 
-	return &overrides, nil
+	return &overrides, fromVersion, nil
 }
 
-func SaveOverrides(overrides *SkillOverrides) error {
-	overridesMu.Lock()
-	defer overridesMu.Unlock()
+// knownFields are the JSON names of the SkillOverrides fields.
+var knownFields = []string{"version", "disabled", "disabledPlugins"}
+
+func writeOverrides(overrides *SkillOverrides) error {
+	overrides.Version = CurrentVersion
 
 	data, err := json.MarshalIndent(overrides, "", "  ")
 	if err != nil {
 		return err
 	}
+	if len(overrides.extra) > 0 {
+		doc := make(map[string]json.RawMessage, len(overrides.extra)+len(knownFields))
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		for key, raw := range overrides.extra {
+			if _, ok := doc[key]; !ok {
+				doc[key] = raw
+			}
+		}
+		if data, err = json.MarshalIndent(doc, "", "  "); err != nil {
+			return err
+		}
+	}
 
-	return os.WriteFile(overridesPath, data, 0644)
+	return WriteFileAtomic(overridesPath, data, 0644)
 }
 
 func IsPluginSkillDisabled(pluginName, skillName string) bool {
@@ -60,8 +197,14 @@ func IsPluginSkillDisabled(pluginName, skillName string) bool {
 		return false
 	}
 
+	return overrides.IsPluginSkillDisabled(pluginName, skillName)
+}
+
+// IsPluginSkillDisabled checks an already loaded overrides value, for callers
+// that look up many skills at once.
+func (o *SkillOverrides) IsPluginSkillDisabled(pluginName, skillName string) bool {
 	// Check if entire plugin is disabled
-	for _, disabled := range overrides.DisabledPlugins {
+	for _, disabled := range o.DisabledPlugins {
 		if disabled == pluginName {
 			return true
 		}
@@ -69,7 +212,7 @@ func IsPluginSkillDisabled(pluginName, skillName string) bool {
 
 	// Check if individual skill is disabled
 	key := pluginName + ":" + skillName
-	for _, disabled := range overrides.Disabled {
+	for _, disabled := range o.Disabled {
 		if disabled == key {
 			return true
 		}
@@ -92,85 +235,114 @@ func IsPluginDisabled(pluginName string) bool {
 }
 
 func DisablePluginSkill(pluginName, skillName string) error {
-	overrides, err := LoadOverrides()
-	if err != nil {
-		return err
-	}
-
-	key := pluginName + ":" + skillName
-
-	// Check if already disabled
-	for _, disabled := range overrides.Disabled {
-		if disabled == key {
-			return nil
+	return UpdateOverrides(func(overrides *SkillOverrides) error {
+		key := pluginName + ":" + skillName
+
+		// Check if already disabled
+		for _, disabled := range overrides.Disabled {
+			if disabled == key {
+				return nil
+			}
 		}
-	}
 
-	overrides.Disabled = append(overrides.Disabled, key)
-	return SaveOverrides(overrides)
+		overrides.Disabled = append(overrides.Disabled, key)
+		return nil
+	})
 }
 
 func EnablePluginSkill(pluginName, skillName string) error {
-	overrides, err := LoadOverrides()
-	if err != nil {
-		return err
-	}
-
-	key := pluginName + ":" + skillName
-
-	// Remove from disabled list
-	newDisabled := make([]string, 0, len(overrides.Disabled))
-	for _, disabled := range overrides.Disabled {
-		if disabled != key {
-			newDisabled = append(newDisabled, disabled)
+	return UpdateOverrides(func(overrides *SkillOverrides) error {
+		key := pluginName + ":" + skillName
+
+		// Remove from disabled list
+		newDisabled := make([]string, 0, len(overrides.Disabled))
+		for _, disabled := range overrides.Disabled {
+			if disabled != key {
+				newDisabled = append(newDisabled, disabled)
+			}
 		}
-	}
 
-	overrides.Disabled = newDisabled
-	return SaveOverrides(overrides)
+		overrides.Disabled = newDisabled
+		return nil
+	})
 }
 
 func DisablePlugin(pluginName string) error {
-	overrides, err := LoadOverrides()
-	if err != nil {
-		return err
-	}
-
-	// Check if already disabled
-	for _, disabled := range overrides.DisabledPlugins {
-		if disabled == pluginName {
-			return nil
+	return UpdateOverrides(func(overrides *SkillOverrides) error {
+		// Check if already disabled
+		for _, disabled := range overrides.DisabledPlugins {
+			if disabled == pluginName {
+				return nil
+			}
 		}
-	}
 
-	overrides.DisabledPlugins = append(overrides.DisabledPlugins, pluginName)
+		overrides.DisabledPlugins = append(overrides.DisabledPlugins, pluginName)
 
-	// Also remove individual skill overrides for this plugin (they're now redundant)
-	newDisabled := make([]string, 0, len(overrides.Disabled))
-	for _, disabled := range overrides.Disabled {
-		if !strings.HasPrefix(disabled, pluginName+":") {
-			newDisabled = append(newDisabled, disabled)
+		// Also remove individual skill overrides for this plugin (they're now redundant)
+		newDisabled := make([]string, 0, len(overrides.Disabled))
+		for _, disabled := range overrides.Disabled {
+			if !strings.HasPrefix(disabled, pluginName+":") {
+				newDisabled = append(newDisabled, disabled)
+			}
 		}
-	}
-	overrides.Disabled = newDisabled
-
-	return SaveOverrides(overrides)
+		overrides.Disabled = newDisabled
+		return nil
+	})
 }
 
 func EnablePlugin(pluginName string) error {
-	overrides, err := LoadOverrides()
-	if err != nil {
-		return err
+	return UpdateOverrides(func(overrides *SkillOverrides) error {
+		// Remove from disabled plugins list
+		newDisabledPlugins := make([]string, 0, len(overrides.DisabledPlugins))
+		for _, disabled := range overrides.DisabledPlugins {
+			if disabled != pluginName {
+				newDisabledPlugins = append(newDisabledPlugins, disabled)
+			}
+		}
+
+		overrides.DisabledPlugins = newDisabledPlugins
+		return nil
+	})
+}
+
+// OrphanedOverrides lists override entries that refer to plugins or plugin
+// skills that are no longer installed.
+type OrphanedOverrides struct {
+	Disabled        []string `json:"disabled"`
+	DisabledPlugins []string `json:"disabledPlugins"`
+}
+
+func (o *OrphanedOverrides) Empty() bool {
+	return len(o.Disabled) == 0 && len(o.DisabledPlugins) == 0
+}
+
+// RemoveOverrides drops the given entries from the overrides file.
+func RemoveOverrides(entries *OrphanedOverrides) error {
+	remove := make(map[string]bool)
+	for _, key := range entries.Disabled {
+		remove[key] = true
+	}
+	removePlugins := make(map[string]bool)
+	for _, name := range entries.DisabledPlugins {
+		removePlugins[name] = true
 	}
 
-	// Remove from disabled plugins list
-	newDisabledPlugins := make([]string, 0, len(overrides.DisabledPlugins))
-	for _, disabled := range overrides.DisabledPlugins {
-		if disabled != pluginName {
-			newDisabledPlugins = append(newDisabledPlugins, disabled)
+	return UpdateOverrides(func(overrides *SkillOverrides) error {
+		newDisabled := make([]string, 0, len(overrides.Disabled))
+		for _, disabled := range overrides.Disabled {
+			if !remove[disabled] {
+				newDisabled = append(newDisabled, disabled)
+			}
 		}
-	}
+		overrides.Disabled = newDisabled
 
-	overrides.DisabledPlugins = newDisabledPlugins
-	return SaveOverrides(overrides)
+		newDisabledPlugins := make([]string, 0, len(overrides.DisabledPlugins))
+		for _, disabled := range overrides.DisabledPlugins {
+			if !removePlugins[disabled] {
+				newDisabledPlugins = append(newDisabledPlugins, disabled)
+			}
+		}
+		overrides.DisabledPlugins = newDisabledPlugins
+		return nil
+	})
 }
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestDisablePluginSkill_ConcurrentGoroutines(t *testing.T) {
+	Init(t.TempDir())
+
+	const workers = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- DisablePluginSkill("plugin", fmt.Sprintf("skill-%d", i))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	overrides, err := LoadOverrides()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(overrides.Disabled) != workers {
+		t.Fatalf("expected %d disabled entries, got %d", workers, len(overrides.Disabled))
+	}
+}
+
+// TestHelperProcess is not a real test. It is run as a subprocess by
+// TestDisablePluginSkill_ConcurrentProcesses.
+func TestHelperProcess(t *testing.T) {
+	dir := os.Getenv("SKILL_ROUTER_HELPER_DIR")
+	if dir == "" {
+		return
+	}
+	id := os.Getenv("SKILL_ROUTER_HELPER_ID")
+
+	Init(dir)
+	for i := 0; i < 20; i++ {
+		if err := DisablePluginSkill("plugin-"+id, fmt.Sprintf("skill-%d", i)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+func TestDisablePluginSkill_ConcurrentProcesses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("spawns subprocesses")
+	}
+
+	dir := t.TempDir()
+	Init(dir)
+
+	const procs = 8
+	cmds := make([]*exec.Cmd, procs)
+	for i := range cmds {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+		cmd.Env = append(os.Environ(),
+			"SKILL_ROUTER_HELPER_DIR="+dir,
+			fmt.Sprintf("SKILL_ROUTER_HELPER_ID=%d", i),
+		)
+		cmd.Stderr = os.Stderr
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("start helper: %v", err)
+		}
+		cmds[i] = cmd
+	}
+
+	// Meanwhile make sure the file is never seen half written.
+	done := make(chan struct{})
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(readErr)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			data, err := os.ReadFile(filepath.Join(dir, "skill-overrides.json"))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+			var o SkillOverrides
+			if err := json.Unmarshal(data, &o); err != nil {
+				readErr <- fmt.Errorf("read partial file %q: %w", data, err)
+				return
+			}
+		}
+	}()
+
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("helper failed: %v", err)
+		}
+	}
+	close(done)
+	if err := <-readErr; err != nil {
+		t.Fatal(err)
+	}
+
+	overrides, err := LoadOverrides()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(overrides.Disabled) != procs*20 {
+		t.Fatalf("expected %d disabled entries, got %d", procs*20, len(overrides.Disabled))
+	}
+}
+
+func TestSaveOverrides_LeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	Init(dir)
+
+	if err := SaveOverrides(&SkillOverrides{Disabled: []string{"a:b"}, DisabledPlugins: []string{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "skill-overrides.json" && e.Name() != "skill-overrides.json.lock" {
+			t.Errorf("unexpected file left behind: %s", e.Name())
+		}
+	}
+}
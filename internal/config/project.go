@@ -0,0 +1,192 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Layers that can decide whether a skill is enabled, from lowest to highest
+// precedence.
+const (
+	LayerDefault = "default"
+	LayerGlobal  = "global"
+	LayerProject = "project"
+)
+
+// Project override states accepted by SetProjectOverride.
+const (
+	StateEnabled  = "enabled"
+	StateDisabled = "disabled"
+	StateInherit  = "inherit"
+)
+
+// ErrInvalidProjectOverride is returned by SetProjectOverride for a request
+// that names no skill or plugin or has an unknown state.
+var ErrInvalidProjectOverride = errors.New("invalid project override")
+
+// ProjectOverrides live in <project>/.claude/skill-overrides.json and apply on
+// top of the global overrides for that project only. Skill keys are the
+// skill directory name for user and project skills and "plugin:skill" for
+// plugin skills.
+type ProjectOverrides struct {
+	Disabled        []string `json:"disabled"`
+	Enabled         []string `json:"enabled"`
+	DisabledPlugins []string `json:"disabledPlugins"`
+	EnabledPlugins  []string `json:"enabledPlugins"`
+}
+
+// IsProject reports whether dir is the absolute path of an existing project
+// directory Claude knows about: one with a .claude directory, or one Claude
+// has been run in and so keeps transcripts for under projects/.
+func IsProject(dir string) bool {
+	if !filepath.IsAbs(dir) {
+		return false
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return false
+	}
+	if info, err := os.Stat(filepath.Join(dir, ".claude")); err == nil && info.IsDir() {
+		return true
+	}
+	info, err := os.Stat(filepath.Join(filepath.Dir(overridesPath), "projects", projectKey(dir)))
+	return err == nil && info.IsDir()
+}
+
+// projectKey is the name of a project's directory under projects/: its
+// path with every character other than an ASCII letter or digit replaced
+// by "-".
+func projectKey(dir string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, dir)
+}
+
+func ProjectOverridesPath(projectDir string) string {
+	return filepath.Join(projectDir, ".claude", "skill-overrides.json")
+}
+
+func LoadProjectOverrides(projectDir string) (*ProjectOverrides, error) {
+	data, err := os.ReadFile(ProjectOverridesPath(projectDir))
+	if os.IsNotExist(err) {
+		return &ProjectOverrides{Disabled: []string{}, Enabled: []string{}, DisabledPlugins: []string{}, EnabledPlugins: []string{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var overrides ProjectOverrides
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, err
+	}
+
+	return &overrides, nil
+}
+
+func SaveProjectOverrides(projectDir string, overrides *ProjectOverrides) error {
+	unlock, err := LockPath(ProjectOverridesPath(projectDir))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return writeProjectOverrides(projectDir, overrides)
+}
+
+func writeProjectOverrides(projectDir string, overrides *ProjectOverrides) error {
+	data, err := json.MarshalIndent(overrides, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return WriteFileAtomic(ProjectOverridesPath(projectDir), data, 0644)
+}
+
+// SetProjectOverride sets the project state of a skill or, when skillKey is
+// empty, of a whole plugin. StateInherit removes the project entry so the
+// global state applies again.
+func SetProjectOverride(projectDir, pluginName, skillKey, state string) error {
+	if state != StateEnabled && state != StateDisabled && state != StateInherit {
+		return fmt.Errorf("%w: invalid state %q", ErrInvalidProjectOverride, state)
+	}
+
+	unlock, err := LockPath(ProjectOverridesPath(projectDir))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	overrides, err := LoadProjectOverrides(projectDir)
+	if err != nil {
+		return err
+	}
+
+	disabled, enabled := &overrides.Disabled, &overrides.Enabled
+	key := skillKey
+	switch {
+	case pluginName != "" && skillKey == "":
+		disabled, enabled = &overrides.DisabledPlugins, &overrides.EnabledPlugins
+		key = pluginName
+	case pluginName != "":
+		key = pluginName + ":" + skillKey
+	case skillKey == "":
+		return fmt.Errorf("%w: skill or plugin is required", ErrInvalidProjectOverride)
+	}
+
+	*disabled = remove(*disabled, key)
+	*enabled = remove(*enabled, key)
+	switch state {
+	case StateDisabled:
+		*disabled = append(*disabled, key)
+	case StateEnabled:
+		*enabled = append(*enabled, key)
+	}
+
+	return writeProjectOverrides(projectDir, overrides)
+}
+
+// ResolveSkill applies the project layer to a user or project skill whose
+// state so far is enabled, set by layer.
+func (p *ProjectOverrides) ResolveSkill(name string, enabled bool, layer string) (bool, string) {
+	switch {
+	case slices.Contains(p.Disabled, name):
+		return false, LayerProject
+	case slices.Contains(p.Enabled, name):
+		return true, LayerProject
+	}
+	return enabled, layer
+}
+
+// ResolvePluginSkill applies the project layer to a plugin skill. A skill
+// entry wins over a plugin entry.
+func (p *ProjectOverrides) ResolvePluginSkill(pluginName, skillName string, enabled bool, layer string) (bool, string) {
+	key := pluginName + ":" + skillName
+	switch {
+	case slices.Contains(p.Disabled, key):
+		return false, LayerProject
+	case slices.Contains(p.Enabled, key):
+		return true, LayerProject
+	case slices.Contains(p.DisabledPlugins, pluginName):
+		return false, LayerProject
+	case slices.Contains(p.EnabledPlugins, pluginName):
+		return true, LayerProject
+	}
+	return enabled, layer
+}
+
+func remove(items []string, item string) []string {
+	out := make([]string, 0, len(items))
+	for _, i := range items {
+		if i != item {
+			out = append(out, i)
+		}
+	}
+	return out
+}
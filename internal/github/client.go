@@ -2,11 +2,15 @@ package github
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type File struct {
@@ -15,6 +19,12 @@ type File struct {
 	Type        string `json:"type"`
 }
 
+var (
+	ErrInvalidURL  = errors.New("invalid github URL")
+	ErrNotFound    = errors.New("not found on GitHub")
+	ErrRateLimited = errors.New("GitHub API rate limit exceeded")
+)
+
 var (
 	repoURLRegex            = regexp.MustCompile(`github\.com/([^/]+)/([^/]+)`) // owner/repo
 	defaultGitHubAPIBaseURL = "https://api.github.com"
@@ -24,23 +34,33 @@ func FetchSkillFiles(repoURL string) ([]File, error) {
 	return fetchSkillFiles(repoURL, defaultGitHubAPIBaseURL, http.DefaultClient)
 }
 
+// FetchSkillFilesAt is like FetchSkillFiles but reads the repository at the
+// given branch, tag or commit. An empty ref means the default branch.
+func FetchSkillFilesAt(repoURL, ref string) ([]File, error) {
+	return fetchSkillFilesAt(repoURL, ref, defaultGitHubAPIBaseURL, http.DefaultClient)
+}
+
 func FetchSkillDirs(repoURL string) (basePath string, dirs []File, err error) {
-	return fetchSkillDirs(repoURL, defaultGitHubAPIBaseURL, http.DefaultClient)
+	return fetchSkillDirs(repoURL, "", defaultGitHubAPIBaseURL, http.DefaultClient)
 }
 
 func FetchSkillFile(repoURL, basePath, skillDir string) (File, error) {
-	return fetchSkillFile(repoURL, basePath, skillDir, defaultGitHubAPIBaseURL, http.DefaultClient)
+	return fetchSkillFile(repoURL, "", basePath, skillDir, defaultGitHubAPIBaseURL, http.DefaultClient)
 }
 
 func fetchSkillFiles(repoURL, apiBaseURL string, client *http.Client) ([]File, error) {
-	basePath, dirs, err := fetchSkillDirs(repoURL, apiBaseURL, client)
+	return fetchSkillFilesAt(repoURL, "", apiBaseURL, client)
+}
+
+func fetchSkillFilesAt(repoURL, ref, apiBaseURL string, client *http.Client) ([]File, error) {
+	basePath, dirs, err := fetchSkillDirs(repoURL, ref, apiBaseURL, client)
 	if err != nil {
 		return nil, err
 	}
 
 	var skillFiles []File
 	for _, d := range dirs {
-		f, err := fetchSkillFile(repoURL, basePath, d.Name, apiBaseURL, client)
+		f, err := fetchSkillFile(repoURL, ref, basePath, d.Name, apiBaseURL, client)
 		if err != nil {
 			return nil, err
 		}
@@ -55,7 +75,7 @@ func fetchSkillFiles(repoURL, apiBaseURL string, client *http.Client) ([]File, e
 	return skillFiles, nil
 }
 
-func fetchSkillDirs(repoURL, apiBaseURL string, client *http.Client) (basePath string, dirs []File, err error) {
+func fetchSkillDirs(repoURL, ref, apiBaseURL string, client *http.Client) (basePath string, dirs []File, err error) {
 	owner, repo, err := parseRepoURL(repoURL)
 	if err != nil {
 		return "", nil, err
@@ -64,7 +84,7 @@ func fetchSkillDirs(repoURL, apiBaseURL string, client *http.Client) (basePath s
 	tryPaths := []string{".claude/skills", "skills"}
 
 	for i, p := range tryPaths {
-		apiURL := fmt.Sprintf("%s/repos/%s/%s/contents/%s", strings.TrimSuffix(apiBaseURL, "/"), owner, repo, p)
+		apiURL := contentsURL(apiBaseURL, owner, repo, p, ref)
 
 		var entries []File
 		status, err := fetchJSON(client, apiURL, &entries)
@@ -74,7 +94,7 @@ func fetchSkillDirs(repoURL, apiBaseURL string, client *http.Client) (basePath s
 
 		if status == http.StatusNotFound {
 			if i == len(tryPaths)-1 {
-				return "", nil, fmt.Errorf("no skills directory found")
+				return "", nil, fmt.Errorf("%w: no skills directory", ErrNotFound)
 			}
 			continue
 		}
@@ -93,10 +113,10 @@ func fetchSkillDirs(repoURL, apiBaseURL string, client *http.Client) (basePath s
 		return p, dirEntries, nil
 	}
 
-	return "", nil, fmt.Errorf("no skills directory found")
+	return "", nil, fmt.Errorf("%w: no skills directory", ErrNotFound)
 }
 
-func fetchSkillFile(repoURL, basePath, skillDir, apiBaseURL string, client *http.Client) (File, error) {
+func fetchSkillFile(repoURL, ref, basePath, skillDir, apiBaseURL string, client *http.Client) (File, error) {
 	owner, repo, err := parseRepoURL(repoURL)
 	if err != nil {
 		return File{}, err
@@ -105,7 +125,7 @@ func fetchSkillFile(repoURL, basePath, skillDir, apiBaseURL string, client *http
 	tryFiles := []string{"SKILL.md", "skill.md"}
 	for i, name := range tryFiles {
 		path := fmt.Sprintf("%s/%s/%s", basePath, skillDir, name)
-		apiURL := fmt.Sprintf("%s/repos/%s/%s/contents/%s", strings.TrimSuffix(apiBaseURL, "/"), owner, repo, path)
+		apiURL := contentsURL(apiBaseURL, owner, repo, path, ref)
 
 		var file File
 		status, err := fetchJSON(client, apiURL, &file)
@@ -115,7 +135,7 @@ func fetchSkillFile(repoURL, basePath, skillDir, apiBaseURL string, client *http
 
 		if status == http.StatusNotFound {
 			if i == len(tryFiles)-1 {
-				return File{}, fmt.Errorf("no skill file found for %s", skillDir)
+				return File{}, fmt.Errorf("%w: no skill file for %s", ErrNotFound, skillDir)
 			}
 			continue
 		}
@@ -127,13 +147,13 @@ func fetchSkillFile(repoURL, basePath, skillDir, apiBaseURL string, client *http
 		return file, nil
 	}
 
-	return File{}, fmt.Errorf("no skill file found for %s", skillDir)
+	return File{}, fmt.Errorf("%w: no skill file for %s", ErrNotFound, skillDir)
 }
 
 func parseRepoURL(repoURL string) (owner, repo string, err error) {
 	matches := repoURLRegex.FindStringSubmatch(repoURL)
 	if len(matches) < 3 {
-		return "", "", fmt.Errorf("invalid github URL")
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidURL, repoURL)
 	}
 
 	owner, repo = matches[1], matches[2]
@@ -141,6 +161,14 @@ func parseRepoURL(repoURL string) (owner, repo string, err error) {
 	return owner, repo, nil
 }
 
+func contentsURL(apiBaseURL, owner, repo, path, ref string) string {
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/contents/%s", strings.TrimSuffix(apiBaseURL, "/"), owner, repo, path)
+	if ref != "" {
+		apiURL += "?ref=" + url.QueryEscape(ref)
+	}
+	return apiURL
+}
+
 func fetchJSON(client *http.Client, url string, out any) (statusCode int, err error) {
 	resp, err := client.Get(url)
 	if err != nil {
@@ -148,6 +176,9 @@ func fetchJSON(client *http.Client, url string, out any) (statusCode int, err er
 	}
 	defer resp.Body.Close()
 
+	if err := checkRateLimit(resp); err != nil {
+		return resp.StatusCode, err
+	}
 	statusCode = resp.StatusCode
 	if statusCode != http.StatusOK {
 		return statusCode, nil
@@ -167,5 +198,25 @@ func DownloadFile(url string) ([]byte, error) {
 	}
 	defer resp.Body.Close()
 
+	if err := checkRateLimit(resp); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s: %s", url, resp.Status)
+	}
 	return io.ReadAll(resp.Body)
 }
+
+// checkRateLimit returns ErrRateLimited if GitHub refused the request
+// because the rate limit is used up.
+func checkRateLimit(resp *http.Response) error {
+	limited := resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0"
+	if !limited {
+		return nil
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		return fmt.Errorf("%w, resets at %s", ErrRateLimited, time.Unix(reset, 0).Format(time.Kitchen))
+	}
+	return ErrRateLimited
+}
@@ -2,6 +2,7 @@ package github
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -145,3 +146,72 @@ func TestFetchSkillFiles_UsesSkillMdFallback(t *testing.T) {
 		t.Fatalf("expected download url %q, got %q", ts.URL+"/download/foo", files[0].DownloadURL)
 	}
 }
+
+func TestFetchSkillFilesAt_PassesRef(t *testing.T) {
+	var refs []string
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refs = append(refs, r.URL.Query().Get("ref"))
+		switch r.URL.Path {
+		case "/repos/acme/myrepo/contents/.claude/skills":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode([]File{{Name: "foo", Type: "dir"}})
+			return
+		case "/repos/acme/myrepo/contents/.claude/skills/foo/SKILL.md":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(File{Name: "SKILL.md", Type: "file", DownloadURL: ts.URL + "/download/foo"})
+			return
+		default:
+			http.NotFound(w, r)
+			return
+		}
+	}))
+	defer ts.Close()
+
+	if _, err := fetchSkillFilesAt("https://github.com/acme/myrepo", "v1.2.0", ts.URL, ts.Client()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(refs) != 2 {
+		t.Fatalf("expected 2 API calls, got %d", len(refs))
+	}
+	for _, ref := range refs {
+		if ref != "v1.2.0" {
+			t.Fatalf("expected ref v1.2.0, got %q", ref)
+		}
+	}
+}
+
+func TestFetchSkillFiles_RateLimited(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	_, err := fetchSkillFiles("https://github.com/acme/myrepo", ts.URL, ts.Client())
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+
+	if _, err := DownloadFile(ts.URL + "/download/foo"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited from download, got %v", err)
+	}
+}
+
+func TestFetchSkillFiles_NotFound(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	if _, err := fetchSkillFiles("https://github.com/acme/myrepo", ts.URL, ts.Client()); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := DownloadFile(ts.URL + "/download/foo"); err == nil {
+		t.Fatal("expected an error for a missing download")
+	}
+	if _, err := fetchSkillFiles("https://example.com/acme/myrepo", ts.URL, ts.Client()); !errors.Is(err, ErrInvalidURL) {
+		t.Fatalf("expected ErrInvalidURL, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/wind/skill-router/internal/service"
+)
+
+func (h *SkillHandler) ExportBackup(w http.ResponseWriter, r *http.Request) {
+	filename := fmt.Sprintf("skills-backup-%s.tar.gz", time.Now().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := h.svc.ExportBackup(w); err != nil {
+		// Headers may be sent already; this only helps if nothing was written
+		writeError(w, err)
+	}
+}
+
+// RestoreBackup takes the archive as the multipart field "file". The query
+// parameters mode ("merge" or "replace") and dryRun select what happens.
+func (h *SkillHandler) RestoreBackup(w http.ResponseWriter, r *http.Request) {
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "No file uploaded")
+		return
+	}
+	defer file.Close()
+
+	opts := service.RestoreOptions{
+		Mode:   r.URL.Query().Get("mode"),
+		DryRun: r.URL.Query().Get("dryRun") == "true",
+	}
+	report, err := h.svc.RestoreBackup(file, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
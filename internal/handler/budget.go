@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/service"
+)
+
+func (h *SkillHandler) Budget(w http.ResponseWriter, r *http.Request) {
+	project := r.URL.Query().Get("project")
+	if project != "" && !config.IsProject(project) {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Unknown project directory")
+		return
+	}
+
+	budget := service.DefaultContextBudget
+	if v := r.URL.Query().Get("budget"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid budget")
+			return
+		}
+		budget = n
+	}
+
+	summary, err := h.svc.ContextBudget(project, budget)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
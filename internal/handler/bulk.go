@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/wind/skill-router/internal/service"
+)
+
+type BulkRequest struct {
+	Action  string               `json:"action"`
+	Targets []service.BulkTarget `json:"targets"`
+}
+
+type BulkResponse struct {
+	Applied bool                 `json:"applied"`
+	Error   string               `json:"error,omitempty"`
+	Results []service.BulkResult `json:"results"`
+}
+
+func (h *SkillHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	var req BulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid request")
+		return
+	}
+	if len(req.Targets) == 0 {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "No targets")
+		return
+	}
+
+	results, err := h.svc.Bulk(req.Action, req.Targets)
+	resp := BulkResponse{Applied: err == nil, Results: results}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		if !errors.Is(err, service.ErrBulkFailed) {
+			writeError(w, err)
+			return
+		}
+		resp.Error = err.Error()
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
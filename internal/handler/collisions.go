@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/wind/skill-router/internal/config"
+)
+
+func (h *SkillHandler) Collisions(w http.ResponseWriter, r *http.Request) {
+	project := r.URL.Query().Get("project")
+	if project != "" && !config.IsProject(project) {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Unknown project directory")
+		return
+	}
+
+	collisions, err := h.svc.FindCollisions(project)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(collisions)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/wind/skill-router/internal/service"
+)
+
+// maxSkillSize limits the SKILL.md accepted by PutContent.
+const maxSkillSize = 1 << 20
+
+func (h *SkillHandler) GetContent(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	content, err := h.svc.ReadSkillContent(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeContent(w, r, content)
+}
+
+func (h *SkillHandler) PutContent(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		writeProblem(w, http.StatusPreconditionRequired, CodePreconditionRequired, "If-Match header required")
+		return
+	}
+
+	content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSkillSize))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	etag, err := h.svc.WriteSkillContent(name, content, ifMatch)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *SkillHandler) GetPluginSkillContent(w http.ResponseWriter, r *http.Request) {
+	content, err := h.svc.ReadPluginSkillContent(r.PathValue("plugin"), r.PathValue("skill"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeContent(w, r, content)
+}
+
+func writeContent(w http.ResponseWriter, r *http.Request, content []byte) {
+	etag := service.ContentETag(content)
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Write(content)
+}
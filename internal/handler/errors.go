@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/history"
+	"github.com/wind/skill-router/internal/service"
+	"github.com/wind/skill-router/internal/versioning"
+)
+
+// Error codes sent in Problem.Code. Clients should switch on these rather
+// than on the human-readable detail.
+const (
+	CodeNotFound             = "not-found"
+	CodeAlreadyExists        = "already-exists"
+	CodeInvalidName          = "invalid-name"
+	CodeInvalidFrontmatter   = "invalid-frontmatter"
+	CodeInvalidRequest       = "invalid-request"
+	CodeUpstreamFailure      = "upstream-failure"
+	CodeRateLimited          = "rate-limited"
+	CodeStaleContent         = "stale-content"
+	CodePreconditionRequired = "precondition-required"
+	CodePayloadTooLarge      = "payload-too-large"
+	CodeConflict             = "conflict"
+	CodeInternal             = "internal-error"
+)
+
+// Problem is the RFC 9457 problem details body of every error response.
+type Problem struct {
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Code   string `json:"code"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func writeProblem(w http.ResponseWriter, status int, code, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Problem{
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	})
+}
+
+// writeError sends err as a problem, with the status and code of its kind.
+// Unexpected errors are logged rather than sent, as their messages may
+// reveal paths and other details of the machine.
+func writeError(w http.ResponseWriter, err error) {
+	status, code := errorStatus(err)
+	if status == http.StatusInternalServerError {
+		fmt.Fprintf(os.Stderr, "internal error: %v\n", err)
+		writeProblem(w, status, code, "An unexpected error occurred, see the server log")
+		return
+	}
+	writeProblem(w, status, code, err.Error())
+}
+
+func errorStatus(err error) (int, string) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, service.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		return http.StatusNotFound, CodeNotFound
+	case errors.Is(err, service.ErrAlreadyExists):
+		return http.StatusConflict, CodeAlreadyExists
+	case errors.Is(err, service.ErrInvalidName):
+		return http.StatusBadRequest, CodeInvalidName
+	case errors.Is(err, service.ErrInvalidFrontmatter):
+		return http.StatusUnprocessableEntity, CodeInvalidFrontmatter
+	case errors.Is(err, service.ErrInvalidRequest), errors.Is(err, versioning.ErrInvalidVersion),
+		errors.Is(err, config.ErrInvalidProjectOverride):
+		return http.StatusBadRequest, CodeInvalidRequest
+	case errors.Is(err, service.ErrRateLimited):
+		return http.StatusTooManyRequests, CodeRateLimited
+	case errors.Is(err, service.ErrUpstream):
+		return http.StatusBadGateway, CodeUpstreamFailure
+	case errors.Is(err, service.ErrStaleContent):
+		return http.StatusPreconditionFailed, CodeStaleContent
+	case errors.Is(err, history.ErrNothingToUndo), errors.Is(err, history.ErrNothingToRedo),
+		errors.Is(err, service.ErrHistoryDisabled), errors.Is(err, versioning.ErrDisabled),
+		errors.Is(err, service.ErrUsageDisabled), errors.Is(err, config.ErrUnsupportedVersion):
+		return http.StatusConflict, CodeConflict
+	case errors.As(err, &tooLarge):
+		return http.StatusRequestEntityTooLarge, CodePayloadTooLarge
+	default:
+		return http.StatusInternalServerError, CodeInternal
+	}
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/wind/skill-router/internal/history"
+	"github.com/wind/skill-router/internal/profile"
+	"github.com/wind/skill-router/internal/service"
+	"github.com/wind/skill-router/internal/versioning"
+)
+
+func TestErrorStatus(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("%w: notes", service.ErrSkillNotFound), 404, CodeNotFound},
+		{fmt.Errorf("%w: tools", service.ErrPluginNotFound), 404, CodeNotFound},
+		{fmt.Errorf("%w: work", profile.ErrProfileNotFound), 404, CodeNotFound},
+		{fmt.Errorf("%w: notes", service.ErrSkillExists), 409, CodeAlreadyExists},
+		{fmt.Errorf("%w: work", profile.ErrProfileExists), 409, CodeAlreadyExists},
+		{fmt.Errorf("%w: skill %q", service.ErrInvalidName, ".."), 400, CodeInvalidName},
+		{fmt.Errorf("%w: missing name", service.ErrInvalidFrontmatter), 422, CodeInvalidFrontmatter},
+		{fmt.Errorf("%w: a/../b", service.ErrInvalidPath), 400, CodeInvalidRequest},
+		{fmt.Errorf("%w: truncated", service.ErrInvalidBackup), 400, CodeInvalidRequest},
+		{versioning.ErrInvalidVersion, 400, CodeInvalidRequest},
+		{fmt.Errorf("%w: GitHub API rate limit exceeded", service.ErrRateLimited), 429, CodeRateLimited},
+		{fmt.Errorf("%w: connection refused", service.ErrUpstream), 502, CodeUpstreamFailure},
+		{service.ErrStaleContent, 412, CodeStaleContent},
+		{history.ErrNothingToUndo, 409, CodeConflict},
+		{versioning.ErrDisabled, 409, CodeConflict},
+		{service.ErrUsageDisabled, 409, CodeConflict},
+		{&http.MaxBytesError{Limit: 1}, 413, CodePayloadTooLarge},
+		{errors.New("disk full"), 500, CodeInternal},
+	}
+	for _, c := range cases {
+		status, code := errorStatus(c.err)
+		if status != c.status || code != c.code {
+			t.Errorf("%v: expected %d %s, got %d %s", c.err, c.status, c.code, status, code)
+		}
+	}
+}
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) Problem {
+	t.Helper()
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected a problem body, got %q: %s", ct, rec.Body.String())
+	}
+	var p Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if p.Status != rec.Code {
+		t.Errorf("problem status %d does not match response %d", p.Status, rec.Code)
+	}
+	return p
+}
+
+func TestWriteError_HidesInternalDetail(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeError(rec, errors.New("open /home/someone/.claude/settings.json: disk full"))
+	p := decodeProblem(t, rec)
+	if rec.Code != 500 || p.Code != CodeInternal {
+		t.Fatalf("expected a 500 internal problem, got %d %+v", rec.Code, p)
+	}
+	if strings.Contains(p.Detail, "someone") || p.Detail == "" {
+		t.Errorf("expected a generic detail, got %q", p.Detail)
+	}
+}
+
+func TestProblemResponses(t *testing.T) {
+	mux, _ := newTestRouter(t)
+
+	upload := func(filename, content string) *http.Request {
+		body, contentType := multipartBody(t, "file", filename, content)
+		req := httptest.NewRequest("POST", "/api/skills/upload", body)
+		req.Header.Set("Content-Type", contentType)
+		return req
+	}
+
+	attach := func(field, filename, content string) *http.Request {
+		body, contentType := multipartBody(t, field, filename, content)
+		req := httptest.NewRequest("POST", "/api/skills/notes/files", body)
+		req.Header.Set("Content-Type", contentType)
+		return req
+	}
+
+	cases := []struct {
+		name   string
+		req    *http.Request
+		status int
+		code   string
+	}{
+		{"disable missing skill", httptest.NewRequest("POST", "/api/skills/missing/disable", nil), 404, CodeNotFound},
+		{"enable an enabled skill", httptest.NewRequest("POST", "/api/skills/notes/enable", nil), 404, CodeNotFound},
+		{"upload existing skill", upload("notes.md", "---\nname: notes\ndescription: Again\n---\n"), 409, CodeAlreadyExists},
+		{"write without frontmatter", func() *http.Request {
+			req := httptest.NewRequest("PUT", "/api/skills/notes/content", strings.NewReader("just text"))
+			req.Header.Set("If-Match", `"any"`)
+			return req
+		}(), 422, CodeInvalidFrontmatter},
+		{"attach nothing", attach("other", "a.txt", "x"), 400, CodeInvalidRequest},
+		{"attach too large a file", attach("file", "big.bin", strings.Repeat("x", maxFileSize+1)), 413, CodePayloadTooLarge},
+		{"upload with bad name", upload("x.md", "---\nname: ..\ndescription: Up\n---\n"), 400, CodeInvalidName},
+		{"rename to bad name", httptest.NewRequest("POST", "/api/skills/notes/rename", strings.NewReader(`{"name":"a/b"}`)), 400, CodeInvalidName},
+		{"install from non-GitHub URL", httptest.NewRequest("POST", "/api/skills/install", strings.NewReader(`{"url":"https://example.com/x/y"}`)), 400, CodeInvalidRequest},
+		{"bad JSON", httptest.NewRequest("POST", "/api/skills/bulk", strings.NewReader("{")), 400, CodeInvalidRequest},
+		{"write without If-Match", httptest.NewRequest("PUT", "/api/skills/notes/content", strings.NewReader("x")), 428, CodePreconditionRequired},
+		{"stale write", func() *http.Request {
+			req := httptest.NewRequest("PUT", "/api/skills/notes/content", strings.NewReader("---\nname: notes\ndescription: New\n---\n"))
+			req.Header.Set("If-Match", `"stale"`)
+			return req
+		}(), 412, CodeStaleContent},
+		{"nothing to redo", httptest.NewRequest("POST", "/api/history/redo", nil), 409, CodeConflict},
+		{"missing profile", httptest.NewRequest("GET", "/api/profiles/nope/diff", nil), 404, CodeNotFound},
+	}
+	for _, c := range cases {
+		rec := serve(t, mux, c.req)
+		if rec.Code != c.status {
+			t.Errorf("%s: expected %d, got %d: %s", c.name, c.status, rec.Code, rec.Body.String())
+			continue
+		}
+		if p := decodeProblem(t, rec); p.Code != c.code || p.Detail == "" {
+			t.Errorf("%s: unexpected problem %+v", c.name, p)
+		}
+	}
+	// An upload without frontmatter is named after the file
+	if rec := serve(t, mux, upload("plain.md", "just text")); rec.Code != http.StatusCreated {
+		t.Errorf("upload without frontmatter: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/wind/skill-router/internal/watcher"
+)
+
+// keepAliveInterval keeps idle event streams from being closed by proxies.
+const keepAliveInterval = 30 * time.Second
+
+type EventsHandler struct {
+	watcher *watcher.Watcher
+}
+
+func NewEventsHandler(w *watcher.Watcher) *EventsHandler {
+	return &EventsHandler{watcher: w}
+}
+
+// Stream sends a Server-Sent Event named "change" whenever skills or
+// overrides change on disk.
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeProblem(w, http.StatusInternalServerError, CodeInternal, "Streaming not supported")
+		return
+	}
+
+	events, unsubscribe := h.watcher.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(ev)
+			fmt.Fprintf(w, "event: change\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
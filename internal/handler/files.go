@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+
+	"github.com/wind/skill-router/internal/service"
+)
+
+// maxFileSize limits files written or uploaded into a skill directory, and
+// maxUploadSize a whole upload of several files.
+const (
+	maxFileSize   = 10 << 20
+	maxUploadSize = 4 * maxFileSize
+)
+
+// ListFiles lists the file tree of a user skill.
+func (h *SkillHandler) ListFiles(w http.ResponseWriter, r *http.Request) {
+	files, err := h.svc.ListSkillFiles(r.PathValue("name"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(files)
+}
+
+func (h *SkillHandler) ReadFile(w http.ResponseWriter, r *http.Request) {
+	content, err := h.svc.ReadSkillFile(r.PathValue("name"), r.PathValue("path"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Skill files are arbitrary user content; never let a browser render them
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(r.PathValue("path"))}))
+	w.Write(content)
+}
+
+func (h *SkillHandler) WriteFile(w http.ResponseWriter, r *http.Request) {
+	content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxFileSize))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	file, err := h.svc.WriteSkillFile(r.PathValue("name"), r.PathValue("path"), content)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(file)
+}
+
+func (h *SkillHandler) UploadFiles(w http.ResponseWriter, r *http.Request) {
+	name, dir := r.PathValue("name"), r.PathValue("path")
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	if err := r.ParseMultipartForm(maxFileSize); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, err)
+			return
+		}
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid upload")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	headers := r.MultipartForm.File["file"]
+	if len(headers) == 0 {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "No file uploaded")
+		return
+	}
+	for _, header := range headers {
+		if header.Size > maxFileSize {
+			writeProblem(w, http.StatusRequestEntityTooLarge, CodePayloadTooLarge, header.Filename+" is too large")
+			return
+		}
+	}
+
+	written := []service.SkillFile{}
+	for _, header := range headers {
+		f, err := header.Open()
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+		content, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+
+		file, err := h.svc.WriteSkillFile(name, path.Join(dir, path.Base(header.Filename)), content)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		written = append(written, file)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(written)
+}
+
+type ChmodRequest struct {
+	Executable bool `json:"executable"`
+}
+
+func (h *SkillHandler) ChmodFile(w http.ResponseWriter, r *http.Request) {
+	var req ChmodRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid request")
+		return
+	}
+
+	file, err := h.svc.SetSkillFileExecutable(r.PathValue("name"), r.PathValue("path"), req.Executable)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(file)
+}
+
+func (h *SkillHandler) DeleteFile(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.DeleteSkillFile(r.PathValue("name"), r.PathValue("path")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
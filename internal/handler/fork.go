@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type ForkRequest struct {
+	Name            string `json:"name"`
+	DisableOriginal bool   `json:"disableOriginal"`
+}
+
+func (h *SkillHandler) ForkPluginSkill(w http.ResponseWriter, r *http.Request) {
+	var req ForkRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid request")
+			return
+		}
+	}
+
+	origin, err := h.svc.ForkPluginSkill(r.PathValue("plugin"), r.PathValue("skill"), strings.TrimSpace(req.Name), req.DisableOriginal)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(origin)
+}
+
+func (h *SkillHandler) GetOrigin(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	origin, err := h.svc.ReadSkillOrigin(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if origin == nil {
+		writeProblem(w, http.StatusNotFound, CodeNotFound, "Skill was not forked from a plugin")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(origin)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/wind/skill-router/internal/history"
+)
+
+// HistoryResponse lists journal entries newest first. Skill contents are
+// left out to keep the response small.
+type HistoryResponse struct {
+	Entries []history.Entry `json:"entries"`
+	CanUndo int             `json:"canUndo"`
+	CanRedo int             `json:"canRedo"`
+}
+
+func (h *SkillHandler) History(w http.ResponseWriter, r *http.Request) {
+	entries, undo, redo, err := h.svc.History()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	slices.Reverse(entries)
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit >= 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+	for i := range entries {
+		entries[i].Op.Content = nil
+		entries[i].Op.Previous = nil
+		entries[i].Op.ContentBlob, entries[i].Op.PreviousBlob = "", ""
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(HistoryResponse{Entries: entries, CanUndo: undo, CanRedo: redo})
+}
+
+func (h *SkillHandler) Undo(w http.ResponseWriter, r *http.Request) {
+	h.historyStep(w, h.svc.Undo)
+}
+
+func (h *SkillHandler) Redo(w http.ResponseWriter, r *http.Request) {
+	h.historyStep(w, h.svc.Redo)
+}
+
+func (h *SkillHandler) historyStep(w http.ResponseWriter, step func() (*history.Entry, error)) {
+	entry, err := step()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	entry.Op.Content = nil
+	entry.Op.Previous = nil
+	entry.Op.ContentBlob, entry.Op.PreviousBlob = "", ""
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/wind/skill-router/internal/manifest"
+)
+
+func (h *SkillHandler) GetManifest(w http.ResponseWriter, r *http.Request) {
+	m, err := manifest.Current(h.svc)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
+
+func (h *SkillHandler) PlanManifest(w http.ResponseWriter, r *http.Request) {
+	plan, ok := h.planFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
+
+func (h *SkillHandler) ApplyManifest(w http.ResponseWriter, r *http.Request) {
+	plan, ok := h.planFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	results := manifest.Apply(h.svc, plan)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]manifest.Result{"results": results})
+}
+
+func (h *SkillHandler) planFromRequest(w http.ResponseWriter, r *http.Request) (*manifest.Plan, bool) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return nil, false
+	}
+
+	m, err := manifest.Parse(body)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid manifest: "+err.Error())
+		return nil, false
+	}
+
+	opts := manifest.Options{Prune: r.URL.Query().Get("prune") == "true"}
+	plan, err := manifest.Diff(h.svc, m, opts)
+	if err != nil {
+		writeProblem(w, http.StatusUnprocessableEntity, CodeInvalidRequest, err.Error())
+		return nil, false
+	}
+	return plan, true
+}
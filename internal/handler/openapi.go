@@ -0,0 +1,13 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/wind/skill-router/internal/openapi"
+)
+
+// OpenAPI serves the OpenAPI document describing the routes of NewRouter.
+func OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openapi.Spec)
+}
@@ -0,0 +1,382 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wind/skill-router/internal/history"
+	"github.com/wind/skill-router/internal/openapi"
+	"github.com/wind/skill-router/internal/profile"
+	"github.com/wind/skill-router/internal/service"
+	"github.com/wind/skill-router/internal/usage"
+	"github.com/wind/skill-router/internal/versioning"
+	"github.com/wind/skill-router/internal/watcher"
+)
+
+var loadSpec = sync.OnceValues(openapi.Load)
+
+func spec(t *testing.T) *openapi.Document {
+	t.Helper()
+	doc, err := loadSpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// operationFor returns the documented operation of the route req matches, or
+// nil when the mux itself answers with 404 or 405.
+func operationFor(t *testing.T, mux *Router, req *http.Request) *openapi.Operation {
+	t.Helper()
+	_, pattern := mux.Handler(req)
+	if pattern == "" {
+		return nil
+	}
+	method, path, _ := strings.Cut(pattern, " ")
+	op := spec(t).Operation(method, strings.ReplaceAll(path, "...}", "}"))
+	if op == nil {
+		t.Errorf("route %q is not in openapi.json", pattern)
+	}
+	return op
+}
+
+// checkSpec fails t unless the exchange of req and rec matches op. Request
+// bodies are only checked when the handler accepted them.
+func checkSpec(t *testing.T, op *openapi.Operation, req *http.Request, body []byte, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	where := req.Method + " " + req.URL.String()
+	if rec.Code < 300 {
+		if err := checkRequest(spec(t), op, req, body); err != nil {
+			t.Errorf("%s: request does not match openapi.json: %v", where, err)
+		}
+	}
+	if err := checkResponse(spec(t), op, rec); err != nil {
+		t.Errorf("%s: %d response does not match openapi.json: %v", where, rec.Code, err)
+	}
+}
+
+func checkRequest(doc *openapi.Document, op *openapi.Operation, req *http.Request, body []byte) error {
+	for name := range req.URL.Query() {
+		if !slices.ContainsFunc(op.Parameters, func(p *openapi.Parameter) bool { return p.In == "query" && p.Name == name }) {
+			return fmt.Errorf("undocumented query parameter %q", name)
+		}
+	}
+	for _, p := range op.Parameters {
+		if p.In == "header" && p.Required && req.Header.Get(p.Name) == "" {
+			return fmt.Errorf("missing required header %s", p.Name)
+		}
+	}
+
+	rb := op.RequestBody
+	if len(body) == 0 {
+		if rb != nil && rb.Required {
+			return errors.New("missing required body")
+		}
+		return nil
+	}
+	if rb == nil {
+		return errors.New("undocumented request body")
+	}
+	contentType := req.Header.Get("Content-Type")
+	if contentType == "" && len(rb.Content) == 1 {
+		// Handlers do not look at the type of a body they only take one way
+		for contentType = range rb.Content {
+		}
+	}
+	mediaType, mt := openapi.MatchMediaType(rb.Content, contentType)
+	switch {
+	case mt == nil:
+		return fmt.Errorf("undocumented request type %q", contentType)
+	case openapi.IsJSON(mediaType):
+		return doc.ValidateJSON(mt.Schema, body)
+	case mediaType == "multipart/form-data":
+		return checkForm(doc.Schema(mt.Schema), contentType, body)
+	}
+	return nil
+}
+
+// checkForm checks that the fields of a multipart body are the documented
+// properties.
+func checkForm(s *openapi.Schema, contentType string, body []byte) error {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return err
+	}
+	mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	seen := make(map[string]bool)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, ok := s.Properties[part.FormName()]; !ok {
+			return fmt.Errorf("undocumented form field %q", part.FormName())
+		}
+		seen[part.FormName()] = true
+	}
+	for _, name := range s.Required {
+		if !seen[name] {
+			return fmt.Errorf("missing form field %q", name)
+		}
+	}
+	return nil
+}
+
+func checkResponse(doc *openapi.Document, op *openapi.Operation, rec *httptest.ResponseRecorder) error {
+	r := op.Responses[strconv.Itoa(rec.Code)]
+	if r == nil && rec.Code >= 400 {
+		r = op.Responses["default"]
+	}
+	if r = doc.Response(r); r == nil {
+		return errors.New("undocumented status")
+	}
+	for name := range r.Headers {
+		if rec.Header().Get(name) == "" {
+			return fmt.Errorf("missing header %s", name)
+		}
+	}
+	if len(r.Content) == 0 {
+		if rec.Body.Len() > 0 {
+			return fmt.Errorf("undocumented body %q", rec.Body.String())
+		}
+		return nil
+	}
+	contentType := rec.Header().Get("Content-Type")
+	mediaType, mt := openapi.MatchMediaType(r.Content, contentType)
+	if mt == nil {
+		return fmt.Errorf("undocumented response type %q", contentType)
+	}
+	if openapi.IsJSON(mediaType) {
+		return doc.ValidateJSON(mt.Schema, rec.Body.Bytes())
+	}
+	return nil
+}
+
+func TestOpenAPI_RoutesMatchSpec(t *testing.T) {
+	doc := spec(t)
+
+	documented := make(map[string]bool)
+	ids := make(map[string]bool)
+	for path, ops := range doc.Paths {
+		for method, op := range ops {
+			documented[strings.ToUpper(method)+" "+path] = true
+			if op.OperationID == "" || ids[op.OperationID] {
+				t.Errorf("%s %s: missing or duplicate operationId %q", method, path, op.OperationID)
+			}
+			ids[op.OperationID] = true
+			if op.Responses["default"] == nil {
+				t.Errorf("%s %s: errors are not documented", method, path)
+			}
+		}
+	}
+
+	for _, rt := range routes(&SkillHandler{}, &ProfileHandler{}, &EventsHandler{}) {
+		key := strings.ReplaceAll(rt.pattern, "...}", "}")
+		if !documented[key] {
+			t.Errorf("route %q is not in openapi.json", rt.pattern)
+		}
+		delete(documented, key)
+	}
+	for key := range documented {
+		t.Errorf("openapi.json documents %s, which is not routed", key)
+	}
+}
+
+// TestOpenAPI_EveryOperation drives every operation to a successful
+// response, so that serve checks each handler's shapes against the spec.
+func TestOpenAPI_EveryOperation(t *testing.T) {
+	_, tmpDir := newTestRouter(t)
+
+	svc := service.NewSkillService(tmpDir)
+	svc.EnableHistory(history.Open(tmpDir))
+	svc.EnableUsage(usage.Open(tmpDir))
+	h := NewSkillHandler(svc)
+	ph := NewProfileHandler(svc, profile.NewStore(tmpDir))
+
+	transcript := filepath.Join(tmpDir, "projects", "demo", "session.jsonl")
+	os.MkdirAll(filepath.Dir(transcript), 0755)
+	os.WriteFile(transcript, []byte(`{"type":"assistant","timestamp":"2024-01-02T03:04:05Z","message":{"content":[{"type":"tool_use","id":"t1","name":"Skill","input":{"skill":"gone"}}]}}`+"\n"), 0644)
+
+	// Operations that cannot succeed here
+	unreachable := map[string]bool{"installSkills": true}
+	_, gitErr := exec.LookPath("git")
+	if gitErr == nil {
+		svc.SetVersioning(versioning.Open(tmpDir))
+	} else {
+		for _, id := range []string{"enableVersioning", "listSkillVersions", "diffSkill", "revertSkill"} {
+			unreachable[id] = true
+		}
+	}
+	var eh *EventsHandler
+	if wt, err := watcher.New(svc.WatchTargets(), 10*time.Millisecond); err == nil {
+		defer wt.Close()
+		eh = NewEventsHandler(wt)
+	} else {
+		unreachable["streamEvents"] = true
+	}
+	mux := NewRouter(h, ph, eh)
+
+	reached := make(map[string]bool)
+	call := func(req *http.Request, want int) *httptest.ResponseRecorder {
+		t.Helper()
+		if op := operationFor(t, mux, req); op != nil && want < 400 {
+			reached[op.OperationID] = true
+		}
+		rec := serve(t, mux, req)
+		if rec.Code != want {
+			t.Fatalf("%s %s: expected %d, got %d: %s", req.Method, req.URL, want, rec.Code, rec.Body.String())
+		}
+		return rec
+	}
+	send := func(method, path, body string, want int) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if strings.HasPrefix(body, "{") {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		return call(req, want)
+	}
+	upload := func(path, filename, content string, want int) *httptest.ResponseRecorder {
+		t.Helper()
+		body, contentType := multipartBody(t, "file", filename, content)
+		req := httptest.NewRequest("POST", path, body)
+		req.Header.Set("Content-Type", contentType)
+		return call(req, want)
+	}
+	decode := func(rec *httptest.ResponseRecorder, v any) {
+		t.Helper()
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	send("GET", "/api/openapi.json", "", 200)
+	send("GET", "/api/versioning", "", 200)
+	if gitErr == nil {
+		send("POST", "/api/versioning", "", 200)
+	}
+
+	// User skills
+	send("GET", "/api/skills", "", 200)
+	send("GET", "/api/skills/search?q=notes&enabled=true&tag=x", "", 200)
+	send("GET", "/api/skills/collisions", "", 200)
+	send("GET", "/api/skills/budget?budget=1", "", 200)
+	send("GET", "/api/skills/budget?budget=none", "", 400)
+	send("GET", "/api/skills/usage?unusedDays=30", "", 200)
+	send("GET", "/api/skills/usage?unusedDays=0", "", 400)
+	upload("/api/skills/upload", "uploaded.md", "---\nname: uploaded\ndescription: Uploaded\n---\n", 201)
+	send("POST", "/api/skills/install", `{"url":"https://example.com/a/b"}`, 400)
+	etag := send("GET", "/api/skills/notes/content", "", 200).Header().Get("ETag")
+	req := httptest.NewRequest("GET", "/api/skills/notes/content", nil)
+	req.Header.Set("If-None-Match", etag)
+	call(req, 304)
+	req = httptest.NewRequest("PUT", "/api/skills/notes/content", strings.NewReader("---\nname: notes\ndescription: Edited\n---\n"))
+	req.Header.Set("If-Match", etag)
+	req.Header.Set("Content-Type", "text/markdown")
+	call(req, 204)
+	if gitErr == nil {
+		var versions []versioning.Version
+		decode(send("GET", "/api/skills/notes/versions", "", 200), &versions)
+		if len(versions) < 2 {
+			t.Fatalf("expected two versions of notes, got %d", len(versions))
+		}
+		oldest := versions[len(versions)-1].Hash
+		send("GET", "/api/skills/notes/diff?from="+oldest, "", 200)
+		send("POST", "/api/skills/notes/revert", `{"version":"`+oldest+`"}`, 200)
+	}
+	send("POST", "/api/skills/notes/duplicate", `{"name":"copy"}`, 201)
+	send("POST", "/api/skills/copy/rename", `{"name":"renamed"}`, 200)
+	send("POST", "/api/skills/renamed/disable", "", 200)
+	send("POST", "/api/skills/renamed/enable", "", 200)
+	send("POST", "/api/skills/bulk", `{"action":"disable","targets":[{"type":"user","name":"renamed"}]}`, 200)
+	send("POST", "/api/skills/bulk", `{"action":"enable","targets":[{"type":"user","name":"missing"}]}`, 422)
+
+	// Skill files
+	send("PUT", "/api/skills/notes/files/docs/a.txt", "hello", 200)
+	send("GET", "/api/skills/notes/files/docs/a.txt", "", 200)
+	send("PATCH", "/api/skills/notes/files/docs/a.txt", `{"executable":true}`, 200)
+	upload("/api/skills/notes/files", "b.txt", "b", 201)
+	upload("/api/skills/notes/files/docs", "c.txt", "c", 201)
+	send("GET", "/api/skills/notes/files", "", 200)
+	send("DELETE", "/api/skills/notes/files/docs/a.txt", "", 200)
+
+	// Plugins
+	send("GET", "/api/plugins/tools/skills/skills/content", "", 200)
+	send("POST", "/api/plugins/tools/skills/skills/fork", `{"name":"forked"}`, 201)
+	send("GET", "/api/skills/forked/origin", "", 200)
+	send("POST", "/api/plugins/tools/skills/skills/disable", "", 200)
+	send("POST", "/api/plugins/tools/skills/skills/enable", "", 200)
+	send("POST", "/api/plugins/tools/disable", "", 200)
+	send("POST", "/api/plugins/tools/enable", "", 200)
+
+	// Manifest, overrides and profiles
+	manifest := `{"skills":[{"name":"notes"},{"name":"forked","enabled":false}],"disabledPlugins":["tools"]}`
+	send("GET", "/api/manifest", "", 200)
+	send("POST", "/api/manifest/plan", manifest, 200)
+	send("POST", "/api/manifest/apply", manifest, 200)
+	// Claude has been run in the project, so it has transcripts
+	projectDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "projects", regexp.MustCompile("[^a-zA-Z0-9]").ReplaceAllString(projectDir, "-")), 0755)
+	project, _ := json.Marshal(ProjectOverrideRequest{Project: projectDir, Skill: "notes", State: "disabled"})
+	send("POST", "/api/project/overrides", string(project), 200)
+	send("POST", "/api/plugins/gone/skills/x/disable", "", 200)
+	send("GET", "/api/overrides/orphans", "", 200)
+	send("POST", "/api/overrides/orphans/prune", "", 200)
+	send("POST", "/api/profiles", `{"name":"work"}`, 201)
+	send("GET", "/api/profiles", "", 200)
+	send("GET", "/api/profiles/work/diff", "", 200)
+	send("POST", "/api/profiles/work/activate", "", 200)
+	send("DELETE", "/api/profiles/work", "", 200)
+
+	// History, trash and backups
+	send("GET", "/api/history?limit=10", "", 200)
+	send("POST", "/api/history/undo", "", 200)
+	send("POST", "/api/history/redo", "", 200)
+	send("DELETE", "/api/skills/uploaded?enabled=true", "", 200)
+	send("DELETE", "/api/plugins/tools", "", 200)
+	var items []service.TrashItem
+	decode(send("GET", "/api/trash", "", 200), &items)
+	if len(items) != 3 {
+		t.Fatalf("expected the plugin, the skill and the deleted file in the trash, got %d", len(items))
+	}
+	send("POST", "/api/trash/"+items[0].ID+"/restore", "", 200)
+	send("DELETE", "/api/trash/"+items[1].ID, "", 200)
+	send("DELETE", "/api/trash", "", 200)
+	archive := send("GET", "/api/backup", "", 200).Body.String()
+	upload("/api/backup/restore?mode=merge&dryRun=true", "backup.tar.gz", archive, 200)
+
+	if eh != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		call(httptest.NewRequest("GET", "/api/events", nil).WithContext(ctx), 200)
+	}
+
+	for path, ops := range spec(t).Paths {
+		for method, op := range ops {
+			if !reached[op.OperationID] && !unreachable[op.OperationID] {
+				t.Errorf("%s %s (%s) was not exercised", method, path, op.OperationID)
+			}
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package handler
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/history"
+	"github.com/wind/skill-router/internal/manifest"
+	"github.com/wind/skill-router/internal/model"
+	"github.com/wind/skill-router/internal/openapi"
+	"github.com/wind/skill-router/internal/profile"
+	"github.com/wind/skill-router/internal/service"
+	"github.com/wind/skill-router/internal/versioning"
+)
+
+// schemaTypes maps the schemas of openapi.json to the Go types the handlers
+// encode and decode.
+var schemaTypes = map[string]any{
+	"Skill":                  model.Skill{},
+	"SnippetPart":            service.SnippetPart{},
+	"SearchResult":           service.SearchResult{},
+	"SkillRef":               service.SkillRef{},
+	"Suggestion":             service.Suggestion{},
+	"Collision":              service.Collision{},
+	"BudgetGroup":            service.BudgetGroup{},
+	"BudgetWarning":          service.BudgetWarning{},
+	"ContextBudget":          service.ContextBudget{},
+	"SkillUsage":             service.SkillUsage{},
+	"UnknownUsage":           service.UnknownUsage{},
+	"UsageReport":            service.UsageReport{},
+	"InstallRequest":         InstallRequest{},
+	"RenameRequest":          RenameRequest{},
+	"BulkTarget":             service.BulkTarget{},
+	"BulkRequest":            BulkRequest{},
+	"BulkResult":             service.BulkResult{},
+	"BulkResponse":           BulkResponse{},
+	"SkillOrigin":            service.SkillOrigin{},
+	"ForkRequest":            ForkRequest{},
+	"SkillVersion":           versioning.Version{},
+	"RevertRequest":          RevertRequest{},
+	"VersioningStatus":       VersioningStatus{},
+	"SkillFile":              service.SkillFile{},
+	"ChmodRequest":           ChmodRequest{},
+	"OrphanedOverrides":      config.OrphanedOverrides{},
+	"TrashItem":              service.TrashItem{},
+	"OverridesChange":        config.OverridesChange{},
+	"Operation":              history.Operation{},
+	"HistoryEntry":           history.Entry{},
+	"History":                HistoryResponse{},
+	"PluginInfo":             service.PluginInfo{},
+	"RestoreSkill":           service.RestoreSkill{},
+	"RestoreReport":          service.RestoreReport{},
+	"ProjectOverrideRequest": ProjectOverrideRequest{},
+	"SkillSpec":              manifest.SkillSpec{},
+	"Manifest":               manifest.Manifest{},
+	"Action":                 manifest.Action{},
+	"Plan":                   manifest.Plan{},
+	"ManifestResult":         manifest.Result{},
+	"Profile":                profile.Profile{},
+	"ProfileInfo":            ProfileInfo{},
+	"CreateProfileRequest":   CreateProfileRequest{},
+	"Problem":                Problem{},
+}
+
+// jsonFields returns the JSON names of the fields of t, with embedded
+// structs flattened, and whether each is always encoded.
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := make(map[string]bool)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.Anonymous && tag == "" {
+			for name, always := range jsonFields(f.Type) {
+				fields[name] = always
+			}
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = !strings.Contains(opts, "omitempty")
+	}
+	return fields
+}
+
+// reachable returns the schemas used, directly or not, by the bodies of
+// requests or of responses.
+func reachable(doc *openapi.Document, responses bool) map[string]bool {
+	seen := make(map[string]bool)
+	var walk func(s *openapi.Schema)
+	walk = func(s *openapi.Schema) {
+		if s == nil {
+			return
+		}
+		if name := openapi.RefName(s); name != "" {
+			if seen[name] {
+				return
+			}
+			seen[name] = true
+		}
+		s = doc.Schema(s)
+		walk(s.Items)
+		for _, p := range s.Properties {
+			walk(p)
+		}
+	}
+	for _, ops := range doc.Paths {
+		for _, op := range ops {
+			if !responses && op.RequestBody != nil {
+				for _, mt := range op.RequestBody.Content {
+					walk(mt.Schema)
+				}
+			}
+			if responses {
+				for _, r := range op.Responses {
+					for _, mt := range doc.Response(r).Content {
+						walk(mt.Schema)
+					}
+				}
+			}
+		}
+	}
+	return seen
+}
+
+func TestOpenAPI_SchemasMatchGoTypes(t *testing.T) {
+	doc := spec(t)
+	inResponses, inRequests := reachable(doc, true), reachable(doc, false)
+
+	for name, s := range doc.Components.Schemas {
+		if s.Type != "object" {
+			continue
+		}
+		v, ok := schemaTypes[name]
+		if !ok {
+			// Inline response objects, checked by TestOpenAPI_EveryOperation
+			if len(s.Properties) > 1 {
+				t.Errorf("schema %s has no Go type", name)
+			}
+			continue
+		}
+		fields := jsonFields(reflect.TypeOf(v))
+		for prop := range s.Properties {
+			if _, ok := fields[prop]; !ok {
+				t.Errorf("%s: property %s is not a field of %T", name, prop, v)
+			}
+		}
+		for field, always := range fields {
+			if _, ok := s.Properties[field]; !ok {
+				t.Errorf("%s: field %s of %T is not documented", name, field, v)
+				continue
+			}
+			// Fields a response always carries are required; request
+			// fields may be optional whatever the Go type says
+			if inResponses[name] && !inRequests[name] && always != slices.Contains(s.Required, field) {
+				t.Errorf("%s: %s is encoded always=%v but required=%v", name, field, always, !always)
+			}
+		}
+	}
+
+	codes := []string{
+		CodeNotFound, CodeAlreadyExists, CodeInvalidName, CodeInvalidFrontmatter, CodeInvalidRequest,
+		CodeUpstreamFailure, CodeRateLimited, CodeStaleContent, CodePreconditionRequired,
+		CodePayloadTooLarge, CodeConflict, CodeInternal,
+	}
+	if enum := doc.Components.Schemas["ErrorCode"].Enum; !slices.Equal(slices.Sorted(slices.Values(enum)), slices.Sorted(slices.Values(codes))) {
+		t.Errorf("ErrorCode enum %v does not match the handler codes %v", enum, codes)
+	}
+}
+
+var (
+	tsFunction  = regexp.MustCompile(`(?m)^export (?:async )?function (\w+)\(`)
+	tsURL       = regexp.MustCompile("(?:fetch|EventSource)\\(`\\$\\{API_BASE\\}([^`]*)`")
+	tsMethod    = regexp.MustCompile(`method: '(\w+)'`)
+	tsQueryKey  = regexp.MustCompile(`query\.(?:set|append)\('(\w+)'|[?&](\w+)=`)
+	tsQueryInit = regexp.MustCompile(`new URLSearchParams\(\{ ([^}]*) \}\)`)
+	tsJSONBody  = regexp.MustCompile(`JSON\.stringify\(\{ ([^}]*) \}\)`)
+	tsFormField = regexp.MustCompile(`formData\.append\('(\w+)'`)
+	tsHeader    = regexp.MustCompile(`'(If-[\w-]+)'`)
+	tsReturn    = regexp.MustCompile(`Promise<(\w+)(\[\])?>`)
+	tsParam     = regexp.MustCompile(`\$\{[^}]*\}`)
+	tsInterface = regexp.MustCompile(`(?ms)^export interface (\w+) \{\n(.*?)^\}`)
+	tsProperty  = regexp.MustCompile(`(?m)^  (\w+)(\??):`)
+	tsUnion     = regexp.MustCompile(`(?s)export type ErrorCode =(.*?)\n\n`)
+	tsString    = regexp.MustCompile(`'([^']*)'`)
+)
+
+// matchPath finds the documented path that a URL template from the web
+// client, with parameters replaced by {}, refers to.
+func matchPath(doc *openapi.Document, template string) string {
+	want := strings.Split(template, "/")
+	for path := range doc.Paths {
+		got := strings.Split(path, "/")
+		if len(got) != len(want) {
+			continue
+		}
+		match := true
+		for i := range got {
+			param := strings.HasPrefix(got[i], "{")
+			if param != (want[i] == "{}") || !param && got[i] != want[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return path
+		}
+	}
+	return ""
+}
+
+func keys(list string) []string {
+	var out []string
+	for _, item := range strings.Split(list, ",") {
+		key, _, _ := strings.Cut(strings.TrimSpace(item), ":")
+		if key = strings.TrimSpace(key); key != "" {
+			out = append(out, key)
+		}
+	}
+	return out
+}
+
+// TestOpenAPI_WebClient keeps web/src/api/skills.ts and the types it uses
+// in line with the spec: every call must hit a documented operation with
+// documented parameters and bodies, and every interface named after a
+// schema must have its properties.
+func TestOpenAPI_WebClient(t *testing.T) {
+	doc := spec(t)
+	src, err := os.ReadFile("../../web/src/api/skills.ts")
+	if err != nil {
+		t.Skipf("web client not available: %v", err)
+	}
+	code := string(src)
+
+	calls := 0
+	starts := tsFunction.FindAllStringSubmatchIndex(code, -1)
+	for i, loc := range starts {
+		end := len(code)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+		fn, body := code[loc[2]:loc[3]], code[loc[0]:end]
+		url := tsURL.FindStringSubmatch(body)
+		if url == nil {
+			continue
+		}
+		calls++
+		method := "GET"
+		if m := tsMethod.FindStringSubmatch(body); m != nil {
+			method = m[1]
+		}
+		pathPart, _, _ := strings.Cut(url[1], "?")
+		template := "/api" + tsParam.ReplaceAllString(pathPart, "{}")
+		path := matchPath(doc, template)
+		op := doc.Operation(method, path)
+		if op == nil {
+			t.Errorf("%s: %s %s is not in openapi.json", fn, method, template)
+			continue
+		}
+
+		documented := func(in, name string) bool {
+			return slices.ContainsFunc(op.Parameters, func(p *openapi.Parameter) bool { return p.In == in && p.Name == name })
+		}
+		var query []string
+		for _, m := range tsQueryKey.FindAllStringSubmatch(body, -1) {
+			query = append(query, m[1]+m[2])
+		}
+		if m := tsQueryInit.FindStringSubmatch(body); m != nil {
+			query = append(query, keys(m[1])...)
+		}
+		for _, name := range query {
+			if !documented("query", name) {
+				t.Errorf("%s: query parameter %s is not documented for %s %s", fn, name, method, path)
+			}
+		}
+		for _, m := range tsHeader.FindAllStringSubmatch(body, -1) {
+			if !documented("header", m[1]) {
+				t.Errorf("%s: header %s is not documented for %s %s", fn, m[1], method, path)
+			}
+		}
+
+		var fields []string
+		var mediaType string
+		if m := tsJSONBody.FindStringSubmatch(body); m != nil {
+			fields, mediaType = keys(m[1]), "application/json"
+		}
+		for _, m := range tsFormField.FindAllStringSubmatch(body, -1) {
+			fields, mediaType = append(fields, m[1]), "multipart/form-data"
+		}
+		if mediaType != "" {
+			if op.RequestBody == nil || op.RequestBody.Content[mediaType] == nil {
+				t.Errorf("%s: %s body is not documented for %s %s", fn, mediaType, method, path)
+				continue
+			}
+			s := doc.Schema(op.RequestBody.Content[mediaType].Schema)
+			for _, f := range fields {
+				if s.Properties[f] == nil {
+					t.Errorf("%s: body field %s is not documented for %s %s", fn, f, method, path)
+				}
+			}
+		}
+
+		// A declared return type named after a schema must be what the
+		// operation returns
+		if m := tsReturn.FindStringSubmatch(body); m != nil && doc.Components.Schemas[m[1]] != nil {
+			var got *openapi.Schema
+			for _, code := range []string{"200", "201"} {
+				if r := doc.Response(op.Responses[code]); r != nil && r.Content["application/json"] != nil {
+					got = r.Content["application/json"].Schema
+				}
+			}
+			if m[2] != "" && got != nil {
+				got = got.Items
+			}
+			if openapi.RefName(got) != m[1] {
+				t.Errorf("%s: returns %s%s but %s %s responds with %s", fn, m[1], m[2], method, path, openapi.RefName(got))
+			}
+		}
+	}
+	if calls == 0 {
+		t.Fatal("found no API calls in skills.ts")
+	}
+
+	types, err := os.ReadFile("../../web/src/types/skill.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range tsInterface.FindAllStringSubmatch(string(types), -1) {
+		name := m[1]
+		s := doc.Components.Schemas[name]
+		if s == nil {
+			continue
+		}
+		var props []string
+		for _, p := range tsProperty.FindAllStringSubmatch(m[2], -1) {
+			props = append(props, p[1])
+			if s.Properties[p[1]] == nil {
+				t.Errorf("interface %s: property %s is not in the %s schema", name, p[1], name)
+				continue
+			}
+			if optional := p[2] == "?"; optional == slices.Contains(s.Required, p[1]) {
+				t.Errorf("interface %s: %s is optional=%v but required=%v in the spec", name, p[1], optional, !optional)
+			}
+		}
+		for prop := range s.Properties {
+			if !slices.Contains(props, prop) && slices.Contains(s.Required, prop) {
+				t.Errorf("interface %s: missing required property %s", name, prop)
+			}
+		}
+	}
+
+	union := tsUnion.FindStringSubmatch(string(types))
+	if union == nil {
+		t.Fatal("ErrorCode type not found in skill.ts")
+	}
+	var tsCodes []string
+	for _, m := range tsString.FindAllStringSubmatch(union[1], -1) {
+		tsCodes = append(tsCodes, m[1])
+	}
+	sort.Strings(tsCodes)
+	if enum := slices.Sorted(slices.Values(doc.Components.Schemas["ErrorCode"].Enum)); !slices.Equal(tsCodes, enum) {
+		t.Errorf("ErrorCode in skill.ts is %v, the spec has %v", tsCodes, enum)
+	}
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/wind/skill-router/internal/manifest"
+	"github.com/wind/skill-router/internal/profile"
+	"github.com/wind/skill-router/internal/service"
+)
+
+type ProfileHandler struct {
+	svc   *service.SkillService
+	store *profile.Store
+}
+
+func NewProfileHandler(svc *service.SkillService, store *profile.Store) *ProfileHandler {
+	return &ProfileHandler{svc: svc, store: store}
+}
+
+type ProfileInfo struct {
+	profile.Profile
+	Active bool `json:"active"`
+}
+
+func (h *ProfileHandler) List(w http.ResponseWriter, r *http.Request) {
+	profiles, err := h.store.List()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	infos := make([]ProfileInfo, 0, len(profiles))
+	for _, p := range profiles {
+		plan, err := profile.Diff(h.svc, &p)
+		infos = append(infos, ProfileInfo{Profile: p, Active: err == nil && len(plan.Actions) == 0})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(infos)
+}
+
+type CreateProfileRequest struct {
+	Name      string `json:"name"`
+	Overwrite bool   `json:"overwrite"`
+}
+
+func (h *ProfileHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req CreateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid request")
+		return
+	}
+	if !profile.ValidName(req.Name) {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidName, "Invalid profile name")
+		return
+	}
+
+	p, err := profile.Snapshot(h.svc, req.Name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.store.Save(p, req.Overwrite); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(p)
+}
+
+func (h *ProfileHandler) Diff(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	p, err := h.store.Get(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	plan, err := profile.Diff(h.svc, p)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
+
+func (h *ProfileHandler) Activate(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	p, err := h.store.Get(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	plan, err := profile.Activate(h.svc, p)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]manifest.Action{"applied": plan.Actions})
+}
+
+func (h *ProfileHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	if err := h.store.Delete(name); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type RenameRequest struct {
+	Name string `json:"name"`
+}
+
+func (h *SkillHandler) Rename(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	h.copySkill(w, r, name, h.svc.RenameSkill, http.StatusOK)
+}
+
+func (h *SkillHandler) Duplicate(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	h.copySkill(w, r, name, h.svc.DuplicateSkill, http.StatusCreated)
+}
+
+func (h *SkillHandler) copySkill(w http.ResponseWriter, r *http.Request, name string, op func(string, string) error, status int) {
+	var req RenameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid request")
+		return
+	}
+
+	if err := op(name, strings.TrimSpace(req.Name)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(status)
+}
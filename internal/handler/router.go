@@ -0,0 +1,153 @@
+package handler
+
+import "net/http"
+
+// NewRouter returns the routes of the HTTP API. A request for a known path
+// with the wrong method gets a 405 with an Allow header, any other path a
+// 404. eh may be nil when live updates are unavailable.
+func NewRouter(h *SkillHandler, ph *ProfileHandler, eh *EventsHandler) *Router {
+	mux := http.NewServeMux()
+	for _, rt := range routes(h, ph, eh) {
+		mux.HandleFunc(rt.pattern, rt.handler)
+	}
+	return &Router{mux}
+}
+
+// Router is the ServeMux of the API. Requests it has no route for get a
+// problem like every other error, instead of ServeMux's plain text 404 and
+// 405 responses.
+type Router struct {
+	*http.ServeMux
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h, pattern := rt.Handler(r)
+	if pattern != "" {
+		rt.ServeMux.ServeHTTP(w, r)
+		return
+	}
+
+	// Let ServeMux decide between 404 and 405 and set Allow, but replace
+	// its body
+	rec := &statusRecorder{header: make(http.Header)}
+	h.ServeHTTP(rec, r)
+	if allow := rec.header.Get("Allow"); allow != "" {
+		w.Header().Set("Allow", allow)
+	}
+	switch rec.status {
+	case http.StatusMethodNotAllowed:
+		writeProblem(w, rec.status, CodeInvalidRequest, r.Method+" is not allowed on "+r.URL.Path)
+	case http.StatusNotFound:
+		writeProblem(w, rec.status, CodeNotFound, "No such endpoint: "+r.URL.Path)
+	default:
+		// A redirect or anything else ServeMux answers itself
+		rt.ServeMux.ServeHTTP(w, r)
+	}
+}
+
+// statusRecorder keeps the status and header of a response and drops its
+// body.
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+func (r *statusRecorder) Header() http.Header { return r.header }
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return len(b), nil
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+type route struct {
+	pattern string
+	handler http.HandlerFunc
+}
+
+// routes lists every route of the API. Each must be described in
+// internal/openapi/openapi.json.
+func routes(h *SkillHandler, ph *ProfileHandler, eh *EventsHandler) []route {
+	rs := []route{
+		// User skills
+		{"GET /api/skills", h.List},
+		{"GET /api/skills/search", h.Search},
+		{"POST /api/skills/bulk", h.Bulk},
+		{"GET /api/skills/collisions", h.Collisions},
+		{"GET /api/skills/budget", h.Budget},
+		{"GET /api/skills/usage", h.Usage},
+		{"POST /api/skills/upload", h.Upload},
+		{"POST /api/skills/install", h.Install},
+		{"DELETE /api/skills/{name}", h.Delete},
+		{"POST /api/skills/{name}/disable", h.Disable},
+		{"POST /api/skills/{name}/enable", h.Enable},
+		{"POST /api/skills/{name}/rename", h.Rename},
+		{"POST /api/skills/{name}/duplicate", h.Duplicate},
+		{"GET /api/skills/{name}/content", h.GetContent},
+		{"PUT /api/skills/{name}/content", h.PutContent},
+		{"GET /api/skills/{name}/origin", h.GetOrigin},
+		{"GET /api/skills/{name}/versions", h.Versions},
+		{"GET /api/skills/{name}/diff", h.Diff},
+		{"POST /api/skills/{name}/revert", h.Revert},
+		{"GET /api/skills/{name}/files", h.ListFiles},
+		{"POST /api/skills/{name}/files", h.UploadFiles},
+		{"GET /api/skills/{name}/files/{path...}", h.ReadFile},
+		{"PUT /api/skills/{name}/files/{path...}", h.WriteFile},
+		{"POST /api/skills/{name}/files/{path...}", h.UploadFiles},
+		{"PATCH /api/skills/{name}/files/{path...}", h.ChmodFile},
+		{"DELETE /api/skills/{name}/files/{path...}", h.DeleteFile},
+
+		// Plugins and plugin skills
+		{"DELETE /api/plugins/{plugin}", h.DeletePlugin},
+		{"POST /api/plugins/{plugin}/disable", h.DisablePlugin},
+		{"POST /api/plugins/{plugin}/enable", h.EnablePlugin},
+		{"GET /api/plugins/{plugin}/skills/{skill}/content", h.GetPluginSkillContent},
+		{"POST /api/plugins/{plugin}/skills/{skill}/fork", h.ForkPluginSkill},
+		{"POST /api/plugins/{plugin}/skills/{skill}/disable", h.DisablePluginSkill},
+		{"POST /api/plugins/{plugin}/skills/{skill}/enable", h.EnablePluginSkill},
+
+		// Versioning, backups, history and trash
+		{"GET /api/versioning", h.GetVersioning},
+		{"POST /api/versioning", h.EnableVersioning},
+		{"GET /api/backup", h.ExportBackup},
+		{"POST /api/backup/restore", h.RestoreBackup},
+		{"GET /api/history", h.History},
+		{"POST /api/history/undo", h.Undo},
+		{"POST /api/history/redo", h.Redo},
+		{"GET /api/trash", h.ListTrash},
+		{"DELETE /api/trash", h.EmptyTrash},
+		{"DELETE /api/trash/{id}", h.DeleteTrash},
+		{"POST /api/trash/{id}/restore", h.RestoreTrash},
+
+		// Manifest, project and override routes
+		{"GET /api/manifest", h.GetManifest},
+		{"POST /api/manifest/plan", h.PlanManifest},
+		{"POST /api/manifest/apply", h.ApplyManifest},
+		{"POST /api/project/overrides", h.SetProjectOverride},
+		{"GET /api/overrides/orphans", h.ListOrphans},
+		{"POST /api/overrides/orphans/prune", h.PruneOrphans},
+
+		// Profiles
+		{"GET /api/profiles", ph.List},
+		{"POST /api/profiles", ph.Create},
+		{"DELETE /api/profiles/{name}", ph.Delete},
+		{"GET /api/profiles/{name}/diff", ph.Diff},
+		{"POST /api/profiles/{name}/activate", ph.Activate},
+
+		// API description
+		{"GET /api/openapi.json", OpenAPI},
+	}
+
+	// Live updates
+	if eh != nil {
+		rs = append(rs, route{"GET /api/events", eh.Stream})
+	}
+	return rs
+}
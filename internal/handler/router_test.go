@@ -0,0 +1,360 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/history"
+	"github.com/wind/skill-router/internal/profile"
+	"github.com/wind/skill-router/internal/service"
+	"github.com/wind/skill-router/internal/watcher"
+)
+
+// newTestRouter sets up a base directory with the user skills "disable" and
+// "notes" and a plugin "tools" whose only skill is named "skills", names that
+// hand-rolled routing used to get wrong.
+func newTestRouter(t *testing.T) (*Router, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+	t.Cleanup(func() { config.SetChangeHook(nil) })
+
+	writeSkill(t, filepath.Join(tmpDir, "skills"), "disable")
+	writeSkill(t, filepath.Join(tmpDir, "skills"), "notes")
+	writeSkill(t, filepath.Join(tmpDir, "plugins", "cache", "acme", "tools", "1.0.0", "skills"), "skills")
+
+	svc := service.NewSkillService(tmpDir)
+	svc.EnableHistory(history.Open(tmpDir))
+	h := NewSkillHandler(svc)
+	ph := NewProfileHandler(svc, profile.NewStore(tmpDir))
+	return NewRouter(h, ph, nil), tmpDir
+}
+
+func writeSkill(t *testing.T, dir, name string) {
+	t.Helper()
+	skillDir := filepath.Join(dir, name)
+	if err := os.MkdirAll(skillDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\nname: " + name + "\ndescription: Test skill " + name + "\n---\nBody"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serve runs req through mux and checks the exchange against openapi.json.
+func serve(t *testing.T, mux *Router, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	op := operationFor(t, mux, req)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if op != nil {
+		checkSpec(t, op, req, body, rec)
+	}
+	return rec
+}
+
+func multipartBody(t *testing.T, field, filename, content string) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile(field, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write([]byte(content))
+	mw.Close()
+	return &body, mw.FormDataContentType()
+}
+
+type routeCase struct {
+	method string
+	path   string
+	body   string
+	want   int
+	check  func(t *testing.T, rec *httptest.ResponseRecorder)
+}
+
+func runRoutes(t *testing.T, mux *Router, cases []routeCase) {
+	t.Helper()
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+		if strings.HasPrefix(c.body, "{") {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		rec := serve(t, mux, req)
+		if rec.Code != c.want {
+			t.Errorf("%s %s: expected %d, got %d: %s", c.method, c.path, c.want, rec.Code, rec.Body.String())
+			continue
+		}
+		if c.check != nil {
+			c.check(t, rec)
+		}
+	}
+}
+
+func TestRouter_UserSkills(t *testing.T) {
+	mux, tmpDir := newTestRouter(t)
+
+	exists := func(rel string) func(t *testing.T, rec *httptest.ResponseRecorder) {
+		return func(t *testing.T, rec *httptest.ResponseRecorder) {
+			if _, err := os.Stat(filepath.Join(tmpDir, rel)); err != nil {
+				t.Errorf("expected %s to exist: %v", rel, err)
+			}
+		}
+	}
+
+	runRoutes(t, mux, []routeCase{
+		{method: "GET", path: "/api/skills", want: 200},
+		{method: "GET", path: "/api/skills/search?q=notes", want: 200},
+		{method: "GET", path: "/api/skills/collisions", want: 200},
+		{method: "GET", path: "/api/skills/budget", want: 200},
+		{method: "GET", path: "/api/skills/usage", want: 409},
+		{method: "POST", path: "/api/skills/upload", want: 400},
+		{method: "POST", path: "/api/skills/install", body: "{", want: 400},
+
+		// A skill named like an action still reaches its own resources
+		{method: "GET", path: "/api/skills/disable/content", want: 200},
+		{method: "PUT", path: "/api/skills/disable/content", body: "x", want: 428},
+		{method: "GET", path: "/api/skills/disable/origin", want: 404},
+		{method: "GET", path: "/api/skills/disable/versions", want: 409},
+		{method: "GET", path: "/api/skills/disable/diff?from=abcd", want: 409},
+		{method: "POST", path: "/api/skills/disable/revert", body: `{"version":"abcd"}`, want: 409},
+		{method: "POST", path: "/api/skills/disable/disable", want: 200, check: exists("skills-disabled/disable")},
+		{method: "POST", path: "/api/skills/disable/enable", want: 200, check: exists("skills/disable")},
+		{method: "POST", path: "/api/skills/disable/duplicate", body: `{"name":"copy"}`, want: 201, check: exists("skills/copy")},
+		{method: "POST", path: "/api/skills/copy/rename", body: `{"name":"renamed"}`, want: 200, check: exists("skills/renamed")},
+		{method: "DELETE", path: "/api/skills/renamed?enabled=true", want: 200},
+		{method: "POST", path: "/api/skills/bulk", body: `{"action":"disable","targets":[{"type":"user","name":"notes"}]}`, want: 200,
+			check: exists("skills-disabled/notes")},
+	})
+}
+
+func TestRouter_SkillFiles(t *testing.T) {
+	mux, tmpDir := newTestRouter(t)
+
+	runRoutes(t, mux, []routeCase{
+		{method: "PUT", path: "/api/skills/disable/files/docs/a.txt", body: "hello", want: 200},
+		{method: "GET", path: "/api/skills/disable/files/docs/a.txt", want: 200, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			if rec.Body.String() != "hello" {
+				t.Errorf("expected file content, got %q", rec.Body.String())
+			}
+			h := rec.Header()
+			if h.Get("Content-Type") != "application/octet-stream" || h.Get("X-Content-Type-Options") != "nosniff" ||
+				h.Get("Content-Disposition") != `attachment; filename=a.txt` {
+				t.Errorf("expected the file as a download, got %v", h)
+			}
+		}},
+		{method: "PATCH", path: "/api/skills/disable/files/docs/a.txt", body: `{"executable":true}`, want: 200},
+		{method: "GET", path: "/api/skills/disable/files", want: 200, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			if !strings.Contains(rec.Body.String(), "docs/a.txt") {
+				t.Errorf("expected docs/a.txt in listing: %s", rec.Body.String())
+			}
+		}},
+		{method: "DELETE", path: "/api/skills/disable/files/docs/a.txt", want: 200},
+	})
+
+	for _, path := range []string{"/api/skills/disable/files", "/api/skills/disable/files/docs"} {
+		body, contentType := multipartBody(t, "file", "b.txt", "upload")
+		req := httptest.NewRequest("POST", path, body)
+		req.Header.Set("Content-Type", contentType)
+		if rec := serve(t, mux, req); rec.Code != 201 {
+			t.Errorf("POST %s: expected 201, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+	}
+	for _, rel := range []string{"b.txt", "docs/b.txt"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, "skills", "disable", rel)); err != nil {
+			t.Errorf("expected uploaded %s: %v", rel, err)
+		}
+	}
+}
+
+func TestRouter_Plugins(t *testing.T) {
+	mux, _ := newTestRouter(t)
+
+	disabled := func(want bool) func(t *testing.T, rec *httptest.ResponseRecorder) {
+		return func(t *testing.T, rec *httptest.ResponseRecorder) {
+			if got := config.IsPluginSkillDisabled("tools", "skills"); got != want {
+				t.Errorf("expected plugin skill disabled=%v, got %v", want, got)
+			}
+		}
+	}
+	pluginDisabled := func(want bool) func(t *testing.T, rec *httptest.ResponseRecorder) {
+		return func(t *testing.T, rec *httptest.ResponseRecorder) {
+			if got := config.IsPluginDisabled("tools"); got != want {
+				t.Errorf("expected plugin disabled=%v, got %v", want, got)
+			}
+		}
+	}
+
+	runRoutes(t, mux, []routeCase{
+		// The plugin skill is named "skills", which used to confuse the router
+		{method: "GET", path: "/api/plugins/tools/skills/skills/content", want: 200},
+		{method: "POST", path: "/api/plugins/tools/skills/skills/disable", want: 200, check: disabled(true)},
+		{method: "POST", path: "/api/plugins/tools/skills/skills/enable", want: 200, check: disabled(false)},
+		{method: "POST", path: "/api/plugins/tools/skills/skills/fork", body: `{"name":"forked"}`, want: 201},
+		{method: "POST", path: "/api/plugins/tools/disable", want: 200, check: pluginDisabled(true)},
+		{method: "POST", path: "/api/plugins/tools/enable", want: 200, check: pluginDisabled(false)},
+		{method: "DELETE", path: "/api/plugins/tools", want: 200},
+		{method: "GET", path: "/api/plugins/tools/skills/skills/content", want: 404},
+	})
+}
+
+func TestRouter_TrashHistoryAndBackup(t *testing.T) {
+	mux, _ := newTestRouter(t)
+
+	var trashID string
+	runRoutes(t, mux, []routeCase{
+		{method: "DELETE", path: "/api/skills/notes?enabled=true", want: 200},
+		{method: "GET", path: "/api/history?limit=5", want: 200},
+		{method: "POST", path: "/api/history/undo", want: 200},
+		{method: "POST", path: "/api/history/redo", want: 200},
+		{method: "GET", path: "/api/trash", want: 200, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var items []service.TrashItem
+			if err := json.Unmarshal(rec.Body.Bytes(), &items); err != nil || len(items) != 1 {
+				t.Fatalf("expected one trash item, got %s", rec.Body.String())
+			}
+			trashID = items[0].ID
+		}},
+	})
+	runRoutes(t, mux, []routeCase{
+		{method: "POST", path: "/api/trash/" + trashID + "/restore", want: 200},
+		{method: "POST", path: "/api/trash/missing/restore", want: 404},
+		{method: "DELETE", path: "/api/trash/missing", want: 404},
+		{method: "DELETE", path: "/api/trash", want: 200},
+
+		{method: "GET", path: "/api/versioning", want: 200},
+		{method: "POST", path: "/api/versioning", want: 409},
+
+		{method: "GET", path: "/api/backup", want: 200},
+		{method: "POST", path: "/api/backup/restore", want: 400},
+	})
+}
+
+func TestRouter_ManifestAndProfiles(t *testing.T) {
+	mux, _ := newTestRouter(t)
+
+	manifest := `{"skills":[{"name":"notes","enabled":false}]}`
+	projectDir := t.TempDir()
+	os.Mkdir(filepath.Join(projectDir, ".claude"), 0755)
+	project, _ := json.Marshal(ProjectOverrideRequest{Project: projectDir, Skill: "notes", State: "disabled"})
+	// Any existing directory is not enough
+	unknown, _ := json.Marshal(ProjectOverrideRequest{Project: t.TempDir(), Skill: "notes", State: "disabled"})
+	invalid, _ := json.Marshal(ProjectOverrideRequest{Project: projectDir, Skill: "notes", State: "off"})
+
+	runRoutes(t, mux, []routeCase{
+		{method: "GET", path: "/api/manifest", want: 200},
+		{method: "POST", path: "/api/manifest/plan", body: manifest, want: 200},
+		{method: "POST", path: "/api/manifest/apply", body: manifest, want: 200},
+		{method: "POST", path: "/api/project/overrides", body: string(project), want: 200},
+		{method: "POST", path: "/api/project/overrides", body: string(unknown), want: 400},
+		{method: "POST", path: "/api/project/overrides", body: string(invalid), want: 400},
+		{method: "GET", path: "/api/overrides/orphans", want: 200},
+		{method: "POST", path: "/api/overrides/orphans/prune", want: 200},
+
+		{method: "POST", path: "/api/profiles", body: `{"name":"work"}`, want: 201},
+		{method: "GET", path: "/api/profiles", want: 200},
+		{method: "GET", path: "/api/profiles/work/diff", want: 200},
+		{method: "POST", path: "/api/profiles/work/activate", want: 200},
+		{method: "DELETE", path: "/api/profiles/work", want: 200},
+		{method: "DELETE", path: "/api/profiles/work", want: 404},
+	})
+}
+
+func TestRouter_Events(t *testing.T) {
+	mux, tmpDir := newTestRouter(t)
+	if rec := serve(t, mux, httptest.NewRequest("GET", "/api/events", nil)); rec.Code != 404 {
+		t.Errorf("expected 404 without a watcher, got %d", rec.Code)
+	}
+
+	wt, err := watcher.New([]watcher.Target{{Path: tmpDir, Depth: 1}}, 10*time.Millisecond)
+	if err != nil {
+		t.Skipf("watcher unavailable: %v", err)
+	}
+	defer wt.Close()
+
+	svc := service.NewSkillService(tmpDir)
+	mux = NewRouter(NewSkillHandler(svc), NewProfileHandler(svc, profile.NewStore(tmpDir)), NewEventsHandler(wt))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := serve(t, mux, httptest.NewRequest("GET", "/api/events", nil).WithContext(ctx))
+	if rec.Code != 200 || rec.Header().Get("Content-Type") != "text/event-stream" {
+		t.Errorf("expected an event stream, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+}
+
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	mux, _ := newTestRouter(t)
+
+	cases := []struct {
+		method string
+		path   string
+		allow  string
+	}{
+		{"POST", "/api/skills", "GET"},
+		{"GET", "/api/skills/disable/disable", "POST"},
+		{"GET", "/api/skills/notes", "DELETE"},
+		{"DELETE", "/api/skills/notes/content", "PUT"},
+		{"GET", "/api/skills/notes/rename", "POST"},
+		{"PUT", "/api/plugins/tools/skills/skills/content", "GET"},
+		{"GET", "/api/plugins/tools/skills/skills/disable", "POST"},
+		{"GET", "/api/plugins/tools", "DELETE"},
+		{"PUT", "/api/trash", "DELETE"},
+		{"GET", "/api/history/undo", "POST"},
+		{"DELETE", "/api/versioning", "POST"},
+		{"PUT", "/api/profiles", "POST"},
+		{"GET", "/api/profiles/work/activate", "POST"},
+		{"GET", "/api/backup/restore", "POST"},
+	}
+	for _, c := range cases {
+		rec := serve(t, mux, httptest.NewRequest(c.method, c.path, nil))
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: expected 405, got %d", c.method, c.path, rec.Code)
+			continue
+		}
+		if allow := rec.Header().Get("Allow"); !strings.Contains(allow, c.allow) {
+			t.Errorf("%s %s: expected Allow to include %s, got %q", c.method, c.path, c.allow, allow)
+		}
+		if p := decodeProblem(t, rec); p.Code != CodeInvalidRequest {
+			t.Errorf("%s %s: unexpected problem %+v", c.method, c.path, p)
+		}
+	}
+}
+
+func TestRouter_NotFound(t *testing.T) {
+	mux, _ := newTestRouter(t)
+
+	for _, path := range []string{
+		"/api/unknown",
+		"/api/skills/notes/unknown",
+		"/api/plugins/tools/skills",
+		"/api/plugins/tools/skills/skills/unknown",
+	} {
+		rec := serve(t, mux, httptest.NewRequest("GET", path, nil))
+		if rec.Code != 404 {
+			t.Errorf("GET %s: expected 404, got %d", path, rec.Code)
+			continue
+		}
+		if p := decodeProblem(t, rec); p.Code != CodeNotFound {
+			t.Errorf("GET %s: unexpected problem %+v", path, p)
+		}
+	}
+}
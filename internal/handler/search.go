@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/service"
+)
+
+// Search handles GET /api/skills/search?q=...&source=...&plugin=...
+// &enabled=true|false&tool=...&tag=...&project=... where tool and tag may be
+// repeated or comma separated.
+func (h *SkillHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	q := service.SearchQuery{
+		Text:    query.Get("q"),
+		Project: query.Get("project"),
+		Source:  query.Get("source"),
+		Plugin:  query.Get("plugin"),
+		Tools:   splitParams(query["tool"]),
+		Tags:    splitParams(query["tag"]),
+	}
+	if q.Project != "" && !config.IsProject(q.Project) {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Unknown project directory")
+		return
+	}
+	if v := query.Get("enabled"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid enabled filter")
+			return
+		}
+		q.Enabled = &enabled
+	}
+
+	results, err := h.svc.Search(q)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+func splitParams(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
@@ -8,7 +8,6 @@ import (
 	"strings"
 
 	"github.com/wind/skill-router/internal/config"
-	"github.com/wind/skill-router/internal/github"
 	"github.com/wind/skill-router/internal/parser"
 	"github.com/wind/skill-router/internal/service"
 )
@@ -22,22 +21,28 @@ func NewSkillHandler(svc *service.SkillService) *SkillHandler {
 }
 
 func (h *SkillHandler) List(w http.ResponseWriter, r *http.Request) {
-	skills, err := h.svc.ListSkills()
+	project := r.URL.Query().Get("project")
+	if project != "" && !config.IsProject(project) {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Unknown project directory")
+		return
+	}
+
+	skills, err := h.svc.ListProjectSkills(project)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
+	h.svc.MarkSkillCollisions(skills)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(skills)
 }
 
 func (h *SkillHandler) Disable(w http.ResponseWriter, r *http.Request) {
-	fileName := strings.TrimPrefix(r.URL.Path, "/api/skills/")
-	fileName = strings.TrimSuffix(fileName, "/disable")
+	fileName := r.PathValue("name")
 
 	if err := h.svc.DisableSkill(fileName); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -45,11 +50,10 @@ func (h *SkillHandler) Disable(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *SkillHandler) Enable(w http.ResponseWriter, r *http.Request) {
-	fileName := strings.TrimPrefix(r.URL.Path, "/api/skills/")
-	fileName = strings.TrimSuffix(fileName, "/enable")
+	fileName := r.PathValue("name")
 
 	if err := h.svc.EnableSkill(fileName); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -57,11 +61,11 @@ func (h *SkillHandler) Enable(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *SkillHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	fileName := strings.TrimPrefix(r.URL.Path, "/api/skills/")
+	fileName := r.PathValue("name")
 	enabled := r.URL.Query().Get("enabled") == "true"
 
 	if err := h.svc.DeleteSkill(fileName, enabled); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -71,14 +75,14 @@ func (h *SkillHandler) Delete(w http.ResponseWriter, r *http.Request) {
 func (h *SkillHandler) Upload(w http.ResponseWriter, r *http.Request) {
 	file, header, err := r.FormFile("file")
 	if err != nil {
-		http.Error(w, "No file uploaded", http.StatusBadRequest)
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "No file uploaded")
 		return
 	}
 	defer file.Close()
 
 	content, err := io.ReadAll(file)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -92,7 +96,7 @@ func (h *SkillHandler) Upload(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.svc.SaveSkill(skillDir, content, overwrite); err != nil {
-		http.Error(w, err.Error(), http.StatusConflict)
+		writeError(w, err)
 		return
 	}
 
@@ -106,68 +110,66 @@ type InstallRequest struct {
 func (h *SkillHandler) Install(w http.ResponseWriter, r *http.Request) {
 	var req InstallRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid request")
 		return
 	}
 
-	files, err := github.FetchSkillFiles(req.URL)
+	installed, err := h.svc.InstallFromGitHub(req.URL)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err)
 		return
 	}
 
-	installed := 0
-	for _, f := range files {
-		content, err := github.DownloadFile(f.DownloadURL)
-		if err != nil {
-			continue
-		}
-		if err := h.svc.SaveSkill(f.Name, content, false); err != nil {
-			continue
-		}
-		installed++
-	}
-
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]int{"installed": installed})
 }
 
-// Plugin skill handlers - these modify the override config file
+type ProjectOverrideRequest struct {
+	Project string `json:"project"` // absolute path of the project directory
+	Plugin  string `json:"plugin"`  // plugin name, empty for user and project skills
+	Skill   string `json:"skill"`   // skill dir name, empty to target the whole plugin
+	State   string `json:"state"`   // "enabled", "disabled" or "inherit"
+}
 
-func (h *SkillHandler) DisablePluginSkill(w http.ResponseWriter, r *http.Request) {
-	// URL format: /api/plugins/{pluginName}/skills/{skillName}/disable
-	path := strings.TrimPrefix(r.URL.Path, "/api/plugins/")
-	parts := strings.Split(path, "/")
-	if len(parts) < 4 {
-		http.Error(w, "Invalid path", http.StatusBadRequest)
+func (h *SkillHandler) SetProjectOverride(w http.ResponseWriter, r *http.Request) {
+	var req ProjectOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid request")
+		return
+	}
+	if !config.IsProject(req.Project) {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Unknown project directory")
 		return
 	}
 
-	pluginName := parts[0]
-	skillName := parts[2]
-
-	if err := config.DisablePluginSkill(pluginName, skillName); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := config.SetProjectOverride(req.Project, req.Plugin, req.Skill, req.State); err != nil {
+		writeError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
-func (h *SkillHandler) EnablePluginSkill(w http.ResponseWriter, r *http.Request) {
-	// URL format: /api/plugins/{pluginName}/skills/{skillName}/enable
-	path := strings.TrimPrefix(r.URL.Path, "/api/plugins/")
-	parts := strings.Split(path, "/")
-	if len(parts) < 4 {
-		http.Error(w, "Invalid path", http.StatusBadRequest)
+// Plugin skill handlers - these modify the override config file
+
+func (h *SkillHandler) DisablePluginSkill(w http.ResponseWriter, r *http.Request) {
+	pluginName := r.PathValue("plugin")
+	skillName := r.PathValue("skill")
+
+	if err := config.DisablePluginSkill(pluginName, skillName); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	pluginName := parts[0]
-	skillName := parts[2]
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *SkillHandler) EnablePluginSkill(w http.ResponseWriter, r *http.Request) {
+	pluginName := r.PathValue("plugin")
+	skillName := r.PathValue("skill")
 
 	if err := config.EnablePluginSkill(pluginName, skillName); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -175,12 +177,10 @@ func (h *SkillHandler) EnablePluginSkill(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *SkillHandler) DisablePlugin(w http.ResponseWriter, r *http.Request) {
-	// URL format: /api/plugins/{pluginName}/disable
-	path := strings.TrimPrefix(r.URL.Path, "/api/plugins/")
-	pluginName := strings.TrimSuffix(path, "/disable")
+	pluginName := r.PathValue("plugin")
 
 	if err := config.DisablePlugin(pluginName); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -188,12 +188,10 @@ func (h *SkillHandler) DisablePlugin(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *SkillHandler) EnablePlugin(w http.ResponseWriter, r *http.Request) {
-	// URL format: /api/plugins/{pluginName}/enable
-	path := strings.TrimPrefix(r.URL.Path, "/api/plugins/")
-	pluginName := strings.TrimSuffix(path, "/enable")
+	pluginName := r.PathValue("plugin")
 
 	if err := config.EnablePlugin(pluginName); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -201,13 +199,34 @@ func (h *SkillHandler) EnablePlugin(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *SkillHandler) DeletePlugin(w http.ResponseWriter, r *http.Request) {
-	// URL format: /api/plugins/{pluginName}
-	pluginName := strings.TrimPrefix(r.URL.Path, "/api/plugins/")
+	pluginName := r.PathValue("plugin")
 
 	if err := h.svc.DeletePlugin(pluginName); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
+
+func (h *SkillHandler) ListOrphans(w http.ResponseWriter, r *http.Request) {
+	orphans, err := h.svc.FindOrphanedOverrides()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orphans)
+}
+
+func (h *SkillHandler) PruneOrphans(w http.ResponseWriter, r *http.Request) {
+	removed, err := h.svc.PruneOrphanedOverrides()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(removed)
+}
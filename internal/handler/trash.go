@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+func (h *SkillHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	items, err := h.svc.ListTrash()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+func (h *SkillHandler) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.EmptyTrash(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *SkillHandler) RestoreTrash(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	item, err := h.svc.RestoreTrash(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+func (h *SkillHandler) DeleteTrash(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if err := h.svc.DeleteTrash(id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/wind/skill-router/internal/service"
+)
+
+func (h *SkillHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	unusedAfter := service.DefaultUnusedAfter
+	if v := r.URL.Query().Get("unusedDays"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days <= 0 {
+			writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid unusedDays")
+			return
+		}
+		unusedAfter = time.Duration(days) * 24 * time.Hour
+	}
+
+	report, err := h.svc.SkillUsage(unusedAfter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
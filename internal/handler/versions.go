@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/wind/skill-router/internal/versioning"
+)
+
+type VersioningStatus struct {
+	Enabled bool `json:"enabled"`
+}
+
+type RevertRequest struct {
+	Version string `json:"version"`
+}
+
+func (h *SkillHandler) GetVersioning(w http.ResponseWriter, r *http.Request) {
+	repo := h.svc.Versioning()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(VersioningStatus{Enabled: repo != nil && repo.Enabled()})
+}
+
+func (h *SkillHandler) EnableVersioning(w http.ResponseWriter, r *http.Request) {
+	repo := h.svc.Versioning()
+	if repo == nil {
+		writeError(w, versioning.ErrDisabled)
+		return
+	}
+	if err := repo.Enable(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(VersioningStatus{Enabled: true})
+}
+
+func (h *SkillHandler) Versions(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	versions, err := h.svc.SkillVersions(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+func (h *SkillHandler) Diff(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	query := r.URL.Query()
+
+	diff, err := h.svc.DiffSkillVersions(name, query.Get("from"), query.Get("to"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(diff))
+}
+
+func (h *SkillHandler) Revert(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	var req RevertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, CodeInvalidRequest, "Invalid request")
+		return
+	}
+
+	if err := h.svc.RevertSkill(name, req.Version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
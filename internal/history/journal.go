@@ -0,0 +1,389 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wind/skill-router/internal/config"
+)
+
+// Operation types. Each one carries enough data to be undone and redone.
+const (
+	OpEnableSkill   = "enable-skill"
+	OpDisableSkill  = "disable-skill"
+	OpDeleteSkill   = "delete-skill"
+	OpCreateSkill   = "create-skill"
+	OpWriteSkill    = "write-skill"
+	OpRenameSkill   = "rename-skill"
+	OpRevertSkill   = "revert-skill"
+	OpDeletePlugin  = "delete-plugin"
+	OpRestoreSkill  = "restore-skill"
+	OpRestorePlugin = "restore-plugin"
+	OpRestoreFile   = "restore-file"
+	OpCreateFile    = "create-file"
+	OpWriteFile     = "write-file"
+	OpDeleteFile    = "delete-file"
+	OpChmodFile     = "chmod-file"
+	OpOverrides     = "overrides"
+	OpBulk          = "bulk"
+)
+
+// Entry kinds. An undo or redo entry refers to the entry it reverses or
+// reapplies through Ref.
+const (
+	KindDo   = "do"
+	KindUndo = "undo"
+	KindRedo = "redo"
+)
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+type Operation struct {
+	Type     string `json:"type"`
+	Target   string `json:"target,omitempty"` // skill directory or plugin name
+	Path     string `json:"path,omitempty"`   // file inside the skill directory, slash-separated
+	NewName  string `json:"newName,omitempty"`
+	Enabled  bool   `json:"enabled,omitempty"` // for chmod-file, whether the file was made executable
+	TrashID  string `json:"trashId,omitempty"`
+	Version  string `json:"version,omitempty"` // for revert-skill, the commit reverted to
+	Saved    string `json:"saved,omitempty"`   // for revert-skill, the commit holding the state before it
+	Content  []byte `json:"content,omitempty"`
+	Previous []byte `json:"previous,omitempty"`
+	// Contents over blobThreshold are stored in blobs named by their SHA-256
+	ContentBlob  string                  `json:"contentBlob,omitempty"`
+	PreviousBlob string                  `json:"previousBlob,omitempty"`
+	Change       *config.OverridesChange `json:"change,omitempty"`
+	// For bulk, the operations on skill directories, in the order they were
+	// made; Target is the bulk action
+	Steps []Operation `json:"steps,omitempty"`
+}
+
+// Entry is a line of the journal. Seq is assigned when the entry is
+// appended; journals written before it was stored use the line number.
+type Entry struct {
+	Seq  int       `json:"seq"`
+	Time time.Time `json:"time"`
+	Kind string    `json:"kind"`
+	Ref  int       `json:"ref,omitempty"`
+	Op   Operation `json:"op"`
+}
+
+// blobThreshold is the size above which skill contents are stored as blobs
+// next to the journal instead of inline.
+const blobThreshold = 4 << 10
+
+// Journal is an append-only log of operations in a JSON lines file. Every
+// process that uses the same file appends under a file lock.
+type Journal struct {
+	path  string
+	blobs string
+	mu    sync.Mutex
+}
+
+func Open(baseDir string) *Journal {
+	return &Journal{
+		path:  filepath.Join(baseDir, "skill-history.jsonl"),
+		blobs: filepath.Join(baseDir, "skill-history-blobs"),
+	}
+}
+
+// Append writes an entry and returns it with its Seq set.
+func (j *Journal) Append(kind string, ref int, op Operation) (Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	unlock, err := config.LockPath(j.path)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer unlock()
+
+	if op, err = j.storeBlobs(op); err != nil {
+		return Entry{}, err
+	}
+
+	f, err := os.OpenFile(j.path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer f.Close()
+
+	last, complete, err := lastSeq(f)
+	if err != nil {
+		return Entry{}, err
+	}
+	e := Entry{Seq: last + 1, Time: time.Now().UTC(), Kind: kind, Ref: ref, Op: op}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return Entry{}, err
+	}
+	if !complete {
+		// Terminate a line a crash cut short, so it does not swallow this one
+		data = append([]byte("\n"), data...)
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return Entry{}, err
+	}
+	if err := f.Sync(); err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// lastSeq returns the Seq of the last entry in f and whether f ends with a
+// complete line. Only the end of the file is read, unless the journal
+// predates stored sequence numbers.
+func lastSeq(f *os.File) (int, bool, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, false, err
+	}
+	if info.Size() == 0 {
+		return 0, true, nil
+	}
+
+	tail := make([]byte, min(info.Size(), 64<<10))
+	if _, err := f.ReadAt(tail, info.Size()-int64(len(tail))); err != nil {
+		return 0, false, err
+	}
+	complete := tail[len(tail)-1] == '\n'
+
+	lines := bytes.Split(bytes.TrimRight(tail, "\n"), []byte("\n"))
+	if int64(len(tail)) < info.Size() {
+		// The first line may be cut off
+		lines = lines[1:]
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		var e struct {
+			Seq int `json:"seq"`
+		}
+		if json.Unmarshal(lines[i], &e) == nil && e.Seq > 0 {
+			return e.Seq, complete, nil
+		}
+	}
+
+	// No stored Seq nearby: fall back to the line number
+	entries, _, err := readEntries(io.NewSectionReader(f, 0, info.Size()))
+	if err != nil {
+		return 0, false, err
+	}
+	last := 0
+	if len(entries) > 0 {
+		last = entries[len(entries)-1].Seq
+	}
+	return last, complete, nil
+}
+
+// Entries returns every entry, oldest first. Lines that do not parse, such
+// as one cut short by a crash, are skipped and reported on stderr. Large
+// contents are left in blobs; see Load.
+func (j *Journal) Entries() ([]Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// Keep out of other processes' appends so no half-written line is seen
+	unlock, err := config.LockPath(j.path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	f, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries, skipped, err := readEntries(f)
+	for _, line := range skipped {
+		fmt.Fprintf(os.Stderr, "history: skipped unreadable line %d of %s\n", line, j.path)
+	}
+	return entries, err
+}
+
+// readEntries parses a journal and returns its entries along with the line
+// numbers of lines it skipped.
+func readEntries(r io.Reader) ([]Entry, []int, error) {
+	entries := []Entry{}
+	var skipped []int
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			skipped = append(skipped, line)
+			continue
+		}
+		if e.Seq == 0 {
+			e.Seq = line
+		}
+		entries = append(entries, e)
+	}
+	return entries, skipped, scanner.Err()
+}
+
+// storeBlobs moves large contents of op into content-addressed blobs.
+func (j *Journal) storeBlobs(op Operation) (Operation, error) {
+	var err error
+	if len(op.Content) > blobThreshold {
+		if op.ContentBlob, err = j.writeBlob(op.Content); err != nil {
+			return op, err
+		}
+		op.Content = nil
+	}
+	if len(op.Previous) > blobThreshold {
+		if op.PreviousBlob, err = j.writeBlob(op.Previous); err != nil {
+			return op, err
+		}
+		op.Previous = nil
+	}
+	return op, nil
+}
+
+func (j *Journal) writeBlob(data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:])
+	path := filepath.Join(j.blobs, name)
+	if _, err := os.Stat(path); err == nil {
+		return name, nil
+	}
+	return name, config.WriteFileAtomic(path, data, 0644)
+}
+
+// Load returns op with the contents that were stored as blobs read back.
+func (j *Journal) Load(op Operation) (Operation, error) {
+	var err error
+	if op.ContentBlob != "" {
+		if op.Content, err = j.readBlob(op.ContentBlob); err != nil {
+			return op, err
+		}
+		op.ContentBlob = ""
+	}
+	if op.PreviousBlob != "" {
+		if op.Previous, err = j.readBlob(op.PreviousBlob); err != nil {
+			return op, err
+		}
+		op.PreviousBlob = ""
+	}
+	return op, nil
+}
+
+func (j *Journal) readBlob(name string) ([]byte, error) {
+	if len(name) != sha256.Size*2 || strings.Trim(name, "0123456789abcdef") != "" {
+		return nil, fmt.Errorf("invalid blob name %q", name)
+	}
+	return os.ReadFile(filepath.Join(j.blobs, name))
+}
+
+// Stacks replays the journal and returns what can be undone and redone, the
+// next candidate last. A new operation clears the redo stack.
+func Stacks(entries []Entry) (undo, redo []Entry) {
+	for _, e := range entries {
+		switch e.Kind {
+		case KindDo:
+			undo = append(undo, e)
+			redo = nil
+		case KindUndo:
+			undo = removeSeq(undo, e.Ref)
+			redo = append(redo, e)
+		case KindRedo:
+			redo = removeSeq(redo, e.Ref)
+			undo = append(undo, e)
+		}
+	}
+	return undo, redo
+}
+
+func removeSeq(stack []Entry, seq int) []Entry {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].Seq == seq {
+			return append(stack[:i:i], stack[i+1:]...)
+		}
+	}
+	return stack
+}
+
+// String describes op in a short sentence, e.g. for commit messages.
+func (op Operation) String() string {
+	switch op.Type {
+	case OpEnableSkill:
+		return "Enable skill " + op.Target
+	case OpDisableSkill:
+		return "Disable skill " + op.Target
+	case OpDeleteSkill:
+		return "Delete skill " + op.Target
+	case OpCreateSkill:
+		return "Add skill " + op.Target
+	case OpWriteSkill:
+		return "Update skill " + op.Target
+	case OpRenameSkill:
+		return "Rename skill " + op.Target + " to " + op.NewName
+	case OpRevertSkill:
+		return fmt.Sprintf("Revert skill %s to %.7s", op.Target, op.Version)
+	case OpDeletePlugin:
+		return "Delete plugin " + op.Target
+	case OpRestoreSkill:
+		return "Restore skill " + op.Target
+	case OpRestorePlugin:
+		return "Restore plugin " + op.Target
+	case OpRestoreFile:
+		return "Restore file " + op.Target + "/" + op.Path
+	case OpCreateFile:
+		return "Add file " + op.Target + "/" + op.Path
+	case OpWriteFile:
+		return "Update file " + op.Target + "/" + op.Path
+	case OpDeleteFile:
+		return "Delete file " + op.Target + "/" + op.Path
+	case OpChmodFile:
+		if op.Enabled {
+			return "Make " + op.Target + "/" + op.Path + " executable"
+		}
+		return "Make " + op.Target + "/" + op.Path + " non-executable"
+	case OpBulk:
+		return "Bulk " + op.Target
+	case OpOverrides:
+		if op.Change == nil {
+			return "Update overrides"
+		}
+		var parts []string
+		for _, key := range op.Change.AddedDisabled {
+			parts = append(parts, "disable "+key)
+		}
+		for _, key := range op.Change.RemovedDisabled {
+			parts = append(parts, "enable "+key)
+		}
+		for _, name := range op.Change.AddedDisabledPlugins {
+			parts = append(parts, "disable plugin "+name)
+		}
+		for _, name := range op.Change.RemovedDisabledPlugins {
+			parts = append(parts, "enable plugin "+name)
+		}
+		if len(parts) == 0 {
+			return "Update overrides"
+		}
+		s := strings.Join(parts, ", ")
+		return strings.ToUpper(s[:1]) + s[1:]
+	}
+	return op.Type + " " + op.Target
+}
@@ -0,0 +1,114 @@
+package history
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStacks(t *testing.T) {
+	j := Open(t.TempDir())
+
+	a, _ := j.Append(KindDo, 0, Operation{Type: OpDisableSkill, Target: "a"})
+	b, _ := j.Append(KindDo, 0, Operation{Type: OpDisableSkill, Target: "b"})
+	undoB, _ := j.Append(KindUndo, b.Seq, Operation{Type: OpDisableSkill, Target: "b"})
+
+	if a.Seq != 1 || b.Seq != 2 || undoB.Seq != 3 {
+		t.Fatalf("unexpected seqs: %d %d %d", a.Seq, b.Seq, undoB.Seq)
+	}
+
+	entries, err := j.Entries()
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %v, %v", entries, err)
+	}
+	undo, redo := Stacks(entries)
+	if len(undo) != 1 || undo[0].Op.Target != "a" {
+		t.Errorf("expected a to be undoable, got %+v", undo)
+	}
+	if len(redo) != 1 || redo[0].Seq != undoB.Seq {
+		t.Errorf("expected b to be redoable, got %+v", redo)
+	}
+
+	// Redo moves it back, and a new operation clears the redo stack
+	j.Append(KindRedo, undoB.Seq, Operation{Type: OpDisableSkill, Target: "b"})
+	entries, _ = j.Entries()
+	if undo, redo = Stacks(entries); len(undo) != 2 || len(redo) != 0 {
+		t.Errorf("expected 2 undoable and 0 redoable, got %d and %d", len(undo), len(redo))
+	}
+
+	j.Append(KindUndo, a.Seq, Operation{Type: OpDisableSkill, Target: "a"})
+	j.Append(KindDo, 0, Operation{Type: OpEnableSkill, Target: "c"})
+	entries, _ = j.Entries()
+	if undo, redo = Stacks(entries); len(undo) != 2 || len(redo) != 0 {
+		t.Errorf("expected a new operation to clear redo, got %d undoable and %d redoable", len(undo), len(redo))
+	}
+}
+
+func TestJournal_SkipsUnreadableLines(t *testing.T) {
+	dir := t.TempDir()
+	j := Open(dir)
+	a, _ := j.Append(KindDo, 0, Operation{Type: OpDisableSkill, Target: "a"})
+
+	// A crash cut the next entry short
+	f, err := os.OpenFile(filepath.Join(dir, "skill-history.jsonl"), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"seq":2,"kind":"do","op":{"type":"disa`)
+	f.Close()
+
+	b, err := j.Append(KindUndo, a.Seq, Operation{Type: OpDisableSkill, Target: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := j.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[1].Seq != b.Seq || entries[1].Ref != a.Seq {
+		t.Fatalf("expected the cut line skipped and the next entry intact, got %+v", entries)
+	}
+	if undo, redo := Stacks(entries); len(undo) != 0 || len(redo) != 1 {
+		t.Errorf("expected a to be redoable, got %d undoable and %d redoable", len(undo), len(redo))
+	}
+}
+
+func TestJournal_LegacySeq(t *testing.T) {
+	dir := t.TempDir()
+	// Entries written before Seq was stored
+	legacy := `{"seq":0,"kind":"do","op":{"type":"disable-skill","target":"a"}}` + "\n" +
+		`{"seq":0,"kind":"undo","ref":1,"op":{"type":"disable-skill","target":"a"}}` + "\n"
+	os.WriteFile(filepath.Join(dir, "skill-history.jsonl"), []byte(legacy), 0644)
+
+	j := Open(dir)
+	e, err := j.Append(KindRedo, 2, Operation{Type: OpDisableSkill, Target: "a"})
+	if err != nil || e.Seq != 3 {
+		t.Fatalf("expected seq 3, got %d, %v", e.Seq, err)
+	}
+	entries, _ := j.Entries()
+	if len(entries) != 3 || entries[0].Seq != 1 || entries[1].Seq != 2 || entries[2].Seq != 3 {
+		t.Errorf("unexpected seqs: %+v", entries)
+	}
+}
+
+func TestJournal_StoresLargeContentsAsBlobs(t *testing.T) {
+	dir := t.TempDir()
+	j := Open(dir)
+	big := bytes.Repeat([]byte("x"), blobThreshold+1)
+
+	j.Append(KindDo, 0, Operation{Type: OpWriteSkill, Target: "a", Content: big, Previous: []byte("small")})
+	data, _ := os.ReadFile(filepath.Join(dir, "skill-history.jsonl"))
+	if len(data) > blobThreshold {
+		t.Errorf("expected the large content out of line, journal is %d bytes", len(data))
+	}
+
+	entries, _ := j.Entries()
+	op, err := j.Load(entries[0].Op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(op.Content, big) || string(op.Previous) != "small" || op.ContentBlob != "" {
+		t.Errorf("expected the contents back, got %d bytes and %q", len(op.Content), op.Previous)
+	}
+}
@@ -0,0 +1,431 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/github"
+	"github.com/wind/skill-router/internal/service"
+)
+
+// DefaultFile is the manifest file name used by the CLI when none is given.
+const DefaultFile = "skills.json"
+
+// Manifest declares the desired skill setup of a machine: which user skills
+// exist (and where they come from), and which plugin skills and plugins are
+// disabled through skill-overrides.json.
+type Manifest struct {
+	Skills          []SkillSpec `json:"skills"`
+	Disabled        []string    `json:"disabled"`        // "plugin:skill" keys
+	DisabledPlugins []string    `json:"disabledPlugins"` // plugin names
+}
+
+// SkillSpec describes a single user skill. Name is the skill directory name
+// under ~/.claude/skills. Source is a GitHub repository URL the skill can be
+// installed from when it is missing, optionally pinned to Ref.
+type SkillSpec struct {
+	Name    string `json:"name"`
+	Source  string `json:"source,omitempty"`
+	Ref     string `json:"ref,omitempty"`
+	Enabled *bool  `json:"enabled,omitempty"` // defaults to true
+}
+
+func (s SkillSpec) wantEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// Operations a plan can contain.
+const (
+	OpInstall            = "install"
+	OpEnable             = "enable"
+	OpDisable            = "disable"
+	OpDelete             = "delete"
+	OpEnablePluginSkill  = "enable-plugin-skill"
+	OpDisablePluginSkill = "disable-plugin-skill"
+	OpEnablePlugin       = "enable-plugin"
+	OpDisablePlugin      = "disable-plugin"
+)
+
+type Action struct {
+	Op     string `json:"op"`
+	Target string `json:"target"`
+	Source string `json:"source,omitempty"`
+	Ref    string `json:"ref,omitempty"`
+}
+
+func (a Action) String() string {
+	s := a.Op + " " + a.Target
+	if a.Source != "" {
+		s += " from " + a.Source
+		if a.Ref != "" {
+			s += "@" + a.Ref
+		}
+	}
+	return s
+}
+
+type Plan struct {
+	Actions []Action `json:"actions"`
+}
+
+type Result struct {
+	Action Action `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Options struct {
+	// Prune deletes user skills that are installed but not listed in the
+	// manifest. Without it they are left alone.
+	Prune bool
+}
+
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+func Parse(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (m *Manifest) Validate() error {
+	seen := make(map[string]bool)
+	for _, s := range m.Skills {
+		if s.Name == "" || strings.ContainsAny(s.Name, `/\`) || s.Name == "." || s.Name == ".." {
+			return fmt.Errorf("invalid skill name: %q", s.Name)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("duplicate skill: %s", s.Name)
+		}
+		seen[s.Name] = true
+	}
+	for _, key := range m.Disabled {
+		if plugin, skill, ok := strings.Cut(key, ":"); !ok || plugin == "" || skill == "" {
+			return fmt.Errorf("invalid plugin skill key: %q (expected plugin:skill)", key)
+		}
+	}
+	return nil
+}
+
+// Current describes the state reported by ListSkills and LoadOverrides as a
+// manifest, so an existing machine can be used as the starting point.
+func Current(svc *service.SkillService) (*Manifest, error) {
+	skills, err := svc.ListSkills()
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := config.LoadOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		Skills:          []SkillSpec{},
+		Disabled:        append([]string{}, overrides.Disabled...),
+		DisabledPlugins: append([]string{}, overrides.DisabledPlugins...),
+	}
+	for _, s := range skills {
+		if s.Source != "user" {
+			continue
+		}
+		spec := SkillSpec{Name: s.FileName}
+		if !s.Enabled {
+			enabled := false
+			spec.Enabled = &enabled
+		}
+		m.Skills = append(m.Skills, spec)
+	}
+	sort.Slice(m.Skills, func(i, j int) bool { return m.Skills[i].Name < m.Skills[j].Name })
+	sort.Strings(m.Disabled)
+	sort.Strings(m.DisabledPlugins)
+	return m, nil
+}
+
+// Diff computes the actions needed to turn the current state into the one
+// declared by the manifest.
+func Diff(svc *service.SkillService, m *Manifest, opts Options) (*Plan, error) {
+	skills, err := svc.ListSkills()
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := config.LoadOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Actions: []Action{}}
+
+	// User skills
+	installed := make(map[string]bool) // dir name -> enabled
+	for _, s := range skills {
+		if s.Source == "user" {
+			installed[s.FileName] = s.Enabled
+		}
+	}
+
+	declared := make(map[string]bool)
+	for _, spec := range m.Skills {
+		declared[spec.Name] = true
+
+		enabled, ok := installed[spec.Name]
+		if !ok {
+			if spec.Source == "" {
+				return nil, fmt.Errorf("skill %s is not installed and has no source", spec.Name)
+			}
+			plan.add(Action{Op: OpInstall, Target: spec.Name, Source: spec.Source, Ref: spec.Ref})
+			if !spec.wantEnabled() {
+				plan.add(Action{Op: OpDisable, Target: spec.Name})
+			}
+			continue
+		}
+
+		switch {
+		case enabled && !spec.wantEnabled():
+			plan.add(Action{Op: OpDisable, Target: spec.Name})
+		case !enabled && spec.wantEnabled():
+			plan.add(Action{Op: OpEnable, Target: spec.Name})
+		}
+	}
+
+	if opts.Prune {
+		var extra []string
+		for name := range installed {
+			if !declared[name] {
+				extra = append(extra, name)
+			}
+		}
+		sort.Strings(extra)
+		for _, name := range extra {
+			plan.add(Action{Op: OpDelete, Target: name})
+		}
+	}
+
+	// Plugins. Enabling comes first so that individual plugin skill overrides
+	// are applied to an enabled plugin; disabling comes last because it
+	// clears the plugin's individual overrides anyway.
+	wantPlugins := toSet(m.DisabledPlugins)
+	havePlugins := toSet(overrides.DisabledPlugins)
+
+	for _, name := range sortedDiff(havePlugins, wantPlugins) {
+		plan.add(Action{Op: OpEnablePlugin, Target: name})
+	}
+
+	wantSkills := make(map[string]bool)
+	for _, key := range m.Disabled {
+		plugin, _, _ := strings.Cut(key, ":")
+		if !wantPlugins[plugin] {
+			wantSkills[key] = true
+		}
+	}
+	haveSkills := toSet(overrides.Disabled)
+
+	for _, key := range sortedDiff(haveSkills, wantSkills) {
+		plugin, _, _ := strings.Cut(key, ":")
+		if wantPlugins[plugin] && !havePlugins[plugin] {
+			// Disabling the plugin drops this override.
+			continue
+		}
+		plan.add(Action{Op: OpEnablePluginSkill, Target: key})
+	}
+	for _, key := range sortedDiff(wantSkills, haveSkills) {
+		plan.add(Action{Op: OpDisablePluginSkill, Target: key})
+	}
+
+	for _, name := range sortedDiff(wantPlugins, havePlugins) {
+		plan.add(Action{Op: OpDisablePlugin, Target: name})
+	}
+
+	return plan, nil
+}
+
+// Apply executes the plan in order. It keeps going after a failed action so
+// that one unreachable source does not block the rest of the machine from
+// converging; failures are reported per action.
+func Apply(svc *service.SkillService, plan *Plan) []Result {
+	results := make([]Result, 0, len(plan.Actions))
+	for _, a := range plan.Actions {
+		r := Result{Action: a}
+		if err := ApplyAction(svc, a); err != nil {
+			r.Error = err.Error()
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// ApplyTransactional executes the plan and undoes everything it did if any
+// action fails: directory moves are reversed, installed skills are removed
+// again and the override entries the plan changed are changed back. Plans
+// containing deletes are rejected since those cannot be rolled back.
+func ApplyTransactional(svc *service.SkillService, plan *Plan) error {
+	for _, a := range plan.Actions {
+		if a.Op == OpDelete {
+			return fmt.Errorf("cannot delete %s in a transaction", a.Target)
+		}
+	}
+
+	// Neither the actions of a failed plan nor their rollback end up in the
+	// history
+	return svc.Transaction(func() error {
+		changes := make([]config.OverridesChange, 0, len(plan.Actions))
+		for i, a := range plan.Actions {
+			change, err := applyTracked(svc, a)
+			if err != nil {
+				err = fmt.Errorf("%s: %w", a, err)
+				if rollbackErr := rollback(svc, plan.Actions[:i], changes); rollbackErr != nil {
+					return errors.Join(err, fmt.Errorf("rollback: %w", rollbackErr))
+				}
+				return err
+			}
+			changes = append(changes, change)
+		}
+		return nil
+	})
+}
+
+// applyTracked executes a and returns the change it made to the overrides,
+// so that a rollback reverts only that and keeps concurrent changes.
+func applyTracked(svc *service.SkillService, a Action) (config.OverridesChange, error) {
+	switch a.Op {
+	case OpEnablePluginSkill, OpDisablePluginSkill, OpEnablePlugin, OpDisablePlugin:
+	default:
+		return config.OverridesChange{}, ApplyAction(svc, a)
+	}
+
+	before, err := config.LoadOverrides()
+	if err != nil {
+		return config.OverridesChange{}, err
+	}
+	if err := ApplyAction(svc, a); err != nil {
+		return config.OverridesChange{}, err
+	}
+	after, err := config.LoadOverrides()
+	if err != nil {
+		return config.OverridesChange{}, err
+	}
+	return config.DiffOverrides(before, after), nil
+}
+
+// rollback undoes the done actions, latest first. changes[i] is what done[i]
+// changed in the overrides. It keeps going after a failure and returns every
+// error it ran into.
+func rollback(svc *service.SkillService, done []Action, changes []config.OverridesChange) error {
+	var errs []error
+	for i := len(done) - 1; i >= 0; i-- {
+		a := done[i]
+		var err error
+		switch a.Op {
+		case OpInstall:
+			err = svc.DeleteSkill(a.Target, true)
+		case OpEnable:
+			err = svc.DisableSkill(a.Target)
+		case OpDisable:
+			err = svc.EnableSkill(a.Target)
+		default:
+			if !changes[i].Empty() {
+				err = config.ApplyOverridesChange(changes[i].Inverse())
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("undo %s: %w", a, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ApplyAction executes a single plan action.
+func ApplyAction(svc *service.SkillService, a Action) error {
+	switch a.Op {
+	case OpInstall:
+		return install(svc, a.Target, a.Source, a.Ref)
+	case OpEnable:
+		return svc.EnableSkill(a.Target)
+	case OpDisable:
+		return svc.DisableSkill(a.Target)
+	case OpDelete:
+		return svc.DeleteSkill(a.Target, isEnabled(svc, a.Target))
+	case OpEnablePluginSkill:
+		plugin, skill, _ := strings.Cut(a.Target, ":")
+		return config.EnablePluginSkill(plugin, skill)
+	case OpDisablePluginSkill:
+		plugin, skill, _ := strings.Cut(a.Target, ":")
+		return config.DisablePluginSkill(plugin, skill)
+	case OpEnablePlugin:
+		return config.EnablePlugin(a.Target)
+	case OpDisablePlugin:
+		return config.DisablePlugin(a.Target)
+	}
+	return fmt.Errorf("unknown operation: %s", a.Op)
+}
+
+func install(svc *service.SkillService, name, source, ref string) error {
+	files, err := github.FetchSkillFilesAt(source, ref)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if f.Name != name {
+			continue
+		}
+		content, err := github.DownloadFile(f.DownloadURL)
+		if err != nil {
+			return err
+		}
+		return svc.SaveSkill(name, content, false)
+	}
+
+	return fmt.Errorf("skill %s not found in %s", name, source)
+}
+
+func isEnabled(svc *service.SkillService, name string) bool {
+	skills, err := svc.ListSkills()
+	if err != nil {
+		return false
+	}
+	for _, s := range skills {
+		if s.Source == "user" && s.FileName == name {
+			return s.Enabled
+		}
+	}
+	return false
+}
+
+func (p *Plan) add(a Action) {
+	p.Actions = append(p.Actions, a)
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+// sortedDiff returns the members of a that are not in b, sorted.
+func sortedDiff(a, b map[string]bool) []string {
+	var out []string
+	for k := range a {
+		if !b[k] {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,198 @@
+package manifest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/history"
+	"github.com/wind/skill-router/internal/service"
+)
+
+func writeSkill(t *testing.T, dir, name string) {
+	t.Helper()
+	skillDir := filepath.Join(dir, name)
+	os.MkdirAll(skillDir, 0755)
+	content := "---\nname: " + name + "\ndescription: Test\n---\nContent"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiffAndApply(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	writeSkill(t, filepath.Join(tmpDir, "skills"), "keep")
+	writeSkill(t, filepath.Join(tmpDir, "skills"), "turn-off")
+	writeSkill(t, filepath.Join(tmpDir, "skills-disabled"), "turn-on")
+	writeSkill(t, filepath.Join(tmpDir, "skills"), "extra")
+
+	if err := config.DisablePluginSkill("superpowers", "old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.DisablePlugin("noisy"); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Parse([]byte(`{
+		"skills": [
+			{"name": "keep"},
+			{"name": "turn-off", "enabled": false},
+			{"name": "turn-on"}
+		],
+		"disabled": ["superpowers:new"],
+		"disabledPlugins": ["other"]
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	svc := service.NewSkillService(tmpDir)
+	plan, err := Diff(svc, m, Options{Prune: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Action{
+		{Op: OpDisable, Target: "turn-off"},
+		{Op: OpEnable, Target: "turn-on"},
+		{Op: OpDelete, Target: "extra"},
+		{Op: OpEnablePlugin, Target: "noisy"},
+		{Op: OpEnablePluginSkill, Target: "superpowers:old"},
+		{Op: OpDisablePluginSkill, Target: "superpowers:new"},
+		{Op: OpDisablePlugin, Target: "other"},
+	}
+	if len(plan.Actions) != len(want) {
+		t.Fatalf("expected %d actions, got %d: %v", len(want), len(plan.Actions), plan.Actions)
+	}
+	for i := range want {
+		if plan.Actions[i] != want[i] {
+			t.Errorf("action %d: expected %v, got %v", i, want[i], plan.Actions[i])
+		}
+	}
+
+	for _, r := range Apply(svc, plan) {
+		if r.Error != "" {
+			t.Errorf("%s failed: %s", r.Action, r.Error)
+		}
+	}
+
+	plan, err = Diff(svc, m, Options{Prune: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Actions) != 0 {
+		t.Errorf("expected empty plan after apply, got %v", plan.Actions)
+	}
+}
+
+func TestDiff_MissingSkillWithoutSource(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	m := &Manifest{Skills: []SkillSpec{{Name: "missing"}}}
+	if _, err := Diff(service.NewSkillService(tmpDir), m, Options{}); err == nil {
+		t.Fatal("expected error for missing skill without source")
+	}
+}
+
+func TestParse_RejectsInvalidEntries(t *testing.T) {
+	for _, data := range []string{
+		`{"skills": [{"name": "../escape"}]}`,
+		`{"skills": [{"name": "a"}, {"name": "a"}]}`,
+		`{"disabled": ["no-colon"]}`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("expected error for %s", data)
+		}
+	}
+}
+
+func TestApplyTransactional_RollsBackOnFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	writeSkill(t, filepath.Join(tmpDir, "skills-disabled"), "a")
+
+	svc := service.NewSkillService(tmpDir)
+	plan := &Plan{Actions: []Action{
+		{Op: OpEnable, Target: "a"},
+		{Op: OpDisablePlugin, Target: "superpowers"},
+		{Op: OpDisable, Target: "missing"},
+	}}
+
+	if err := ApplyTransactional(svc, plan); err == nil {
+		t.Fatal("expected error from failing action")
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "skills-disabled", "a", "SKILL.md")); err != nil {
+		t.Error("skill a should have been moved back to the disabled dir")
+	}
+	if config.IsPluginDisabled("superpowers") {
+		t.Error("plugin disable should have been rolled back")
+	}
+}
+
+func TestRollback_RevertsOnlyThePlansChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+	svc := service.NewSkillService(tmpDir)
+
+	done := []Action{
+		{Op: OpDisablePlugin, Target: "superpowers"},
+		{Op: OpEnable, Target: "gone"},
+	}
+	changes := make([]config.OverridesChange, len(done))
+	var err error
+	if changes[0], err = applyTracked(svc, done[0]); err != nil {
+		t.Fatal(err)
+	}
+	// Another writer disables a skill after the plan's change
+	if err := config.DisablePluginSkill("other", "lint"); err != nil {
+		t.Fatal(err)
+	}
+
+	err = rollback(svc, done, changes)
+	if !errors.Is(err, service.ErrSkillNotFound) {
+		t.Errorf("expected the failed undo of enabling gone to be reported, got %v", err)
+	}
+	if config.IsPluginDisabled("superpowers") {
+		t.Error("plugin disable should have been rolled back")
+	}
+	if !config.IsPluginSkillDisabled("other", "lint") {
+		t.Error("the other writer's change should have been kept")
+	}
+}
+
+func TestApplyTransactional_History(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+	t.Cleanup(func() { config.SetChangeHook(nil) })
+
+	writeSkill(t, filepath.Join(tmpDir, "skills-disabled"), "a")
+
+	svc := service.NewSkillService(tmpDir)
+	svc.EnableHistory(history.Open(tmpDir))
+
+	failing := &Plan{Actions: []Action{
+		{Op: OpEnable, Target: "a"},
+		{Op: OpDisablePlugin, Target: "superpowers"},
+		{Op: OpDisable, Target: "missing"},
+	}}
+	if err := ApplyTransactional(svc, failing); err == nil {
+		t.Fatal("expected error from failing action")
+	}
+	if entries, _, _, _ := svc.History(); len(entries) != 0 {
+		t.Errorf("expected a rolled back plan to leave no history, got %+v", entries)
+	}
+
+	if err := ApplyTransactional(svc, &Plan{Actions: failing.Actions[:2]}); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _, _, _ := svc.History(); len(entries) != 2 {
+		t.Errorf("expected an entry per action, got %+v", entries)
+	}
+}
@@ -0,0 +1,160 @@
+// Package mcp serves skill management as Model Context Protocol tools over
+// a stream of newline-delimited JSON-RPC 2.0 messages, as used by the stdio
+// transport.
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"slices"
+
+	"github.com/wind/skill-router/internal/profile"
+	"github.com/wind/skill-router/internal/service"
+)
+
+// ProtocolVersions are the protocol revisions the server speaks, newest
+// first.
+var ProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+)
+
+type Server struct {
+	svc   *service.SkillService
+	store *profile.Store
+	tools []tool
+}
+
+func NewServer(svc *service.SkillService, store *profile.Store) *Server {
+	s := &Server{svc: svc, store: store}
+	s.tools = s.toolList()
+	return s
+}
+
+// Request is a JSON-RPC request or, without an ID, a notification.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("json-rpc error %d: %s", e.Code, e.Message)
+}
+
+// Serve reads messages from r and writes responses to w, one JSON value per
+// line, until r is exhausted. Requests are handled in order.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if resp := s.handle(line); resp != nil {
+			if err := enc.Encode(resp); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// handle answers one message, or returns nil for notifications.
+func (s *Server) handle(data []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return errorResponse(json.RawMessage("null"), CodeParseError, "parse error")
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if req.ID == nil {
+			req.ID = json.RawMessage("null")
+		}
+		return errorResponse(req.ID, CodeInvalidRequest, "invalid request")
+	}
+
+	result, err := s.call(req.Method, req.Params)
+	if req.ID == nil {
+		return nil
+	}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInvalidParams, Message: err.Error()}
+		}
+		return &Response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+	}
+	return &Response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *Response {
+	return &Response{JSONRPC: "2.0", ID: id, Error: &Error{Code: code, Message: message}}
+}
+
+func (s *Server) call(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "initialize":
+		return s.initialize(params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.listTools(), nil
+	case "tools/call":
+		return s.callTool(params)
+	case "notifications/initialized", "notifications/cancelled":
+		return struct{}{}, nil
+	default:
+		return nil, &Error{Code: CodeMethodNotFound, Message: "method not found: " + method}
+	}
+}
+
+func (s *Server) initialize(params json.RawMessage) (any, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+	}
+
+	protocol := ProtocolVersions[0]
+	if slices.Contains(ProtocolVersions, p.ProtocolVersion) {
+		protocol = p.ProtocolVersion
+	}
+
+	version := "(devel)"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		version = info.Main.Version
+	}
+
+	return map[string]any{
+		"protocolVersion": protocol,
+		"capabilities":    map[string]any{"tools": map[string]any{}},
+		"serverInfo":      map[string]string{"name": "skill-router", "version": version},
+	}, nil
+}
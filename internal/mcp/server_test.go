@@ -0,0 +1,300 @@
+package mcp
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/profile"
+	"github.com/wind/skill-router/internal/service"
+)
+
+// testClient talks JSON-RPC to a Server running in the same process.
+type testClient struct {
+	t      *testing.T
+	w      io.Writer
+	enc    *json.Encoder
+	dec    *json.Decoder
+	nextID int
+}
+
+func newTestClient(t *testing.T, srv *Server) *testClient {
+	t.Helper()
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(serverR, serverW)
+		serverW.Close()
+	}()
+	t.Cleanup(func() {
+		clientW.Close()
+		if err := <-done; err != nil {
+			t.Errorf("serve: %v", err)
+		}
+	})
+
+	return &testClient{t: t, w: clientW, enc: json.NewEncoder(clientW), dec: json.NewDecoder(clientR)}
+}
+
+func (c *testClient) send(msg any) {
+	c.t.Helper()
+	if err := c.enc.Encode(msg); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *testClient) receive() Response {
+	c.t.Helper()
+	var resp struct {
+		Response
+		Result json.RawMessage `json:"result"`
+	}
+	if err := c.dec.Decode(&resp); err != nil {
+		c.t.Fatal(err)
+	}
+	resp.Response.Result = resp.Result
+	return resp.Response
+}
+
+// call sends a request and decodes the result into result.
+func (c *testClient) call(method string, params, result any) *Error {
+	c.t.Helper()
+	c.nextID++
+	c.send(map[string]any{"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params})
+
+	resp := c.receive()
+	if string(resp.ID) != strconv.Itoa(c.nextID) {
+		c.t.Fatalf("expected id %d, got %s", c.nextID, resp.ID)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result != nil {
+		if err := json.Unmarshal(resp.Result.(json.RawMessage), result); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+	return nil
+}
+
+type callResult struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	IsError bool `json:"isError"`
+}
+
+// tool calls a tool and returns its text and whether it failed.
+func (c *testClient) tool(name string, args any) (string, bool) {
+	c.t.Helper()
+	var result callResult
+	if err := c.call("tools/call", map[string]any{"name": name, "arguments": args}, &result); err != nil {
+		c.t.Fatalf("%s: %v", name, err)
+	}
+	if len(result.Content) != 1 || result.Content[0].Type != "text" {
+		c.t.Fatalf("%s: unexpected content %+v", name, result.Content)
+	}
+	return result.Content[0].Text, result.IsError
+}
+
+func writeSkill(t *testing.T, dir, name string) {
+	t.Helper()
+	skillDir := filepath.Join(dir, name)
+	os.MkdirAll(skillDir, 0755)
+	content := "---\nname: " + name + "\ndescription: Helps with " + name + "\n---\nContent"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func setup(t *testing.T) (string, *testClient) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	writeSkill(t, filepath.Join(tmpDir, "skills"), "notes")
+	writeSkill(t, filepath.Join(tmpDir, "skills-disabled"), "infra")
+	writeSkill(t, filepath.Join(tmpDir, "plugins", "cache", "acme", "tools", "1.0.0", "skills"), "lint")
+
+	svc := service.NewSkillService(tmpDir)
+	return tmpDir, newTestClient(t, NewServer(svc, profile.NewStore(tmpDir)))
+}
+
+func TestServer_Initialize(t *testing.T) {
+	_, c := setup(t)
+
+	var init struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		Capabilities    struct {
+			Tools *struct{} `json:"tools"`
+		} `json:"capabilities"`
+		ServerInfo struct {
+			Name string `json:"name"`
+		} `json:"serverInfo"`
+	}
+	if err := c.call("initialize", map[string]any{"protocolVersion": "2025-03-26", "capabilities": map[string]any{}}, &init); err != nil {
+		t.Fatal(err)
+	}
+	if init.ProtocolVersion != "2025-03-26" || init.Capabilities.Tools == nil || init.ServerInfo.Name != "skill-router" {
+		t.Errorf("unexpected initialize result %+v", init)
+	}
+	c.send(map[string]any{"jsonrpc": "2.0", "method": "notifications/initialized"})
+
+	if err := c.call("initialize", map[string]any{"protocolVersion": "1999-01-01"}, &init); err != nil {
+		t.Fatal(err)
+	}
+	if init.ProtocolVersion != ProtocolVersions[0] {
+		t.Errorf("expected the latest version for an unknown one, got %s", init.ProtocolVersion)
+	}
+
+	var list struct {
+		Tools []struct {
+			Name        string         `json:"name"`
+			InputSchema map[string]any `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	if err := c.call("tools/list", nil, &list); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tool := range list.Tools {
+		names = append(names, tool.Name)
+		if tool.InputSchema["type"] != "object" {
+			t.Errorf("%s: input schema is not an object", tool.Name)
+		}
+	}
+	want := "list_skills search_skills read_skill enable_skill disable_skill install_skills list_profiles activate_profile"
+	if strings.Join(names, " ") != want {
+		t.Errorf("unexpected tools %v", names)
+	}
+
+	if err := c.call("ping", nil, nil); err != nil {
+		t.Errorf("ping: %v", err)
+	}
+}
+
+func TestServer_ProtocolErrors(t *testing.T) {
+	_, c := setup(t)
+
+	io.WriteString(c.w, `{"jsonrpc":"2.0","id":1,`+"\n")
+	if resp := c.receive(); resp.Error == nil || resp.Error.Code != CodeParseError || string(resp.ID) != "null" {
+		t.Errorf("expected a parse error, got %+v", resp)
+	}
+	c.send(map[string]any{"jsonrpc": "1.0", "id": "a", "method": "ping"})
+	if resp := c.receive(); resp.Error == nil || resp.Error.Code != CodeInvalidRequest || string(resp.ID) != `"a"` {
+		t.Errorf("expected an invalid request error, got %+v", resp)
+	}
+
+	if err := c.call("resources/list", nil, nil); err == nil || err.Code != CodeMethodNotFound {
+		t.Errorf("expected method not found, got %v", err)
+	}
+	if err := c.call("tools/call", map[string]any{"name": "format_disk"}, nil); err == nil || err.Code != CodeInvalidParams {
+		t.Errorf("expected invalid params for an unknown tool, got %v", err)
+	}
+	if err := c.call("tools/call", map[string]any{"name": "read_skill", "arguments": map[string]any{"skill": "notes"}}, nil); err == nil || err.Code != CodeInvalidParams {
+		t.Errorf("expected invalid params for an unknown argument, got %v", err)
+	}
+	if err := c.call("tools/call", map[string]any{"name": "enable_skill", "arguments": map[string]any{}}, nil); err == nil || !strings.Contains(err.Message, "name is required") {
+		t.Errorf("expected a missing name error, got %v", err)
+	}
+	if err := c.call("tools/call", map[string]any{"name": "list_skills", "arguments": map[string]any{"project": "relative"}}, nil); err == nil || err.Code != CodeInvalidParams {
+		t.Errorf("expected invalid params for a relative project, got %v", err)
+	}
+}
+
+func TestServer_Tools(t *testing.T) {
+	tmpDir, c := setup(t)
+
+	text, isError := c.tool("list_skills", nil)
+	var skills []struct {
+		Name    string `json:"name"`
+		Enabled bool   `json:"enabled"`
+		Source  string `json:"source"`
+	}
+	if err := json.Unmarshal([]byte(text), &skills); err != nil || isError || len(skills) != 3 {
+		t.Fatalf("expected three skills, got %s", text)
+	}
+
+	text, _ = c.tool("search_skills", map[string]any{"query": "infra", "enabled": false})
+	if !strings.Contains(text, `"fileName": "infra"`) || strings.Contains(text, `"notes"`) {
+		t.Errorf("expected only infra, got %s", text)
+	}
+
+	if text, _ := c.tool("read_skill", map[string]any{"name": "notes"}); !strings.Contains(text, "Helps with notes") {
+		t.Errorf("unexpected SKILL.md %q", text)
+	}
+	if text, _ := c.tool("read_skill", map[string]any{"name": "lint", "plugin": "tools"}); !strings.Contains(text, "name: lint") {
+		t.Errorf("unexpected plugin SKILL.md %q", text)
+	}
+	if text, isError := c.tool("read_skill", map[string]any{"name": "missing"}); !isError || !strings.Contains(text, "not found") {
+		t.Errorf("expected a not-found tool error, got %q", text)
+	}
+
+	if _, isError := c.tool("disable_skill", map[string]any{"name": "notes"}); isError {
+		t.Fatal("disable failed")
+	}
+	if _, isError := c.tool("enable_skill", map[string]any{"name": "infra"}); isError {
+		t.Fatal("enable failed")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "skills", "infra")); err != nil {
+		t.Error("infra should be enabled")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "skills-disabled", "notes")); err != nil {
+		t.Error("notes should be disabled")
+	}
+	if _, isError := c.tool("disable_skill", map[string]any{"name": "lint", "plugin": "tools"}); isError {
+		t.Fatal("disabling the plugin skill failed")
+	}
+	if !config.IsPluginSkillDisabled("tools", "lint") {
+		t.Error("lint should be disabled")
+	}
+	if text, isError := c.tool("disable_skill", map[string]any{"name": "lnit", "plugin": "tools"}); !isError || !strings.Contains(text, "not found") {
+		t.Errorf("expected a not-found tool error for a missing plugin skill, got %q", text)
+	}
+	if config.IsPluginSkillDisabled("tools", "lnit") {
+		t.Error("no override should be written for a missing plugin skill")
+	}
+}
+
+func TestServer_Profiles(t *testing.T) {
+	tmpDir, c := setup(t)
+
+	svc := service.NewSkillService(tmpDir)
+	store := profile.NewStore(tmpDir)
+	p, err := profile.Snapshot(svc, "writing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(p, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if text, _ := c.tool("list_profiles", nil); !strings.Contains(text, `"active": true`) {
+		t.Errorf("expected the active profile, got %s", text)
+	}
+
+	c.tool("enable_skill", map[string]any{"name": "infra"})
+	if text, _ := c.tool("list_profiles", nil); !strings.Contains(text, `"active": false`) {
+		t.Errorf("expected an inactive profile, got %s", text)
+	}
+
+	text, isError := c.tool("activate_profile", map[string]any{"name": "writing"})
+	if isError || !strings.Contains(text, "infra") {
+		t.Errorf("expected infra to be disabled again, got %s", text)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "skills-disabled", "infra")); err != nil {
+		t.Error("infra should be disabled")
+	}
+
+	if text, isError := c.tool("activate_profile", map[string]any{"name": "missing"}); !isError || !strings.Contains(text, "not found") {
+		t.Errorf("expected a not-found tool error, got %q", text)
+	}
+}
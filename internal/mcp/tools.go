@@ -0,0 +1,370 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/manifest"
+	"github.com/wind/skill-router/internal/profile"
+	"github.com/wind/skill-router/internal/service"
+)
+
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+
+	call func(args json.RawMessage) (any, error)
+}
+
+// object returns the JSON schema of an object with the given properties, of
+// which the ones listed in required must be present.
+func object(properties map[string]any, required ...string) map[string]any {
+	schema := map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func str(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
+
+var (
+	projectProp = str("Absolute path of a project directory; its .claude settings and skills are taken into account")
+	nameProp    = str("Skill directory name")
+	pluginProp  = str("Plugin name, for a plugin skill")
+)
+
+func (s *Server) toolList() []tool {
+	return []tool{
+		{
+			Name:        "list_skills",
+			Description: "List user, project and plugin skills with whether each one is enabled.",
+			InputSchema: object(map[string]any{"project": projectProp}),
+			call:        s.listSkills,
+		},
+		{
+			Name:        "search_skills",
+			Description: "Search skill names, descriptions and bodies, best matches first.",
+			InputSchema: object(map[string]any{
+				"query":   str("Search terms; all of them must match"),
+				"source":  map[string]any{"type": "string", "enum": []string{"user", "project", "plugin"}},
+				"plugin":  str("Only skills of this plugin"),
+				"enabled": map[string]any{"type": "boolean", "description": "Only enabled or only disabled skills"},
+				"project": projectProp,
+			}),
+			call: s.searchSkills,
+		},
+		{
+			Name:        "read_skill",
+			Description: "Return the SKILL.md of a user skill, or of a plugin skill when plugin is given.",
+			InputSchema: object(map[string]any{"name": nameProp, "plugin": pluginProp}, "name"),
+			call:        s.readSkill,
+		},
+		{
+			Name:        "enable_skill",
+			Description: "Enable a user skill, or a plugin skill when plugin is given.",
+			InputSchema: object(map[string]any{"name": nameProp, "plugin": pluginProp}, "name"),
+			call:        s.enableSkill,
+		},
+		{
+			Name:        "disable_skill",
+			Description: "Disable a user skill, or a plugin skill when plugin is given.",
+			InputSchema: object(map[string]any{"name": nameProp, "plugin": pluginProp}, "name"),
+			call:        s.disableSkill,
+		},
+		{
+			Name:        "install_skills",
+			Description: "Install the skills found at a GitHub repository or directory URL as user skills.",
+			InputSchema: object(map[string]any{"url": str("GitHub URL of a repository, directory or SKILL.md")}, "url"),
+			call:        s.installSkills,
+		},
+		{
+			Name:        "list_profiles",
+			Description: "List the saved skill profiles and which one is active.",
+			InputSchema: object(map[string]any{}),
+			call:        s.listProfiles,
+		},
+		{
+			Name:        "activate_profile",
+			Description: "Switch to a saved skill profile, enabling and disabling skills to match it.",
+			InputSchema: object(map[string]any{"name": str("Profile name")}, "name"),
+			call:        s.activateProfile,
+		},
+	}
+}
+
+func (s *Server) listTools() any {
+	return map[string]any{"tools": s.tools}
+}
+
+// callTool runs a tool. Unknown tools and malformed arguments are protocol
+// errors; failures of the tool itself are reported in the result so the
+// model can see them.
+func (s *Server) callTool(params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	for _, t := range s.tools {
+		if t.Name != p.Name {
+			continue
+		}
+		result, err := t.call(p.Arguments)
+		if _, ok := err.(*argsError); ok {
+			return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+		}
+		if err != nil {
+			return toolResult(err.Error(), true), nil
+		}
+		if text, ok := result.(string); ok {
+			return toolResult(text, false), nil
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return toolResult(string(data), false), nil
+	}
+	return nil, &Error{Code: CodeInvalidParams, Message: "unknown tool: " + p.Name}
+}
+
+func toolResult(text string, isError bool) map[string]any {
+	return map[string]any{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+type argsError struct {
+	err error
+}
+
+func (e *argsError) Error() string { return "invalid arguments: " + e.err.Error() }
+
+// decodeArgs strictly decodes tool arguments into v.
+func decodeArgs(args json.RawMessage, v any) error {
+	if len(args) == 0 || string(args) == "null" {
+		args = []byte("{}")
+	}
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return &argsError{err}
+	}
+	return nil
+}
+
+func requireArg(name, value string) error {
+	if value == "" {
+		return &argsError{fmt.Errorf("%s is required", name)}
+	}
+	return nil
+}
+
+func checkProject(project string) error {
+	if project == "" {
+		return nil
+	}
+	if !config.IsProject(project) {
+		return &argsError{fmt.Errorf("project %q is not a known project directory", project)}
+	}
+	return nil
+}
+
+func (s *Server) listSkills(args json.RawMessage) (any, error) {
+	var a struct {
+		Project string `json:"project"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	if err := checkProject(a.Project); err != nil {
+		return nil, err
+	}
+
+	skills, err := s.svc.ListProjectSkills(a.Project)
+	if err != nil {
+		return nil, err
+	}
+	s.svc.MarkSkillCollisions(skills)
+	return skills, nil
+}
+
+func (s *Server) searchSkills(args json.RawMessage) (any, error) {
+	var a struct {
+		Query   string `json:"query"`
+		Source  string `json:"source"`
+		Plugin  string `json:"plugin"`
+		Enabled *bool  `json:"enabled"`
+		Project string `json:"project"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	if err := checkProject(a.Project); err != nil {
+		return nil, err
+	}
+
+	return s.svc.Search(service.SearchQuery{
+		Text:    a.Query,
+		Project: a.Project,
+		Source:  a.Source,
+		Plugin:  a.Plugin,
+		Enabled: a.Enabled,
+	})
+}
+
+type skillArgs struct {
+	Name   string `json:"name"`
+	Plugin string `json:"plugin"`
+}
+
+func decodeSkillArgs(args json.RawMessage) (skillArgs, error) {
+	var a skillArgs
+	if err := decodeArgs(args, &a); err != nil {
+		return a, err
+	}
+	return a, requireArg("name", a.Name)
+}
+
+func (s *Server) readSkill(args json.RawMessage) (any, error) {
+	a, err := decodeSkillArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var content []byte
+	if a.Plugin != "" {
+		content, err = s.svc.ReadPluginSkillContent(a.Plugin, a.Name)
+	} else {
+		content, err = s.svc.ReadSkillContent(a.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return string(content), nil
+}
+
+func (s *Server) enableSkill(args json.RawMessage) (any, error) {
+	a, err := decodeSkillArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.Plugin != "" {
+		if err = s.checkPluginSkill(a); err == nil {
+			err = config.EnablePluginSkill(a.Plugin, a.Name)
+		}
+	} else {
+		err = s.svc.EnableSkill(a.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return "Enabled " + skillID(a), nil
+}
+
+func (s *Server) disableSkill(args json.RawMessage) (any, error) {
+	a, err := decodeSkillArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.Plugin != "" {
+		if err = s.checkPluginSkill(a); err == nil {
+			err = config.DisablePluginSkill(a.Plugin, a.Name)
+		}
+	} else {
+		err = s.svc.DisableSkill(a.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return "Disabled " + skillID(a), nil
+}
+
+// checkPluginSkill makes sure a plugin skill is installed before its
+// override is changed, so a typo does not leave a stray entry behind.
+func (s *Server) checkPluginSkill(a skillArgs) error {
+	_, err := s.svc.ReadPluginSkillContent(a.Plugin, a.Name)
+	return err
+}
+
+func skillID(a skillArgs) string {
+	if a.Plugin != "" {
+		return "plugin skill " + a.Plugin + ":" + a.Name
+	}
+	return "skill " + a.Name
+}
+
+func (s *Server) installSkills(args json.RawMessage) (any, error) {
+	var a struct {
+		URL string `json:"url"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArg("url", a.URL); err != nil {
+		return nil, err
+	}
+
+	installed, err := s.svc.InstallFromGitHub(a.URL)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]int{"installed": installed}, nil
+}
+
+type profileInfo struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+func (s *Server) listProfiles(args json.RawMessage) (any, error) {
+	if err := decodeArgs(args, &struct{}{}); err != nil {
+		return nil, err
+	}
+
+	profiles, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]profileInfo, 0, len(profiles))
+	for _, p := range profiles {
+		plan, err := profile.Diff(s.svc, &p)
+		infos = append(infos, profileInfo{Name: p.Name, Active: err == nil && len(plan.Actions) == 0})
+	}
+	return infos, nil
+}
+
+func (s *Server) activateProfile(args json.RawMessage) (any, error) {
+	var a struct {
+		Name string `json:"name"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArg("name", a.Name); err != nil {
+		return nil, err
+	}
+
+	p, err := s.store.Get(a.Name)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := profile.Activate(s.svc, p)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]manifest.Action{"applied": plan.Actions}, nil
+}
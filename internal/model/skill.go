@@ -6,6 +6,12 @@ type Skill struct {
 	FileName    string `json:"fileName"`
 	FilePath    string `json:"filePath"`
 	Enabled     bool   `json:"enabled"`
-	Source      string `json:"source"`     // "user" or "plugin"
+	Source      string `json:"source"`     // "user", "project" or "plugin"
 	PluginName  string `json:"pluginName"` // e.g., "superpowers" (empty for user skills)
+	StateLayer  string `json:"stateLayer"` // "default", "global" or "project": which layer decided Enabled
+	Tokens      int    `json:"tokens"`     // estimated context taken up by name and description
+
+	AllowedTools []string `json:"allowedTools,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Conflicts    []string `json:"conflicts,omitempty"` // IDs of enabled skills this one collides with
 }
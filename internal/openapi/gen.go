@@ -0,0 +1,685 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// streamTypes are response media types the client hands back unread.
+var streamTypes = map[string]bool{
+	"text/event-stream": true,
+	"application/gzip":  true,
+}
+
+// initialisms are words spelled in capitals in Go names.
+var initialisms = map[string]string{
+	"api":  "API",
+	"etag": "ETag",
+	"id":   "ID",
+	"json": "JSON",
+	"url":  "URL",
+}
+
+// GenerateClient renders a client for every operation in doc as Go source
+// of package pkg. It relies on the Client, Error, File and form helpers
+// written by hand in that package.
+func GenerateClient(doc *Document, pkg string) ([]byte, error) {
+	g := &generator{doc: doc, declared: make(map[string]bool)}
+	for _, name := range slices.Sorted(maps.Keys(doc.Components.Schemas)) {
+		if err := g.schemaType(name, doc.Components.Schemas[name]); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+	for _, path := range slices.Sorted(maps.Keys(doc.Paths)) {
+		for _, method := range Methods {
+			op := doc.Paths[path][method]
+			if op == nil {
+				continue
+			}
+			if err := g.operation(strings.ToUpper(method), path, op); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by genclient from internal/openapi/openapi.json. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", pkg)
+	for _, imp := range []string{"context", "encoding/json", "io", "net/http", "net/url", "strconv", "time"} {
+		if strings.Contains(g.buf.String(), imp[strings.LastIndex(imp, "/")+1:]+".") {
+			fmt.Fprintf(&out, "\t%q\n", imp)
+		}
+	}
+	fmt.Fprintf(&out, ")\n")
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format client: %w", err)
+	}
+	return src, nil
+}
+
+type generator struct {
+	doc      *Document
+	buf      bytes.Buffer
+	declared map[string]bool
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) declare(name string) error {
+	if g.declared[name] {
+		return fmt.Errorf("%s is declared twice", name)
+	}
+	g.declared[name] = true
+	return nil
+}
+
+// comment starts a declaration of name, documented by text if there is
+// any.
+func (g *generator) comment(name, text string) {
+	g.printf("\n")
+	if text == "" {
+		return
+	}
+	text = name + " " + lowerFirst(text)
+	for _, line := range wrap(text, 76) {
+		g.printf("// %s\n", line)
+	}
+}
+
+func (g *generator) schemaType(name string, s *Schema) error {
+	if err := g.declare(name); err != nil {
+		return err
+	}
+	switch {
+	case s.Type == "string" && len(s.Enum) > 0:
+		g.comment(name, isDescription(s.Description))
+		g.printf("type %s string\n\nconst (\n", name)
+		for _, v := range s.Enum {
+			g.printf("\t%s%s %s = %q\n", name, goName(v), name, v)
+		}
+		g.printf(")\n")
+	case s.Type == "object":
+		g.comment(name, isDescription(s.Description))
+		g.printf("type %s struct {\n", name)
+		if err := g.fields(s); err != nil {
+			return err
+		}
+		g.printf("}\n")
+	default:
+		return fmt.Errorf("unsupported component type %q", s.Type)
+	}
+	return nil
+}
+
+func (g *generator) fields(s *Schema) error {
+	for _, prop := range s.PropertyOrder {
+		required := slices.Contains(s.Required, prop)
+		typ, err := g.goType(s.Properties[prop], required)
+		if err != nil {
+			return fmt.Errorf("property %s: %w", prop, err)
+		}
+		tag := prop
+		if !required {
+			tag += ",omitempty"
+		}
+		g.printf("\t%s %s `json:%q`\n", goName(prop), typ, tag)
+	}
+	return nil
+}
+
+// goType returns the Go type of values matching s. Optional objects and
+// booleans that default to true become pointers so that absent and zero
+// differ.
+func (g *generator) goType(s *Schema, required bool) (string, error) {
+	if name := RefName(s); name != "" {
+		target := g.doc.Schema(s)
+		if target == nil {
+			return "", fmt.Errorf("unknown schema %s", s.Ref)
+		}
+		if target.Type == "object" && (!required || s.Nullable) {
+			return "*" + name, nil
+		}
+		return name, nil
+	}
+	switch s.Type {
+	case "array":
+		item, err := g.goType(s.Items, true)
+		return "[]" + item, err
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return "time.Time", nil
+		case "byte":
+			return "[]byte", nil
+		case "binary":
+			return "File", nil
+		}
+		return "string", nil
+	case "integer":
+		if s.Format == "int64" {
+			return "int64", nil
+		}
+		return "int", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		if !required && s.Default == true {
+			return "*bool", nil
+		}
+		return "bool", nil
+	case "object":
+		if len(s.Properties) == 0 {
+			return "json.RawMessage", nil
+		}
+	}
+	return "", fmt.Errorf("unsupported schema type %q", s.Type)
+}
+
+// operation writes the method for op along with the types of its optional
+// parameters, multipart form and result.
+func (g *generator) operation(method, path string, op *Operation) error {
+	name := goName(op.OperationID)
+	if name == "" {
+		return fmt.Errorf("missing operationId")
+	}
+	args := []string{"ctx context.Context"}
+	var pathParams, required, optional []*Parameter
+	for _, p := range op.Parameters {
+		switch {
+		case p.In == "path":
+			pathParams = append(pathParams, p)
+		case p.Required:
+			required = append(required, p)
+		default:
+			optional = append(optional, p)
+		}
+	}
+	for _, p := range append(pathParams, required...) {
+		if p.Schema.Type != "string" {
+			return fmt.Errorf("required parameter %s must be a string", p.Name)
+		}
+		args = append(args, lowerFirst(goName(p.Name))+" string")
+	}
+
+	// Request body
+	var bodyType, contentType string
+	if op.RequestBody != nil {
+		if len(op.RequestBody.Content) != 1 {
+			return fmt.Errorf("request body needs exactly one media type")
+		}
+		for contentType = range op.RequestBody.Content {
+		}
+		schema := op.RequestBody.Content[contentType].Schema
+		switch {
+		case IsJSON(contentType):
+			t, err := g.goType(schema, true)
+			if err != nil {
+				return err
+			}
+			bodyType = t
+		case contentType == "multipart/form-data":
+			bodyType = name + "Form"
+			if err := g.formType(bodyType, name, schema); err != nil {
+				return err
+			}
+		default:
+			bodyType = "io.Reader"
+		}
+		args = append(args, "body "+bodyType)
+	}
+
+	if len(optional) > 0 {
+		if err := g.paramsType(name, optional); err != nil {
+			return err
+		}
+		args = append(args, "params *"+name+"Params")
+	}
+
+	res, err := g.result(name, op)
+	if err != nil {
+		return err
+	}
+
+	doc := op.Summary
+	if res.also != 0 {
+		doc += fmt.Sprintf(" A %d response is decoded too and returned along with an *Error.", res.also)
+	}
+	if res.stream {
+		doc += " The caller must close the returned body."
+	}
+	g.comment(name, doc)
+	if res.goType == "" {
+		g.printf("func (c *Client) %s(%s) error {\n", name, strings.Join(args, ", "))
+	} else {
+		g.printf("func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), res.goType)
+	}
+	fail := "return " + res.zero + "err"
+
+	// URL and headers
+	g.printf("\turlPath := %s\n", pathExpr(path, pathParams))
+	query, header := "nil", "nil"
+	hasQuery := slices.ContainsFunc(op.Parameters, func(p *Parameter) bool { return p.In == "query" })
+	hasHeader := slices.ContainsFunc(op.Parameters, func(p *Parameter) bool { return p.In == "header" })
+	if hasQuery {
+		query = "query"
+		g.printf("\tquery := url.Values{}\n")
+	}
+	if hasHeader {
+		header = "header"
+		g.printf("\theader := http.Header{}\n")
+	}
+	for _, p := range required {
+		g.setParam(p, lowerFirst(goName(p.Name)), true)
+	}
+	if len(optional) > 0 {
+		g.printf("\tif params != nil {\n")
+		for _, p := range optional {
+			g.setParam(p, "params."+goName(p.Name), false)
+		}
+		g.printf("\t}\n")
+	}
+
+	reqBody := "nil"
+	if bodyType != "" {
+		reqBody = "reqBody"
+		switch {
+		case IsJSON(contentType):
+			g.printf("\treqBody, err := jsonBody(body)\n\tif err != nil {\n\t\t%s\n\t}\n", fail)
+		case contentType == "multipart/form-data":
+			g.printf("\tform := newForm()\n")
+			schema := op.RequestBody.Content[contentType].Schema
+			for _, prop := range schema.PropertyOrder {
+				field := "body." + goName(prop)
+				switch t, _ := g.goType(schema.Properties[prop], true); t {
+				case "File":
+					g.printf("\tform.file(%q, %s)\n", prop, field)
+				case "[]File":
+					g.printf("\tfor _, f := range %s {\n\t\tform.file(%q, f)\n\t}\n", field, prop)
+				case "bool":
+					g.printf("\tform.field(%q, strconv.FormatBool(%s))\n", prop, field)
+				default:
+					g.printf("\tform.field(%q, %s)\n", prop, field)
+				}
+			}
+			g.printf("\treqBody, contentType, err := form.encode()\n\tif err != nil {\n\t\t%s\n\t}\n", fail)
+		default:
+			g.printf("\treqBody := body\n")
+		}
+	}
+	ct := strconv.Quote(contentType)
+	if contentType == "multipart/form-data" {
+		ct = "contentType"
+	}
+
+	g.printf("\tresp, err := c.do(ctx, %q, urlPath, %s, %s, %s, %s)\n", method, query, header, reqBody, ct)
+	g.printf("\tif err != nil {\n\t\t%s\n\t}\n", fail)
+	if !res.stream {
+		g.printf("\tdefer resp.Body.Close()\n")
+	}
+
+	// Status check
+	var codes []string
+	for _, code := range res.codes {
+		codes = append(codes, strconv.Itoa(code))
+	}
+	if res.also != 0 {
+		codes = append(codes, strconv.Itoa(res.also))
+	}
+	g.printf("\tif %s {\n", statusTest(codes))
+	if res.stream {
+		g.printf("\t\tdefer resp.Body.Close()\n")
+	}
+	g.printf("\t\treturn %sresponseError(resp)\n\t}\n", res.zero)
+
+	// Result
+	switch {
+	case res.goType == "":
+		g.printf("\treturn nil\n")
+	case res.stream:
+		g.printf("\treturn resp.Body, nil\n")
+	case res.wrapper:
+		g.printf("\tout := &%s{", strings.TrimPrefix(res.goType, "*"))
+		if len(res.codes) > 1 {
+			g.printf("StatusCode: resp.StatusCode")
+		}
+		g.printf("}\n")
+		for _, h := range res.headers {
+			g.printf("\tout.%s = resp.Header.Get(%q)\n", goName(h), h)
+		}
+		if res.bodyType != "" {
+			g.printf("\tif resp.StatusCode == %d {\n", res.bodyCode)
+			if res.bodyJSON {
+				g.printf("\t\tif err := decodeJSON(resp, &out.Body); err != nil {\n\t\t\treturn nil, err\n\t\t}\n")
+			} else {
+				g.printf("\t\tif out.Body, err = io.ReadAll(resp.Body); err != nil {\n\t\t\treturn nil, err\n\t\t}\n")
+			}
+			g.printf("\t}\n")
+		}
+		g.printf("\treturn out, nil\n")
+	case res.bodyJSON:
+		g.printf("\tvar out %s\n", strings.TrimPrefix(res.goType, "*"))
+		g.printf("\tif err := decodeJSON(resp, &out); err != nil {\n\t\treturn %serr\n\t}\n", res.zero)
+		ret := "out"
+		if strings.HasPrefix(res.goType, "*") {
+			ret = "&out"
+		}
+		if res.also != 0 {
+			g.printf("\tif resp.StatusCode == %d {\n\t\treturn %s, &Error{StatusCode: resp.StatusCode}\n\t}\n", res.also, ret)
+		}
+		g.printf("\treturn %s, nil\n", ret)
+	default:
+		g.printf("\treturn io.ReadAll(resp.Body)\n")
+	}
+	g.printf("}\n")
+	return nil
+}
+
+type result struct {
+	goType   string // "" when the method only returns an error
+	zero     string // zero value and comma to return before an error
+	codes    []int  // success statuses
+	also     int    // error status whose body decodes like a success
+	stream   bool
+	wrapper  bool // goType is a generated struct with Body and headers
+	headers  []string
+	bodyType string
+	bodyJSON bool
+	bodyCode int
+}
+
+// result works out what the method for op returns and declares the result
+// struct when the success response carries headers or several statuses.
+func (g *generator) result(name string, op *Operation) (*result, error) {
+	res := &result{}
+	var primary *Response
+	for _, code := range slices.Sorted(maps.Keys(op.Responses)) {
+		n, err := strconv.Atoi(code)
+		if err != nil || n >= 400 {
+			continue
+		}
+		r := g.doc.Response(op.Responses[code])
+		res.codes = append(res.codes, n)
+		for h := range r.Headers {
+			if !slices.Contains(res.headers, h) {
+				res.headers = append(res.headers, h)
+			}
+		}
+		if len(r.Content) > 0 && primary == nil {
+			primary, res.bodyCode = r, n
+		}
+	}
+	if len(res.codes) == 0 {
+		return nil, fmt.Errorf("no success response")
+	}
+	slices.Sort(res.headers)
+
+	if primary != nil {
+		if len(primary.Content) != 1 {
+			return nil, fmt.Errorf("success response needs exactly one media type")
+		}
+		for mediaType, mt := range primary.Content {
+			switch {
+			case streamTypes[mediaType]:
+				res.stream = true
+				res.bodyType = "io.ReadCloser"
+			case IsJSON(mediaType):
+				t, err := g.goType(mt.Schema, false)
+				if err != nil {
+					return nil, err
+				}
+				res.bodyType, res.bodyJSON = t, true
+			default:
+				res.bodyType = "[]byte"
+			}
+			// An error status documented with the same body, such as a
+			// failed transaction reporting what it rolled back
+			for code, r := range op.Responses {
+				n, err := strconv.Atoi(code)
+				if err != nil || n < 400 {
+					continue
+				}
+				if other := g.doc.Response(r).Content[mediaType]; other != nil && other.Schema.Ref != "" && other.Schema.Ref == mt.Schema.Ref {
+					res.also = n
+				}
+			}
+		}
+	}
+
+	switch {
+	case len(res.codes) > 1 || len(res.headers) > 0:
+		typeName := name + "Result"
+		if err := g.declare(typeName); err != nil {
+			return nil, err
+		}
+		g.printf("\n// %s is the response of %s.\n", typeName, name)
+		g.printf("type %s struct {\n", typeName)
+		if len(res.codes) > 1 {
+			g.printf("\tStatusCode int\n")
+		}
+		for _, h := range res.headers {
+			g.printf("\t%s string\n", goName(h))
+		}
+		if res.bodyType != "" {
+			g.printf("\tBody %s\n", res.bodyType)
+		}
+		g.printf("}\n")
+		res.goType, res.wrapper = "*"+typeName, true
+	default:
+		res.goType = res.bodyType
+	}
+	if res.goType != "" {
+		res.zero = "nil, "
+	}
+	return res, nil
+}
+
+func (g *generator) paramsType(name string, params []*Parameter) error {
+	typeName := name + "Params"
+	if err := g.declare(typeName); err != nil {
+		return err
+	}
+	g.printf("\n// %s holds the optional parameters of %s.\n", typeName, name)
+	g.printf("type %s struct {\n", typeName)
+	for _, p := range params {
+		typ, err := paramType(p)
+		if err != nil {
+			return err
+		}
+		if p.Description != "" {
+			for _, line := range wrap(p.Description, 72) {
+				g.printf("\t// %s\n", line)
+			}
+		}
+		g.printf("\t%s %s\n", goName(p.Name), typ)
+	}
+	g.printf("}\n")
+	return nil
+}
+
+func paramType(p *Parameter) (string, error) {
+	switch p.Schema.Type {
+	case "string":
+		return "string", nil
+	case "boolean":
+		return "*bool", nil
+	case "integer":
+		return "*int", nil
+	case "array":
+		if p.Schema.Items.Type == "string" {
+			return "[]string", nil
+		}
+	}
+	return "", fmt.Errorf("unsupported parameter type %q for %s", p.Schema.Type, p.Name)
+}
+
+// setParam writes the code that adds the query parameter or header p with
+// the value of expr.
+func (g *generator) setParam(p *Parameter, expr string, required bool) {
+	target := "query"
+	if p.In == "header" {
+		target = "header"
+	}
+	if required {
+		g.printf("\t%s.Set(%q, %s)\n", target, p.Name, expr)
+		return
+	}
+	typ, _ := paramType(p)
+	switch typ {
+	case "string":
+		g.printf("\t\tif %s != \"\" {\n\t\t\t%s.Set(%q, %s)\n\t\t}\n", expr, target, p.Name, expr)
+	case "*bool":
+		g.printf("\t\tif %s != nil {\n\t\t\t%s.Set(%q, strconv.FormatBool(*%s))\n\t\t}\n", expr, target, p.Name, expr)
+	case "*int":
+		g.printf("\t\tif %s != nil {\n\t\t\t%s.Set(%q, strconv.Itoa(*%s))\n\t\t}\n", expr, target, p.Name, expr)
+	case "[]string":
+		g.printf("\t\tfor _, v := range %s {\n\t\t\t%s.Add(%q, v)\n\t\t}\n", expr, target, p.Name)
+	}
+}
+
+func (g *generator) formType(typeName, opName string, s *Schema) error {
+	if err := g.declare(typeName); err != nil {
+		return err
+	}
+	if s.Type != "object" {
+		return fmt.Errorf("multipart body must be an object")
+	}
+	g.printf("\n// %s is the multipart form sent by %s.\n", typeName, opName)
+	g.printf("type %s struct {\n", typeName)
+	for _, prop := range s.PropertyOrder {
+		typ, err := g.goType(s.Properties[prop], true)
+		if err != nil {
+			return fmt.Errorf("form field %s: %w", prop, err)
+		}
+		g.printf("\t%s %s\n", goName(prop), typ)
+	}
+	g.printf("}\n")
+	return nil
+}
+
+// pathExpr returns a Go expression building path with its parameters
+// escaped.
+func pathExpr(path string, params []*Parameter) string {
+	var parts []string
+	rest := path
+	for rest != "" {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			parts = append(parts, strconv.Quote(rest))
+			break
+		}
+		end := strings.Index(rest, "}")
+		if start > 0 {
+			parts = append(parts, strconv.Quote(rest[:start]))
+		}
+		name := rest[start+1 : end]
+		escape := "url.PathEscape"
+		for _, p := range params {
+			if p.Name == name && p.MultiSegment {
+				escape = "escapeSegments"
+			}
+		}
+		parts = append(parts, escape+"("+lowerFirst(goName(name))+")")
+		rest = rest[end+1:]
+	}
+	return strings.Join(parts, " + ")
+}
+
+func statusTest(codes []string) string {
+	var tests []string
+	for _, code := range codes {
+		tests = append(tests, "resp.StatusCode != "+code)
+	}
+	return strings.Join(tests, " && ")
+}
+
+// goName turns an identifier such as listSkills, dryRun or If-None-Match
+// into an exported Go name.
+func goName(s string) string {
+	var b strings.Builder
+	for _, word := range splitWords(s) {
+		if init, ok := initialisms[strings.ToLower(word)]; ok {
+			b.WriteString(init)
+			continue
+		}
+		r := []rune(word)
+		b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+	}
+	return b.String()
+}
+
+func splitWords(s string) []string {
+	var words []string
+	var cur []rune
+	var prev rune
+	for _, r := range s {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(cur) > 0 {
+				words = append(words, string(cur))
+			}
+			cur = nil
+		case unicode.IsUpper(r) && unicode.IsLower(prev) && len(cur) > 0:
+			words = append(words, string(cur))
+			cur = []rune{r}
+		default:
+			cur = append(cur, r)
+		}
+		prev = r
+	}
+	if len(cur) > 0 {
+		words = append(words, string(cur))
+	}
+	return words
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	// Keep initialisms such as ID whole
+	if init, ok := initialisms[strings.ToLower(s)]; ok && init == s {
+		return strings.ToLower(s)
+	}
+	r := []rune(s)
+	return string(unicode.ToLower(r[0])) + string(r[1:])
+}
+
+// isDescription turns a schema description into the rest of a sentence
+// starting with "Name is".
+func isDescription(desc string) string {
+	if desc == "" {
+		return ""
+	}
+	return "is " + lowerFirst(desc)
+}
+
+func wrap(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
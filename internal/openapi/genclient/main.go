@@ -0,0 +1,33 @@
+// Command genclient writes the Go client for the API described by
+// internal/openapi/openapi.json. Run it with go generate in the client
+// package.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wind/skill-router/internal/openapi"
+)
+
+func main() {
+	out := flag.String("o", "client_gen.go", "output file")
+	pkg := flag.String("package", "client", "package name of the generated file")
+	flag.Parse()
+
+	doc, err := openapi.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	src, err := openapi.GenerateClient(doc, *pkg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,183 @@
+// Package openapi holds the OpenAPI 3 document of the HTTP API, a validator
+// for the JSON it describes and the generator of the Go client.
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Spec is the OpenAPI document served at /api/openapi.json.
+//
+//go:embed openapi.json
+var Spec []byte
+
+// Document is the part of an OpenAPI 3.0 document this package uses.
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components struct {
+		Schemas   map[string]*Schema   `json:"schemas"`
+		Responses map[string]*Response `json:"responses"`
+	} `json:"components"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Parameters  []*Parameter         `json:"parameters"`
+	RequestBody *RequestBody         `json:"requestBody"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // "path", "query" or "header"
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+
+	// MultiSegment marks a path parameter that may contain slashes.
+	MultiSegment bool `json:"x-multi-segment"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref         string                `json:"$ref"`
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+type Header struct {
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref         string             `json:"$ref"`
+	Description string             `json:"description"`
+	Type        string             `json:"type"`
+	Format      string             `json:"format"`
+	Nullable    bool               `json:"nullable"`
+	Enum        []string           `json:"enum"`
+	Default     any                `json:"default"`
+	Items       *Schema            `json:"items"`
+	Required    []string           `json:"required"`
+	Properties  map[string]*Schema `json:"properties"`
+
+	// PropertyOrder lists Properties in document order.
+	PropertyOrder []string `json:"-"`
+}
+
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type plain Schema
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+	var raw struct {
+		Properties json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil || raw.Properties == nil {
+		return err
+	}
+	keys, err := objectKeys(raw.Properties)
+	s.PropertyOrder = keys
+	return err
+}
+
+// objectKeys returns the keys of a JSON object in order.
+func objectKeys(data []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+		keys = append(keys, tok.(string))
+	}
+	return keys, nil
+}
+
+// Methods in the order operations are listed.
+var Methods = []string{"get", "put", "post", "patch", "delete"}
+
+// Load parses the embedded document.
+func Load() (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(Spec, &doc); err != nil {
+		return nil, fmt.Errorf("parse openapi.json: %w", err)
+	}
+	return &doc, nil
+}
+
+// Operation returns the operation for method and a path template such as
+// /api/skills/{name}, or nil.
+func (d *Document) Operation(method, path string) *Operation {
+	return d.Paths[path][strings.ToLower(method)]
+}
+
+// Schema follows s to the component it refers to, if any.
+func (d *Document) Schema(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// Response follows r to the component it refers to, if any.
+func (d *Document) Response(r *Response) *Response {
+	for r != nil && r.Ref != "" {
+		r = d.Components.Responses[strings.TrimPrefix(r.Ref, "#/components/responses/")]
+	}
+	return r
+}
+
+// RefName returns the component name s refers to, or "".
+func RefName(s *Schema) string {
+	if s == nil {
+		return ""
+	}
+	return strings.TrimPrefix(s.Ref, "#/components/schemas/")
+}
+
+// MatchMediaType returns the declared media type matching contentType, which
+// may carry parameters. Ranges such as */* in the document match any type.
+func MatchMediaType(content map[string]*MediaType, contentType string) (string, *MediaType) {
+	base, _, _ := strings.Cut(contentType, ";")
+	base = strings.ToLower(strings.TrimSpace(base))
+	if mt, ok := content[base]; ok {
+		return base, mt
+	}
+	major, _, _ := strings.Cut(base, "/")
+	for _, key := range []string{major + "/*", "*/*"} {
+		if mt, ok := content[key]; ok {
+			return key, mt
+		}
+	}
+	return "", nil
+}
+
+// IsJSON reports whether mediaType is JSON or a JSON suffix type.
+func IsJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
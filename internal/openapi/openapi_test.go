@@ -0,0 +1,135 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoad_RefsResolve(t *testing.T) {
+	doc, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.0.3" {
+		t.Errorf("unexpected version %q", doc.OpenAPI)
+	}
+
+	var check func(where string, s *Schema)
+	check = func(where string, s *Schema) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" {
+			if doc.Schema(s) == nil {
+				t.Errorf("%s: unresolved %s", where, s.Ref)
+			}
+			return
+		}
+		check(where+"[]", s.Items)
+		for name, p := range s.Properties {
+			check(where+"."+name, p)
+		}
+		if len(s.PropertyOrder) != len(s.Properties) {
+			t.Errorf("%s: property order %v is incomplete", where, s.PropertyOrder)
+		}
+	}
+	for name, s := range doc.Components.Schemas {
+		check(name, s)
+	}
+	for path, ops := range doc.Paths {
+		for method, op := range ops {
+			where := method + " " + path
+			for _, p := range op.Parameters {
+				if p.In == "path" && !strings.Contains(path, "{"+p.Name+"}") {
+					t.Errorf("%s: path parameter %s is not in the path", where, p.Name)
+				}
+				check(where+" "+p.Name, p.Schema)
+			}
+			if op.RequestBody != nil {
+				for mediaType, mt := range op.RequestBody.Content {
+					check(where+" "+mediaType, mt.Schema)
+				}
+			}
+			for code, r := range op.Responses {
+				resolved := doc.Response(r)
+				if resolved == nil {
+					t.Errorf("%s %s: unresolved %s", where, code, r.Ref)
+					continue
+				}
+				for mediaType, mt := range resolved.Content {
+					check(where+" "+code+" "+mediaType, mt.Schema)
+				}
+			}
+		}
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	doc, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := func(name string) *Schema { return &Schema{Ref: "#/components/schemas/" + name} }
+
+	cases := []struct {
+		schema *Schema
+		data   string
+		err    string
+	}{
+		{ref("Problem"), `{"title":"Not Found","status":404,"code":"not-found"}`, ""},
+		{ref("Problem"), `{"title":"Not Found","status":404,"code":"gone"}`, `"gone" is not one of`},
+		{ref("Problem"), `{"title":"Not Found","status":404}`, `missing required property "code"`},
+		{ref("Problem"), `{"title":"x","status":4.5,"code":"conflict"}`, "expected integer"},
+		{ref("Problem"), `{"title":"x","status":409,"code":"conflict","extra":1}`, `unknown property "extra"`},
+		{ref("SearchResult"), `{"skill":null,"score":1,"snippet":null}`, "$.skill: null is not allowed"},
+		{&Schema{Type: "array", Items: ref("SkillVersion")}, `[{"hash":"a","time":"2024-01-02T03:04:05Z","message":"m"}]`, ""},
+		{&Schema{Type: "array", Items: ref("SkillVersion")}, `[{"hash":"a","time":"yesterday","message":"m"}]`, "$[0].time"},
+		{ref("Operation"), `{"type":"save","content":"not base64!"}`, "$.content"},
+		{&Schema{Type: "object"}, `{"anything":[1,2]}`, ""},
+		{ref("Plan"), `{"actions":`, "invalid JSON"},
+	}
+	for _, c := range cases {
+		err := doc.ValidateJSON(c.schema, []byte(c.data))
+		switch {
+		case c.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", c.data, err)
+		case c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)):
+			t.Errorf("%s: expected error containing %q, got %v", c.data, c.err, err)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	cases := map[string]string{
+		"listSkills":    "ListSkills",
+		"getOpenAPI":    "GetOpenAPI",
+		"trashId":       "TrashID",
+		"id":            "ID",
+		"url":           "URL",
+		"If-None-Match": "IfNoneMatch",
+		"ETag":          "ETag",
+		"not-found":     "NotFound",
+		"dryRun":        "DryRun",
+	}
+	for in, want := range cases {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := lowerFirst(goName("id")); got != "id" {
+		t.Errorf("expected id, got %q", got)
+	}
+}
+
+func TestMatchMediaType(t *testing.T) {
+	content := map[string]*MediaType{"text/markdown": {}, "image/*": {}}
+	for contentType, want := range map[string]string{
+		"text/markdown; charset=utf-8": "text/markdown",
+		"image/png":                    "image/*",
+		"application/json":             "",
+	} {
+		if got, _ := MatchMediaType(content, contentType); got != want {
+			t.Errorf("%s: expected %q, got %q", contentType, want, got)
+		}
+	}
+}
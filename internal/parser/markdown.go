@@ -1,16 +1,21 @@
 package parser
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 )
 
 type Frontmatter struct {
-	Name        string
-	Description string
+	Name         string
+	Description  string
+	AllowedTools []string
+	Tags         []string
 }
 
-var frontmatterRegex = regexp.MustCompile(`(?s)^---\n(.+?)\n---`)
+// frontmatterRegex matches the frontmatter block, with LF or CRLF line
+// endings.
+var frontmatterRegex = regexp.MustCompile(`(?s)^---\r?\n(.+?)\r?\n---`)
 
 func ParseFrontmatter(content string) (Frontmatter, error) {
 	var fm Frontmatter
@@ -20,8 +25,18 @@ func ParseFrontmatter(content string) (Frontmatter, error) {
 		return fm, nil
 	}
 
+	// listKey is set while reading the "- item" lines of a block list
+	var listKey string
+
 	lines := strings.Split(matches[1], "\n")
 	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if listKey != "" && strings.HasPrefix(trimmed, "- ") {
+			fm.setList(listKey, append(fm.list(listKey), unquote(strings.TrimPrefix(trimmed, "- "))))
+			continue
+		}
+		listKey = ""
+
 		parts := strings.SplitN(line, ":", 2)
 		if len(parts) != 2 {
 			continue
@@ -34,8 +49,112 @@ func ParseFrontmatter(content string) (Frontmatter, error) {
 			fm.Name = value
 		case "description":
 			fm.Description = value
+		case "allowed-tools", "tags":
+			if value == "" {
+				listKey = key
+				continue
+			}
+			fm.setList(key, parseList(value))
 		}
 	}
 
 	return fm, nil
 }
+
+// Body returns the markdown after the frontmatter block.
+func Body(content string) string {
+	loc := frontmatterRegex.FindStringIndex(content)
+	if loc == nil {
+		return content
+	}
+	return strings.TrimLeft(content[loc[1]:], "\r\n")
+}
+
+func (fm *Frontmatter) list(key string) []string {
+	if key == "tags" {
+		return fm.Tags
+	}
+	return fm.AllowedTools
+}
+
+func (fm *Frontmatter) setList(key string, items []string) {
+	if key == "tags" {
+		fm.Tags = items
+	} else {
+		fm.AllowedTools = items
+	}
+}
+
+// parseList reads an inline list, either "a, b" or "[a, b]".
+func parseList(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = unquote(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// ValidateFrontmatter checks that content starts with a frontmatter block
+// holding the name and description Claude Code needs to load the skill.
+func ValidateFrontmatter(content string) (Frontmatter, error) {
+	if !frontmatterRegex.MatchString(content) {
+		return Frontmatter{}, fmt.Errorf("missing frontmatter block")
+	}
+
+	fm, err := ParseFrontmatter(content)
+	if err != nil {
+		return fm, err
+	}
+	if fm.Name == "" {
+		return fm, fmt.Errorf("frontmatter is missing name")
+	}
+	if fm.Description == "" {
+		return fm, fmt.Errorf("frontmatter is missing description")
+	}
+	return fm, nil
+}
+
+// SetFrontmatterField sets key to value in the frontmatter of content,
+// replacing an existing line for key or adding one. Content without
+// frontmatter gets a new block. Everything else, including the file's line
+// endings, is left untouched.
+func SetFrontmatterField(content, key, value string) string {
+	eol := "\n"
+	loc := frontmatterRegex.FindStringSubmatchIndex(content)
+	if loc == nil {
+		if strings.Contains(content, "\r\n") {
+			eol = "\r\n"
+		}
+		return "---" + eol + key + ": " + value + eol + "---" + eol + content
+	}
+	if strings.HasPrefix(content, "---\r\n") {
+		eol = "\r\n"
+	}
+
+	block := content[loc[2]:loc[3]]
+	lines := strings.Split(block, eol)
+	replaced := false
+	for i, line := range lines {
+		if k, _, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(k) == key && !strings.HasPrefix(line, " ") {
+			lines[i] = key + ": " + value
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		lines = append([]string{key + ": " + value}, lines...)
+	}
+
+	return content[:loc[2]] + strings.Join(lines, eol) + content[loc[3]:]
+}
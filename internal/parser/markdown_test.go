@@ -34,3 +34,85 @@ func TestParseFrontmatter_NoFrontmatter(t *testing.T) {
 		t.Errorf("expected empty name, got '%s'", fm.Name)
 	}
 }
+
+func TestParseFrontmatter_Lists(t *testing.T) {
+	content := `---
+name: test-skill
+allowed-tools: Read, Grep, "Bash(git:*)"
+tags:
+  - review
+  - 'go'
+---
+Body`
+	fm, err := ParseFrontmatter(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fm.AllowedTools) != 3 || fm.AllowedTools[2] != "Bash(git:*)" {
+		t.Errorf("unexpected allowed-tools: %q", fm.AllowedTools)
+	}
+	if len(fm.Tags) != 2 || fm.Tags[0] != "review" || fm.Tags[1] != "go" {
+		t.Errorf("unexpected tags: %q", fm.Tags)
+	}
+
+	fm, _ = ParseFrontmatter("---\ntags: [a, b]\n---\n")
+	if len(fm.Tags) != 2 || fm.Tags[1] != "b" {
+		t.Errorf("unexpected inline tags: %q", fm.Tags)
+	}
+}
+
+func TestBody(t *testing.T) {
+	if got := Body("---\nname: x\n---\n\n# Title\n"); got != "# Title\n" {
+		t.Errorf("unexpected body: %q", got)
+	}
+	if got := Body("# No frontmatter"); got != "# No frontmatter" {
+		t.Errorf("unexpected body: %q", got)
+	}
+	if got := Body("---\r\nname: x\r\n---\r\n\r\n# Title\r\n"); got != "# Title\r\n" {
+		t.Errorf("unexpected CRLF body: %q", got)
+	}
+}
+
+func TestParseFrontmatter_CRLF(t *testing.T) {
+	content := "---\r\nname: notes\r\ndescription: Take notes\r\ntags:\r\n  - writing\r\n---\r\nBody\r\n"
+	fm, err := ValidateFrontmatter(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fm.Name != "notes" || fm.Description != "Take notes" || len(fm.Tags) != 1 || fm.Tags[0] != "writing" {
+		t.Errorf("unexpected frontmatter: %+v", fm)
+	}
+}
+
+func TestSetFrontmatterField(t *testing.T) {
+	content := "---\nname: old\ndescription: Keep: this\n---\n\nname: body stays\n"
+	got := SetFrontmatterField(content, "name", "new")
+	want := "---\nname: new\ndescription: Keep: this\n---\n\nname: body stays\n"
+	if got != want {
+		t.Errorf("unexpected result:\n%s", got)
+	}
+
+	got = SetFrontmatterField("---\ndescription: x\n---\n", "name", "added")
+	if fm, _ := ParseFrontmatter(got); fm.Name != "added" || fm.Description != "x" {
+		t.Errorf("expected name to be added, got %q", got)
+	}
+
+	got = SetFrontmatterField("# No frontmatter\n", "name", "fresh")
+	if fm, _ := ParseFrontmatter(got); fm.Name != "fresh" {
+		t.Errorf("expected frontmatter to be created, got %q", got)
+	}
+
+	// CRLF files keep their line endings
+	got = SetFrontmatterField("---\r\nname: old\r\ndescription: x\r\n---\r\nBody\r\n", "name", "new")
+	if want := "---\r\nname: new\r\ndescription: x\r\n---\r\nBody\r\n"; got != want {
+		t.Errorf("unexpected CRLF result: %q", got)
+	}
+	got = SetFrontmatterField("---\r\ndescription: x\r\n---\r\n", "name", "added")
+	if want := "---\r\nname: added\r\ndescription: x\r\n---\r\n"; got != want {
+		t.Errorf("unexpected CRLF result: %q", got)
+	}
+	got = SetFrontmatterField("# No frontmatter\r\n", "name", "fresh")
+	if want := "---\r\nname: fresh\r\n---\r\n# No frontmatter\r\n"; got != want {
+		t.Errorf("unexpected CRLF result: %q", got)
+	}
+}
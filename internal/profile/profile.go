@@ -0,0 +1,199 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wind/skill-router/internal/manifest"
+	"github.com/wind/skill-router/internal/service"
+)
+
+// Profile is a named snapshot of which user skills are enabled and which
+// plugin skills and plugins are disabled.
+type Profile struct {
+	Name            string    `json:"name"`
+	CreatedAt       time.Time `json:"createdAt"`
+	EnabledSkills   []string  `json:"enabledSkills"`
+	DisabledSkills  []string  `json:"disabledSkills"`
+	Disabled        []string  `json:"disabled"`        // "plugin:skill" keys
+	DisabledPlugins []string  `json:"disabledPlugins"` // plugin names
+}
+
+var (
+	ErrProfileNotFound = fmt.Errorf("profile %w", service.ErrNotFound)
+	ErrProfileExists   = fmt.Errorf("profile %w", service.ErrAlreadyExists)
+)
+
+type Store struct {
+	dir string
+}
+
+func NewStore(baseDir string) *Store {
+	return &Store{dir: filepath.Join(baseDir, "skill-profiles")}
+}
+
+func ValidName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\:`)
+}
+
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+func (s *Store) List() ([]Profile, error) {
+	profiles := []Profile{}
+
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		p, err := s.Get(name)
+		if err != nil {
+			continue
+		}
+		profiles = append(profiles, *p)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+func (s *Store) Get(name string) (*Profile, error) {
+	if !ValidName(name) {
+		return nil, fmt.Errorf("%w: profile %q", service.ErrInvalidName, name)
+	}
+
+	data, err := os.ReadFile(s.path(name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (s *Store) Save(p *Profile, overwrite bool) error {
+	if !ValidName(p.Name) {
+		return fmt.Errorf("%w: profile %q", service.ErrInvalidName, p.Name)
+	}
+
+	if !overwrite {
+		if _, err := os.Stat(s.path(p.Name)); err == nil {
+			return fmt.Errorf("%w: %s", ErrProfileExists, p.Name)
+		}
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(p.Name), data, 0644)
+}
+
+func (s *Store) Delete(name string) error {
+	if !ValidName(name) {
+		return fmt.Errorf("%w: profile %q", service.ErrInvalidName, name)
+	}
+	err := os.Remove(s.path(name))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	return err
+}
+
+// Snapshot captures the current state as a profile with the given name.
+func Snapshot(svc *service.SkillService, name string) (*Profile, error) {
+	m, err := manifest.Current(svc)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Profile{
+		Name:            name,
+		CreatedAt:       time.Now().UTC(),
+		EnabledSkills:   []string{},
+		DisabledSkills:  []string{},
+		Disabled:        m.Disabled,
+		DisabledPlugins: m.DisabledPlugins,
+	}
+	for _, spec := range m.Skills {
+		if spec.Enabled != nil && !*spec.Enabled {
+			p.DisabledSkills = append(p.DisabledSkills, spec.Name)
+		} else {
+			p.EnabledSkills = append(p.EnabledSkills, spec.Name)
+		}
+	}
+	return p, nil
+}
+
+// Diff returns the changes activating the profile would make. User skills
+// the profile mentions but that are no longer installed are skipped, and
+// skills installed after the profile was created are left as they are.
+func Diff(svc *service.SkillService, p *Profile) (*manifest.Plan, error) {
+	skills, err := svc.ListSkills()
+	if err != nil {
+		return nil, err
+	}
+
+	installed := make(map[string]bool)
+	for _, s := range skills {
+		if s.Source == "user" {
+			installed[s.FileName] = true
+		}
+	}
+
+	m := &manifest.Manifest{
+		Disabled:        p.Disabled,
+		DisabledPlugins: p.DisabledPlugins,
+	}
+	for _, name := range p.EnabledSkills {
+		if installed[name] {
+			m.Skills = append(m.Skills, manifest.SkillSpec{Name: name})
+		}
+	}
+	for _, name := range p.DisabledSkills {
+		if installed[name] {
+			enabled := false
+			m.Skills = append(m.Skills, manifest.SkillSpec{Name: name, Enabled: &enabled})
+		}
+	}
+
+	return manifest.Diff(svc, m, manifest.Options{})
+}
+
+// Activate switches to the profile. Either all of its changes are applied or,
+// on failure, none of them are.
+func Activate(svc *service.SkillService, p *Profile) (*manifest.Plan, error) {
+	plan, err := Diff(svc, p)
+	if err != nil {
+		return nil, err
+	}
+	if err := manifest.ApplyTransactional(svc, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
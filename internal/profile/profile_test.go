@@ -0,0 +1,97 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/service"
+)
+
+func writeSkill(t *testing.T, dir, name string) {
+	t.Helper()
+	skillDir := filepath.Join(dir, name)
+	os.MkdirAll(skillDir, 0755)
+	content := "---\nname: " + name + "\ndescription: Test\n---\nContent"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSnapshotAndActivate(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	writeSkill(t, filepath.Join(tmpDir, "skills"), "frontend")
+	writeSkill(t, filepath.Join(tmpDir, "skills-disabled"), "infra")
+
+	svc := service.NewSkillService(tmpDir)
+	store := NewStore(tmpDir)
+
+	p, err := Snapshot(svc, "frontend-work")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Save(p, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Save(p, false); err == nil {
+		t.Error("expected error saving an existing profile without overwrite")
+	}
+
+	// Switch to a different setup by hand.
+	if err := svc.DisableSkill("frontend"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.EnableSkill("infra"); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.DisablePlugin("superpowers"); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := store.Get("frontend-work")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	plan, err := Diff(svc, saved)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Actions) != 3 {
+		t.Fatalf("expected 3 actions, got %v", plan.Actions)
+	}
+
+	if _, err := Activate(svc, saved); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "skills", "frontend")); err != nil {
+		t.Error("frontend should be enabled again")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "skills-disabled", "infra")); err != nil {
+		t.Error("infra should be disabled again")
+	}
+	if config.IsPluginDisabled("superpowers") {
+		t.Error("superpowers should be enabled again")
+	}
+
+	profiles, err := store.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(profiles) != 1 || profiles[0].Name != "frontend-work" {
+		t.Errorf("unexpected profiles: %v", profiles)
+	}
+}
+
+func TestStore_RejectsInvalidNames(t *testing.T) {
+	store := NewStore(t.TempDir())
+	for _, name := range []string{"", "..", "a/b", `a\b`} {
+		if err := store.Save(&Profile{Name: name}, true); err == nil {
+			t.Errorf("expected error for name %q", name)
+		}
+	}
+}
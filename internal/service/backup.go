@@ -0,0 +1,624 @@
+package service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/history"
+)
+
+// Restore modes. Merge adds what is missing and keeps everything local;
+// replace makes the user skills and overrides match the backup exactly.
+const (
+	RestoreMerge   = "merge"
+	RestoreReplace = "replace"
+)
+
+const (
+	backupFormatVersion = 1
+	backupManifestFile  = "backup.json"
+	backupOverridesFile = "skill-overrides.json"
+	backupSkillsDir     = "skills" // skills/<name>/..., enabled or not
+
+	// maxBackupSize caps the uncompressed size of an archive being restored
+	maxBackupSize = 256 << 20
+)
+
+var ErrInvalidBackup error = &kindError{kind: ErrInvalidRequest, err: errors.New("invalid backup")}
+
+// BackupManifest is stored as backup.json at the root of a backup archive.
+type BackupManifest struct {
+	Version   int           `json:"version"`
+	CreatedAt time.Time     `json:"createdAt"`
+	Skills    []BackupSkill `json:"skills"`
+	Plugins   []PluginInfo  `json:"plugins"`
+}
+
+type BackupSkill struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// PluginInfo identifies an installed plugin and the version in use.
+type PluginInfo struct {
+	Org     string `json:"org"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type RestoreOptions struct {
+	Mode   string
+	DryRun bool
+}
+
+// RestoreReport describes what a restore did, or would do on a dry run.
+// Skill actions are "add", "replace", "keep" or "remove".
+type RestoreReport struct {
+	Mode           string                 `json:"mode"`
+	DryRun         bool                   `json:"dryRun"`
+	Skills         []RestoreSkill         `json:"skills"`
+	Overrides      config.OverridesChange `json:"overrides"`
+	MissingPlugins []PluginInfo           `json:"missingPlugins"`
+	Warnings       []string               `json:"warnings"`
+}
+
+type RestoreSkill struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Action  string `json:"action"`
+}
+
+// InstalledPlugins lists the installed plugins with the version that is
+// used, the last one in sorted order, like scanPlugins.
+func (s *SkillService) InstalledPlugins() ([]PluginInfo, error) {
+	plugins := []PluginInfo{}
+
+	orgs, err := os.ReadDir(s.pluginsDir)
+	if os.IsNotExist(err) {
+		return plugins, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, org := range orgs {
+		if !org.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(s.pluginsDir, org.Name()))
+		if err != nil {
+			continue
+		}
+		for _, plugin := range entries {
+			if !plugin.IsDir() {
+				continue
+			}
+			versions, err := os.ReadDir(filepath.Join(s.pluginsDir, org.Name(), plugin.Name()))
+			if err != nil {
+				continue
+			}
+			var latest string
+			for _, v := range versions {
+				if v.IsDir() {
+					latest = v.Name()
+				}
+			}
+			if latest != "" {
+				plugins = append(plugins, PluginInfo{Org: org.Name(), Name: plugin.Name(), Version: latest})
+			}
+		}
+	}
+	return plugins, nil
+}
+
+// ExportBackup writes a gzipped tar archive of all user skills, enabled and
+// disabled, with their supporting files, the global overrides and a list of
+// the installed plugins.
+func (s *SkillService) ExportBackup(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	overrides, err := config.LoadOverrides()
+	if err != nil {
+		return err
+	}
+	plugins, err := s.InstalledPlugins()
+	if err != nil {
+		return err
+	}
+
+	m := BackupManifest{Version: backupFormatVersion, CreatedAt: time.Now().UTC(), Skills: []BackupSkill{}, Plugins: plugins}
+	local, err := s.localSkillDirs()
+	if err != nil {
+		return err
+	}
+	for _, name := range sortedKeys(local) {
+		m.Skills = append(m.Skills, BackupSkill{Name: name, Enabled: filepath.Dir(local[name]) == s.enabledDir})
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := writeTarJSON(tw, backupManifestFile, m); err != nil {
+		return err
+	}
+	if err := writeTarJSON(tw, backupOverridesFile, overrides); err != nil {
+		return err
+	}
+	for _, name := range sortedKeys(local) {
+		if err := writeTarDir(tw, local[name], backupSkillsDir+"/"+name); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// backupArchive is a backup read into memory.
+type backupArchive struct {
+	manifest  BackupManifest
+	overrides *config.SkillOverrides
+	files     map[string][]backupFile // by skill name
+	warnings  []string
+}
+
+type backupFile struct {
+	path     string // relative to the skill directory, slash separated
+	mode     fs.FileMode
+	dir      bool
+	linkname string
+	data     []byte
+}
+
+// RestoreBackup restores an archive made by ExportBackup. Skills that are
+// removed or replaced go to the trash, and every change is recorded in the
+// history, so a restore can be undone.
+func (s *SkillService) RestoreBackup(r io.Reader, opts RestoreOptions) (*RestoreReport, error) {
+	if opts.Mode == "" {
+		opts.Mode = RestoreMerge
+	}
+	if opts.Mode != RestoreMerge && opts.Mode != RestoreReplace {
+		return nil, fmt.Errorf("%w: unknown restore mode %q", ErrInvalidRequest, opts.Mode)
+	}
+
+	archive, err := readBackup(r)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	report, err := s.planRestore(archive, opts)
+	if err != nil || opts.DryRun {
+		s.mu.Unlock()
+		return report, err
+	}
+	ops, err := s.applyRestore(archive, report)
+	s.mu.Unlock()
+
+	for _, op := range ops {
+		s.record(op)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The overrides may have changed since the plan, so the change is
+	// worked out again against what is on disk
+	err = config.UpdateOverrides(func(o *config.SkillOverrides) error {
+		before := &config.SkillOverrides{Disabled: o.Disabled, DisabledPlugins: o.DisabledPlugins}
+		o.Disabled, o.DisabledPlugins = restoredOverrides(before, archive.overrides, opts.Mode)
+		report.Overrides = config.DiffOverrides(before, o)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// restoredOverrides returns the disabled skills and plugins after restoring
+// backup over current: the backup's own in replace mode, both combined in
+// merge mode.
+func restoredOverrides(current, backup *config.SkillOverrides, mode string) (disabled, disabledPlugins []string) {
+	if mode == RestoreReplace {
+		return slices.Clone(backup.Disabled), slices.Clone(backup.DisabledPlugins)
+	}
+	return union(current.Disabled, backup.Disabled), union(current.DisabledPlugins, backup.DisabledPlugins)
+}
+
+func (s *SkillService) planRestore(archive *backupArchive, opts RestoreOptions) (*RestoreReport, error) {
+	report := &RestoreReport{
+		Mode:           opts.Mode,
+		DryRun:         opts.DryRun,
+		Skills:         []RestoreSkill{},
+		MissingPlugins: []PluginInfo{},
+		Warnings:       append([]string{}, archive.warnings...),
+	}
+
+	local, err := s.localSkillDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	inBackup := make(map[string]bool)
+	for _, skill := range archive.manifest.Skills {
+		inBackup[skill.Name] = true
+		action := "add"
+		if _, ok := local[skill.Name]; ok {
+			action = "keep"
+			if opts.Mode == RestoreReplace {
+				action = "replace"
+			}
+		}
+		report.Skills = append(report.Skills, RestoreSkill{Name: skill.Name, Enabled: skill.Enabled, Action: action})
+	}
+	if opts.Mode == RestoreReplace {
+		for _, name := range sortedKeys(local) {
+			if !inBackup[name] {
+				enabled := filepath.Dir(local[name]) == s.enabledDir
+				report.Skills = append(report.Skills, RestoreSkill{Name: name, Enabled: enabled, Action: "remove"})
+			}
+		}
+	}
+
+	current, err := config.LoadOverrides()
+	if err != nil {
+		return nil, err
+	}
+	target := &config.SkillOverrides{}
+	target.Disabled, target.DisabledPlugins = restoredOverrides(current, archive.overrides, opts.Mode)
+	report.Overrides = config.DiffOverrides(current, target)
+
+	installed, err := s.InstalledPlugins()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range archive.manifest.Plugins {
+		i := slices.IndexFunc(installed, func(q PluginInfo) bool { return q.Org == p.Org && q.Name == p.Name })
+		switch {
+		case i < 0:
+			report.MissingPlugins = append(report.MissingPlugins, p)
+		case installed[i].Version != p.Version:
+			report.Warnings = append(report.Warnings,
+				fmt.Sprintf("plugin %s is at version %s here, the backup had %s", p.Name, installed[i].Version, p.Version))
+		}
+	}
+	return report, nil
+}
+
+// applyRestore moves removed and replaced skills to the trash and writes the
+// skills from the archive. It returns the operations it performed, for the
+// history. The caller holds s.mu.
+func (s *SkillService) applyRestore(archive *backupArchive, report *RestoreReport) ([]history.Operation, error) {
+	local, err := s.localSkillDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	staging, err := os.MkdirTemp(s.baseDir, ".skill-restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	var ops []history.Operation
+	for _, skill := range report.Skills {
+		if skill.Action == "keep" {
+			continue
+		}
+
+		if skill.Action == "remove" || skill.Action == "replace" {
+			dir := local[skill.Name]
+			trashID, err := s.trashSkill(dir)
+			if err != nil {
+				return ops, err
+			}
+			ops = append(ops, history.Operation{
+				Type:    history.OpDeleteSkill,
+				Target:  skill.Name,
+				Enabled: filepath.Dir(dir) == s.enabledDir,
+				TrashID: trashID,
+			})
+		}
+		if skill.Action == "remove" {
+			continue
+		}
+
+		tmp := filepath.Join(staging, skill.Name)
+		if err := writeBackupFiles(tmp, archive.files[skill.Name]); err != nil {
+			return ops, err
+		}
+		dst := filepath.Join(s.disabledDir, skill.Name)
+		if skill.Enabled {
+			dst = filepath.Join(s.enabledDir, skill.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return ops, err
+		}
+		if err := moveDir(tmp, dst); err != nil {
+			return ops, err
+		}
+		s.index.invalidate(dst)
+		ops = append(ops, history.Operation{Type: history.OpCreateSkill, Target: skill.Name})
+	}
+	return ops, nil
+}
+
+// localSkillDirs maps the name of every user skill to its directory.
+func (s *SkillService) localSkillDirs() (map[string]string, error) {
+	dirs := make(map[string]string)
+	for _, dir := range []string{s.disabledDir, s.enabledDir} {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				dirs[entry.Name()] = filepath.Join(dir, entry.Name())
+			}
+		}
+	}
+	return dirs, nil
+}
+
+func readBackup(r io.Reader) (*backupArchive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer gz.Close()
+
+	archive := &backupArchive{files: make(map[string][]backupFile)}
+	links := make(map[string]bool) // "<skill>/<rel>" of every accepted symlink
+	var haveManifest bool
+	var total int64
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+
+		total += hdr.Size
+		if total > maxBackupSize {
+			return nil, fmt.Errorf("%w: larger than %d bytes", ErrInvalidBackup, maxBackupSize)
+		}
+
+		name := strings.TrimPrefix(path.Clean(hdr.Name), "./")
+		switch name {
+		case backupManifestFile:
+			if err := json.NewDecoder(tr).Decode(&archive.manifest); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBackup, name, err)
+			}
+			haveManifest = true
+			continue
+		case backupOverridesFile:
+			if err := json.NewDecoder(tr).Decode(&archive.overrides); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBackup, name, err)
+			}
+			continue
+		}
+
+		skill, rel, ok := splitBackupPath(name)
+		if !ok || underLink(links, skill, rel) {
+			archive.warnings = append(archive.warnings, "skipped "+hdr.Name)
+			continue
+		}
+
+		f := backupFile{path: rel, mode: fs.FileMode(hdr.Mode).Perm()}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			f.dir = true
+		case tar.TypeReg:
+			if f.data, err = io.ReadAll(tr); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+			}
+		case tar.TypeSymlink:
+			// Only relative links that stay inside the skill directory. No
+			// entry may sit below another link, so the check holds for the
+			// real path too.
+			if rel == "." || filepath.IsAbs(hdr.Linkname) || path.IsAbs(hdr.Linkname) ||
+				!filepath.IsLocal(filepath.Join(filepath.Dir(rel), hdr.Linkname)) {
+				archive.warnings = append(archive.warnings, "skipped symlink "+hdr.Name)
+				continue
+			}
+			f.linkname = hdr.Linkname
+			links[skill+"/"+rel] = true
+		default:
+			archive.warnings = append(archive.warnings, "skipped "+hdr.Name)
+			continue
+		}
+		archive.files[skill] = append(archive.files[skill], f)
+	}
+
+	if !haveManifest {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidBackup, backupManifestFile)
+	}
+	if archive.manifest.Version > backupFormatVersion {
+		return nil, fmt.Errorf("%w: format version %d is newer than supported %d", ErrInvalidBackup, archive.manifest.Version, backupFormatVersion)
+	}
+	if archive.overrides == nil {
+		archive.overrides = &config.SkillOverrides{}
+	}
+	if archive.overrides.Disabled == nil {
+		archive.overrides.Disabled = []string{}
+	}
+	if archive.overrides.DisabledPlugins == nil {
+		archive.overrides.DisabledPlugins = []string{}
+	}
+
+	for _, skill := range archive.manifest.Skills {
+		if !validDirName(skill.Name) {
+			return nil, fmt.Errorf("%w: invalid skill name %q", ErrInvalidBackup, skill.Name)
+		}
+		if len(archive.files[skill.Name]) == 0 {
+			return nil, fmt.Errorf("%w: no files for skill %s", ErrInvalidBackup, skill.Name)
+		}
+	}
+	return archive, nil
+}
+
+// splitBackupPath splits "skills/<skill>/<path>" into the skill name and
+// the path inside the skill directory.
+func splitBackupPath(name string) (skill, rel string, ok bool) {
+	name, ok = strings.CutPrefix(name, backupSkillsDir+"/")
+	if !ok || !fs.ValidPath(name) {
+		return "", "", false
+	}
+	skill, rel, _ = strings.Cut(name, "/")
+	if rel == "" {
+		rel = "."
+	}
+	return skill, rel, validDirName(skill)
+}
+
+// underLink reports whether rel, or one of its parents, is below a symlink
+// already accepted for skill.
+func underLink(links map[string]bool, skill, rel string) bool {
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		if links[skill+"/"+dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// writeBackupFiles writes files below dir through an os.Root, so nothing
+// outside dir is touched even if a link points out of it.
+func writeBackupFiles(dir string, files []backupFile) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	for _, f := range files {
+		target := filepath.FromSlash(f.path)
+		switch {
+		case f.dir:
+			if err := root.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case f.linkname != "":
+			if err := root.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := root.Symlink(f.linkname, target); err != nil {
+				return err
+			}
+		default:
+			if err := root.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := root.WriteFile(target, f.data, f.mode|0600); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeTarDir adds the tree at dir to the archive under prefix. Symlinks are
+// stored as links.
+func writeTarDir(tw *tar.Writer, dir, prefix string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		var link string
+		if d.Type()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = path.Join(prefix, filepath.ToSlash(rel))
+		if d.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
+
+func writeTarJSON(tw *tar.Writer, name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func union(a, b []string) []string {
+	out := append([]string{}, a...)
+	for _, item := range b {
+		if !slices.Contains(out, item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wind/skill-router/internal/config"
+)
+
+func TestBackupRestore(t *testing.T) {
+	// Source machine
+	srcDir := t.TempDir()
+	config.Init(srcDir)
+	setupSkill(t, filepath.Join(srcDir, "skills"), "notes")
+	setupSkill(t, filepath.Join(srcDir, "skills-disabled"), "drafts")
+	os.MkdirAll(filepath.Join(srcDir, "plugins", "cache", "acme", "tools", "1.2.0", "skills"), 0755)
+	config.DisablePluginSkill("tools", "lint")
+
+	var archive bytes.Buffer
+	if err := NewSkillService(srcDir).ExportBackup(&archive); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	// Target machine with one skill of its own
+	dstDir := t.TempDir()
+	config.Init(dstDir)
+	setupSkill(t, filepath.Join(dstDir, "skills"), "local")
+	// A plugin of the same name from another org is not the backup's
+	os.MkdirAll(filepath.Join(dstDir, "plugins", "cache", "other", "tools", "1.2.0", "skills"), 0755)
+	svc := NewSkillService(dstDir)
+
+	report, err := svc.RestoreBackup(bytes.NewReader(archive.Bytes()), RestoreOptions{Mode: RestoreReplace, DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	actions := make(map[string]string)
+	for _, skill := range report.Skills {
+		actions[skill.Name] = skill.Action
+	}
+	if actions["notes"] != "add" || actions["drafts"] != "add" || actions["local"] != "remove" {
+		t.Errorf("unexpected dry run actions: %v", actions)
+	}
+	if len(report.MissingPlugins) != 1 || report.MissingPlugins[0].Version != "1.2.0" {
+		t.Errorf("expected tools 1.2.0 to be missing, got %+v", report.MissingPlugins)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "skills", "notes")); !os.IsNotExist(err) {
+		t.Fatal("dry run must not change anything")
+	}
+
+	// Merge keeps the local skill
+	if _, err := svc.RestoreBackup(bytes.NewReader(archive.Bytes()), RestoreOptions{Mode: RestoreMerge}); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dstDir, "skills", "notes", "scripts", "run.sh"))
+	if err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("expected executable supporting file restored, got %v, %v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "skills-disabled", "drafts", "SKILL.md")); err != nil {
+		t.Errorf("expected disabled skill restored as disabled: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "skills", "local")); err != nil {
+		t.Errorf("merge must keep local skills: %v", err)
+	}
+	if !config.IsPluginSkillDisabled("tools", "lint") {
+		t.Error("expected overrides to be restored")
+	}
+
+	// Replace moves the local skill to the trash
+	if _, err := svc.RestoreBackup(bytes.NewReader(archive.Bytes()), RestoreOptions{Mode: RestoreReplace}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "skills", "local")); !os.IsNotExist(err) {
+		t.Error("replace should remove skills that are not in the backup")
+	}
+	items, _ := svc.ListTrash()
+	if len(items) != 3 {
+		t.Errorf("expected local, notes and drafts in the trash, got %+v", items)
+	}
+}
+
+func TestRestoreBackup_Invalid(t *testing.T) {
+	svc := NewSkillService(t.TempDir())
+
+	_, err := svc.RestoreBackup(strings.NewReader("not an archive"), RestoreOptions{})
+	if !errors.Is(err, ErrInvalidBackup) {
+		t.Errorf("expected ErrInvalidBackup, got %v", err)
+	}
+	if _, err := svc.RestoreBackup(strings.NewReader(""), RestoreOptions{Mode: "wipe"}); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+func TestSplitBackupPath(t *testing.T) {
+	tests := []struct {
+		name, skill, rel string
+		ok               bool
+	}{
+		{"skills/notes/SKILL.md", "notes", "SKILL.md", true},
+		{"skills/notes", "notes", ".", true},
+		{"skills/notes/scripts/run.sh", "notes", "scripts/run.sh", true},
+		{"skills/../etc/passwd", "", "", false},
+		{"other/notes/SKILL.md", "", "", false},
+		{"skills/..", "", "", false},
+	}
+	for _, tt := range tests {
+		skill, rel, ok := splitBackupPath(tt.name)
+		if ok != tt.ok || (ok && (skill != tt.skill || rel != tt.rel)) {
+			t.Errorf("splitBackupPath(%q) = %q, %q, %v", tt.name, skill, rel, ok)
+		}
+	}
+}
+
+// craftBackup builds an archive for skill "evil" from raw tar headers.
+func craftBackup(t *testing.T, entries []tar.Header, data map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	manifest, _ := json.Marshal(BackupManifest{Version: backupFormatVersion, Skills: []BackupSkill{{Name: "evil", Enabled: true}}})
+	entries = append([]tar.Header{
+		{Name: backupManifestFile, Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "skills/evil/SKILL.md", Typeflag: tar.TypeReg, Mode: 0644},
+	}, entries...)
+	data[backupManifestFile] = string(manifest)
+	data["skills/evil/SKILL.md"] = "---\nname: evil\ndescription: Evil\n---\n"
+
+	for _, hdr := range entries {
+		hdr.Size = int64(len(data[hdr.Name]))
+		if err := tw.WriteHeader(&hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(data[hdr.Name]))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestRestoreBackup_LinksStayInside(t *testing.T) {
+	outside := t.TempDir()
+	cases := map[string][]tar.Header{
+		"absolute link": {
+			{Name: "skills/evil/out", Typeflag: tar.TypeSymlink, Linkname: outside},
+			{Name: "skills/evil/out/pwned.txt", Typeflag: tar.TypeReg, Mode: 0644},
+		},
+		"relative link": {
+			{Name: "skills/evil/out", Typeflag: tar.TypeSymlink, Linkname: "../../../../" + outside},
+			{Name: "skills/evil/out/pwned.txt", Typeflag: tar.TypeReg, Mode: 0644},
+		},
+		// up -> .. stays inside on its own, but up/out resolves one level
+		// above the skill, and up/out/x further out still
+		"chained links": {
+			{Name: "skills/evil/sub", Typeflag: tar.TypeDir, Mode: 0755},
+			{Name: "skills/evil/sub/up", Typeflag: tar.TypeSymlink, Linkname: ".."},
+			{Name: "skills/evil/sub/up/out", Typeflag: tar.TypeSymlink, Linkname: "../.."},
+			{Name: "skills/evil/sub/up/out/pwned.txt", Typeflag: tar.TypeReg, Mode: 0644},
+		},
+	}
+	for name, entries := range cases {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			config.Init(tmpDir)
+			svc := NewSkillService(tmpDir)
+
+			data := map[string]string{}
+			for _, hdr := range entries {
+				if hdr.Typeflag == tar.TypeReg {
+					data[hdr.Name] = "pwned"
+				}
+			}
+			report, err := svc.RestoreBackup(bytes.NewReader(craftBackup(t, entries, data)), RestoreOptions{Mode: RestoreMerge})
+			if err != nil {
+				t.Fatalf("restore: %v", err)
+			}
+			if len(report.Warnings) == 0 {
+				t.Error("expected the skipped entries to be reported")
+			}
+			if _, err := os.Stat(filepath.Join(outside, "pwned.txt")); !os.IsNotExist(err) {
+				t.Fatal("a file was written outside the skill directory")
+			}
+			if _, err := os.Stat(filepath.Join(tmpDir, "pwned.txt")); !os.IsNotExist(err) {
+				t.Fatal("a file was written outside the skill directory")
+			}
+			if _, err := os.Stat(filepath.Join(tmpDir, "skills", "evil", "SKILL.md")); err != nil {
+				t.Errorf("expected the skill itself to be restored: %v", err)
+			}
+		})
+	}
+}
+
+func TestRestoreBackup_KeepsLocalLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+	svc := NewSkillService(tmpDir)
+
+	entries := []tar.Header{
+		{Name: "skills/evil/docs", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "skills/evil/docs/guide.md", Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "skills/evil/guide.md", Typeflag: tar.TypeSymlink, Linkname: "docs/guide.md"},
+	}
+	report, err := svc.RestoreBackup(bytes.NewReader(craftBackup(t, entries, map[string]string{"skills/evil/docs/guide.md": "guide"})), RestoreOptions{Mode: RestoreMerge})
+	if err != nil || len(report.Warnings) != 0 {
+		t.Fatalf("restore: %+v, %v", report, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(tmpDir, "skills", "evil", "guide.md")); err != nil || string(data) != "guide" {
+		t.Errorf("expected the link to be restored, got %q, %v", data, err)
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/wind/skill-router/internal/model"
+)
+
+// DefaultContextBudget is the default number of tokens the metadata of all
+// enabled skills may take up in every session.
+const DefaultContextBudget = 5000
+
+// MaxDescriptionLength is the longest description, in characters, Claude
+// Code accepts in a skill's frontmatter.
+const MaxDescriptionLength = 1024
+
+const (
+	charsPerToken  = 4
+	metadataTokens = 4 // list formatting around each skill's name and description
+)
+
+// Budget warning kinds.
+const (
+	WarningOverBudget      = "over-budget"
+	WarningLongDescription = "long-description"
+)
+
+// EstimateTokens approximates how many tokens text takes up: one per four
+// ASCII characters and one per other character, which covers CJK text.
+func EstimateTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+charsPerToken-1)/charsPerToken + other
+}
+
+// MetadataTokens estimates the tokens a skill's name and description add to
+// the context while it is enabled.
+func MetadataTokens(name, description string) int {
+	return EstimateTokens(name+": "+description) + metadataTokens
+}
+
+// BudgetGroup totals the enabled skills of one source or plugin.
+type BudgetGroup struct {
+	Name   string `json:"name"`
+	Skills int    `json:"skills"`
+	Tokens int    `json:"tokens"`
+}
+
+type BudgetWarning struct {
+	Kind    string `json:"kind"`
+	Skill   string `json:"skill,omitempty"` // SkillRef.ID, empty for over-budget
+	Message string `json:"message"`
+}
+
+// ContextBudget is the estimated context taken up by the metadata of the
+// enabled skills.
+type ContextBudget struct {
+	Budget   int             `json:"budget"`
+	Total    int             `json:"total"`
+	Skills   int             `json:"skills"`
+	BySource []BudgetGroup   `json:"bySource"`
+	ByPlugin []BudgetGroup   `json:"byPlugin"`
+	Warnings []BudgetWarning `json:"warnings"`
+}
+
+// ContextBudget summarizes the metadata tokens of the skills enabled in
+// projectDir (or globally, if empty) against budget.
+func (s *SkillService) ContextBudget(projectDir string, budget int) (*ContextBudget, error) {
+	skills, err := s.ListProjectSkills(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	return SummarizeBudget(skills, budget), nil
+}
+
+// SummarizeBudget totals the Tokens of the enabled skills per source and per
+// plugin, largest plugin first. It warns about descriptions longer than
+// MaxDescriptionLength and about a total over budget.
+func SummarizeBudget(skills []model.Skill, budget int) *ContextBudget {
+	b := &ContextBudget{Budget: budget, BySource: []BudgetGroup{}, ByPlugin: []BudgetGroup{}, Warnings: []BudgetWarning{}}
+
+	sources := make(map[string]*BudgetGroup)
+	plugins := make(map[string]*BudgetGroup)
+	add := func(groups map[string]*BudgetGroup, name string, tokens int) {
+		g, ok := groups[name]
+		if !ok {
+			g = &BudgetGroup{Name: name}
+			groups[name] = g
+		}
+		g.Skills++
+		g.Tokens += tokens
+	}
+
+	var long []BudgetWarning
+	for _, skill := range skills {
+		if !skill.Enabled {
+			continue
+		}
+		b.Total += skill.Tokens
+		b.Skills++
+		add(sources, skill.Source, skill.Tokens)
+		if skill.Source == "plugin" {
+			add(plugins, skill.PluginName, skill.Tokens)
+		}
+
+		if n := utf8.RuneCountInString(skill.Description); n > MaxDescriptionLength {
+			long = append(long, BudgetWarning{
+				Kind:    WarningLongDescription,
+				Skill:   SkillID(skill),
+				Message: fmt.Sprintf("description is %d characters, over the limit of %d", n, MaxDescriptionLength),
+			})
+		}
+	}
+
+	for _, source := range []string{"user", "project", "plugin"} {
+		if g, ok := sources[source]; ok {
+			b.BySource = append(b.BySource, *g)
+		}
+	}
+	for _, g := range plugins {
+		b.ByPlugin = append(b.ByPlugin, *g)
+	}
+	sort.Slice(b.ByPlugin, func(i, j int) bool {
+		if b.ByPlugin[i].Tokens != b.ByPlugin[j].Tokens {
+			return b.ByPlugin[i].Tokens > b.ByPlugin[j].Tokens
+		}
+		return b.ByPlugin[i].Name < b.ByPlugin[j].Name
+	})
+
+	if b.Total > budget {
+		b.Warnings = append(b.Warnings, BudgetWarning{
+			Kind:    WarningOverBudget,
+			Message: fmt.Sprintf("enabled skills take about %d tokens, over the budget of %d", b.Total, budget),
+		})
+	}
+	sort.Slice(long, func(i, j int) bool { return long[i].Skill < long[j].Skill })
+	b.Warnings = append(b.Warnings, long...)
+
+	return b
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wind/skill-router/internal/config"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	cases := map[string]int{
+		"":        0,
+		"a":       1,
+		"abcd":    1,
+		"abcde":   2,
+		"技能":      2,
+		"lint 技能": 4,
+	}
+	for text, want := range cases {
+		if got := EstimateTokens(text); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestContextBudget(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	writeSkill := func(dir, name, description string) {
+		os.MkdirAll(filepath.Join(dir, name), 0755)
+		content := "---\nname: " + name + "\ndescription: " + description + "\n---\nContent"
+		os.WriteFile(filepath.Join(dir, name, "SKILL.md"), []byte(content), 0644)
+	}
+	long := strings.Repeat("x", MaxDescriptionLength+1)
+	writeSkill(filepath.Join(tmpDir, "skills"), "notes", "Take notes")
+	writeSkill(filepath.Join(tmpDir, "skills-disabled"), "essay", long)
+	pluginDir := filepath.Join(tmpDir, "plugins", "cache", "acme", "tools", "1.0.0", "skills")
+	writeSkill(pluginDir, "lint", long)
+	writeSkill(pluginDir, "format", "Format code")
+	writeSkill(filepath.Join(tmpDir, "plugins", "cache", "acme", "docs", "1.0.0", "skills"), "spell", "Check spelling")
+
+	svc := NewSkillService(tmpDir)
+	skills, err := svc.ListSkills()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := make(map[string]int)
+	for _, skill := range skills {
+		if skill.Tokens != MetadataTokens(skill.Name, skill.Description) {
+			t.Errorf("%s: expected the metadata estimate, got %d", skill.FileName, skill.Tokens)
+		}
+		tokens[skill.FileName] = skill.Tokens
+	}
+
+	budget, err := svc.ContextBudget("", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pluginTotal := tokens["lint"] + tokens["format"] + tokens["spell"]
+	if budget.Skills != 4 || budget.Total != tokens["notes"]+pluginTotal {
+		t.Errorf("unexpected totals %+v", budget)
+	}
+	if len(budget.BySource) != 2 || budget.BySource[0] != (BudgetGroup{"user", 1, tokens["notes"]}) || budget.BySource[1] != (BudgetGroup{"plugin", 3, pluginTotal}) {
+		t.Errorf("unexpected sources %+v", budget.BySource)
+	}
+	if len(budget.ByPlugin) != 2 || budget.ByPlugin[0].Name != "tools" || budget.ByPlugin[1] != (BudgetGroup{"docs", 1, tokens["spell"]}) {
+		t.Errorf("expected the largest plugin first, got %+v", budget.ByPlugin)
+	}
+	if len(budget.Warnings) != 2 || budget.Warnings[0].Kind != WarningOverBudget ||
+		budget.Warnings[1].Kind != WarningLongDescription || budget.Warnings[1].Skill != "plugin/tools/lint" {
+		t.Errorf("expected over-budget and lint warnings only, got %+v", budget.Warnings)
+	}
+
+	budget, err = svc.ContextBudget("", DefaultContextBudget)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(budget.Warnings) != 1 || budget.Warnings[0].Kind != WarningLongDescription {
+		t.Errorf("expected no over-budget warning, got %+v", budget.Warnings)
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/history"
+)
+
+// Bulk actions and target types.
+const (
+	BulkEnable  = "enable"
+	BulkDisable = "disable"
+	BulkDelete  = "delete"
+
+	TargetUser        = "user"
+	TargetPluginSkill = "plugin-skill"
+	TargetPlugin      = "plugin"
+)
+
+var ErrBulkFailed = errors.New("bulk operation failed")
+
+// BulkTarget names a user skill (Name), a plugin skill (Plugin and Name) or
+// a whole plugin (Plugin).
+type BulkTarget struct {
+	Type   string `json:"type"`
+	Name   string `json:"name,omitempty"`
+	Plugin string `json:"plugin,omitempty"`
+}
+
+// BulkResult is the outcome for one target. Status is "ok", "unchanged",
+// "failed", "rolled-back" or "not-run".
+type BulkResult struct {
+	Target BulkTarget `json:"target"`
+	Status string     `json:"status"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// bulkMove is a directory move done during a bulk operation, kept so it can
+// be rolled back.
+type bulkMove struct {
+	index   int
+	src     string
+	dst     string
+	trashID string
+	op      history.Operation
+}
+
+// Bulk applies one action to many targets as a single transaction. User
+// skill directories are moved first; plugin skill and plugin changes then
+// go into one write of the overrides file. If anything fails, the moves
+// done so far are undone and ErrBulkFailed is returned with the results.
+func (s *SkillService) Bulk(action string, targets []BulkTarget) ([]BulkResult, error) {
+	results := make([]BulkResult, len(targets))
+	for i, t := range targets {
+		results[i] = BulkResult{Target: t, Status: "not-run"}
+	}
+
+	if action != BulkEnable && action != BulkDisable && action != BulkDelete {
+		return results, fmt.Errorf("%w: %w: unknown action %q", ErrBulkFailed, ErrInvalidRequest, action)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Validate everything before touching anything
+	failed := false
+	for i, t := range targets {
+		if err := s.validateBulkTarget(action, t); err != nil {
+			results[i].Status, results[i].Error = "failed", err.Error()
+			failed = true
+		}
+	}
+	if failed {
+		return results, ErrBulkFailed
+	}
+
+	var moves []bulkMove
+	rollback := func(cause error) ([]BulkResult, error) {
+		for j := len(moves) - 1; j >= 0; j-- {
+			m := moves[j]
+			var err error
+			if m.trashID != "" {
+				_, err = s.restoreTrash(m.trashID)
+			} else {
+				err = os.Rename(m.dst, m.src)
+			}
+			results[m.index].Status = "rolled-back"
+			if err != nil {
+				results[m.index].Error = "rollback: " + err.Error()
+			}
+		}
+		return results, fmt.Errorf("%w: %v", ErrBulkFailed, cause)
+	}
+
+	for i, t := range targets {
+		m, changed, err := s.bulkMove(action, t)
+		if err != nil {
+			results[i].Status, results[i].Error = "failed", err.Error()
+			return rollback(err)
+		}
+		if !changed {
+			continue
+		}
+		m.index = i
+		moves = append(moves, m)
+		results[i].Status = "ok"
+	}
+
+	// Statuses are only set once the write has succeeded, and the change is
+	// recorded with the moves rather than on its own
+	changed := make([]bool, len(targets))
+	change, err := config.ChangeOverrides(func(o *config.SkillOverrides) error {
+		var forget []string
+		for i, t := range targets {
+			if t.Type == TargetUser {
+				continue
+			}
+			changed[i] = applyBulkOverride(o, action, t)
+			if action == BulkDelete {
+				forget = append(forget, t.Plugin)
+			}
+		}
+		// Another org may still ship a deleted plugin under the same name
+		installed, err := s.installedPlugins()
+		if err != nil {
+			return err
+		}
+		for _, name := range forget {
+			if !installed[name] {
+				o.Disabled = slices.DeleteFunc(o.Disabled, func(key string) bool { return strings.HasPrefix(key, name+":") })
+				o.DisabledPlugins = slices.DeleteFunc(o.DisabledPlugins, func(p string) bool { return p == name })
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return rollback(err)
+	}
+
+	for i := range results {
+		switch {
+		case changed[i]:
+			results[i].Status = "ok"
+		case results[i].Status == "not-run":
+			results[i].Status = "unchanged"
+		}
+	}
+
+	// The whole call is a single step in the history
+	op := history.Operation{Type: history.OpBulk, Target: action}
+	for _, m := range moves {
+		op.Steps = append(op.Steps, m.op)
+	}
+	if !change.Empty() {
+		op.Change = &change
+	}
+	if len(op.Steps) > 0 || op.Change != nil {
+		s.record(op)
+	}
+	return results, nil
+}
+
+func (s *SkillService) validateBulkTarget(action string, t BulkTarget) error {
+	switch t.Type {
+	case TargetUser:
+		if _, err := s.findUserSkill(t.Name); err != nil {
+			return err
+		}
+	case TargetPluginSkill:
+		if !validDirName(t.Plugin) || !validDirName(t.Name) {
+			return fmt.Errorf("%w: plugin skill %s:%s", ErrInvalidName, t.Plugin, t.Name)
+		}
+		if action == BulkDelete {
+			return fmt.Errorf("%w: plugin skills cannot be deleted, delete or disable the plugin instead", ErrInvalidRequest)
+		}
+	case TargetPlugin:
+		if !validDirName(t.Plugin) {
+			return invalidPluginName(t.Plugin)
+		}
+		if action == BulkDelete {
+			if installed, err := s.installedPlugins(); err != nil || !installed[t.Plugin] {
+				return fmt.Errorf("%w: %s", ErrPluginNotFound, t.Plugin)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown target type %q", ErrInvalidRequest, t.Type)
+	}
+	return nil
+}
+
+// bulkMove performs the directory part of an action: moving a user skill
+// between the enabled and disabled locations, or into the trash. It reports
+// whether anything changed. The caller holds s.mu.
+func (s *SkillService) bulkMove(action string, t BulkTarget) (bulkMove, bool, error) {
+	switch {
+	case t.Type == TargetUser:
+		dir, err := s.findUserSkill(t.Name)
+		if err != nil {
+			return bulkMove{}, false, err
+		}
+		enabled := filepath.Dir(dir) == s.enabledDir
+
+		switch action {
+		case BulkDelete:
+			trashID, err := s.trashSkill(dir)
+			op := history.Operation{Type: history.OpDeleteSkill, Target: t.Name, Enabled: enabled, TrashID: trashID}
+			return bulkMove{trashID: trashID, op: op}, err == nil, err
+		case BulkEnable:
+			if enabled {
+				return bulkMove{}, false, nil
+			}
+			dst := filepath.Join(s.enabledDir, t.Name)
+			op := history.Operation{Type: history.OpEnableSkill, Target: t.Name}
+			return bulkMove{src: dir, dst: dst, op: op}, true, s.moveSkill(t.Name, s.disabledDir, s.enabledDir)
+		default:
+			if !enabled {
+				return bulkMove{}, false, nil
+			}
+			dst := filepath.Join(s.disabledDir, t.Name)
+			op := history.Operation{Type: history.OpDisableSkill, Target: t.Name}
+			return bulkMove{src: dir, dst: dst, op: op}, true, s.moveSkill(t.Name, s.enabledDir, s.disabledDir)
+		}
+
+	case t.Type == TargetPlugin && action == BulkDelete:
+		trashID, err := s.trashPlugin(t.Plugin)
+		op := history.Operation{Type: history.OpDeletePlugin, Target: t.Plugin, TrashID: trashID}
+		return bulkMove{trashID: trashID, op: op}, err == nil, err
+	}
+	return bulkMove{}, false, nil
+}
+
+// applyBulkOverride changes the overrides for a plugin skill or plugin
+// target and reports whether anything changed.
+func applyBulkOverride(o *config.SkillOverrides, action string, t BulkTarget) bool {
+	list, key := &o.DisabledPlugins, t.Plugin
+	if t.Type == TargetPluginSkill {
+		list, key = &o.Disabled, t.Plugin+":"+t.Name
+	}
+
+	switch action {
+	case BulkDisable:
+		if slices.Contains(*list, key) {
+			return false
+		}
+		*list = append(*list, key)
+		if t.Type == TargetPlugin {
+			// Individual skill entries are redundant now, like DisablePlugin
+			o.Disabled = slices.DeleteFunc(o.Disabled, func(k string) bool { return strings.HasPrefix(k, t.Plugin+":") })
+		}
+		return true
+	case BulkEnable:
+		before := len(*list)
+		*list = slices.DeleteFunc(*list, func(k string) bool { return k == key })
+		return len(*list) != before
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/history"
+)
+
+func TestBulk_EnablePluginSkillsInOneStep(t *testing.T) {
+	svc, tmpDir := newHistoryService(t)
+	setupSkill(t, filepath.Join(tmpDir, "skills-disabled"), "notes")
+	config.DisablePluginSkill("tools", "lint")
+	config.DisablePluginSkill("tools", "format")
+
+	results, err := svc.Bulk(BulkEnable, []BulkTarget{
+		{Type: TargetPluginSkill, Plugin: "tools", Name: "lint"},
+		{Type: TargetPluginSkill, Plugin: "tools", Name: "format"},
+		{Type: TargetPluginSkill, Plugin: "tools", Name: "test"},
+		{Type: TargetUser, Name: "notes"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	statuses := []string{results[0].Status, results[1].Status, results[2].Status, results[3].Status}
+	want := []string{"ok", "ok", "unchanged", "ok"}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Errorf("result %d: expected %s, got %s", i, want[i], statuses[i])
+		}
+	}
+	if config.IsPluginSkillDisabled("tools", "lint") || config.IsPluginSkillDisabled("tools", "format") {
+		t.Error("expected plugin skills to be enabled")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "skills", "notes")); err != nil {
+		t.Errorf("expected user skill to be enabled: %v", err)
+	}
+
+	// The move and the overrides write are one entry after the two disables
+	entries, _, _, err := svc.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[2].Op.Type != history.OpBulk {
+		t.Fatalf("expected a single bulk entry, got %+v", entries)
+	}
+	if _, err := svc.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if !config.IsPluginSkillDisabled("tools", "lint") || !config.IsPluginSkillDisabled("tools", "format") {
+		t.Error("expected undo to disable the plugin skills again")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "skills-disabled", "notes")); err != nil {
+		t.Errorf("expected undo to disable the user skill again: %v", err)
+	}
+	if _, err := svc.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if config.IsPluginSkillDisabled("tools", "lint") {
+		t.Error("expected redo to enable the plugin skill again")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "skills", "notes")); err != nil {
+		t.Errorf("expected redo to enable the user skill again: %v", err)
+	}
+}
+
+func TestBulk_ValidationFailsBeforeChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+	setupSkill(t, filepath.Join(tmpDir, "skills"), "notes")
+
+	svc := NewSkillService(tmpDir)
+	results, err := svc.Bulk(BulkDisable, []BulkTarget{
+		{Type: TargetUser, Name: "notes"},
+		{Type: TargetUser, Name: "missing"},
+	})
+	if !errors.Is(err, ErrBulkFailed) {
+		t.Fatalf("expected ErrBulkFailed, got %v", err)
+	}
+	if results[0].Status != "not-run" || results[1].Status != "failed" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "skills", "notes")); err != nil {
+		t.Error("nothing should have been changed")
+	}
+}
+
+func TestBulk_RollsBackMoves(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+	setupSkill(t, filepath.Join(tmpDir, "skills"), "a")
+	setupSkill(t, filepath.Join(tmpDir, "skills"), "b")
+
+	// An unreadable overrides file makes the overrides write fail
+	os.WriteFile(config.OverridesPath(), []byte("{not json"), 0644)
+
+	svc := NewSkillService(tmpDir)
+	results, err := svc.Bulk(BulkDelete, []BulkTarget{
+		{Type: TargetUser, Name: "a"},
+		{Type: TargetUser, Name: "b"},
+	})
+	if !errors.Is(err, ErrBulkFailed) {
+		t.Fatalf("expected ErrBulkFailed, got %v", err)
+	}
+	for _, r := range results {
+		if r.Status != "rolled-back" {
+			t.Errorf("expected rolled-back, got %+v", r)
+		}
+	}
+	for _, name := range []string{"a", "b"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, "skills", name, "SKILL.md")); err != nil {
+			t.Errorf("expected %s restored: %v", name, err)
+		}
+	}
+}
+
+func TestBulk_FailedWriteLeavesPluginTargetsUnapplied(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+	setupSkill(t, filepath.Join(tmpDir, "skills"), "a")
+
+	// A plugins cache that cannot be listed makes the overrides update fail
+	// after the plugin skill has been changed in memory
+	os.MkdirAll(filepath.Join(tmpDir, "plugins"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "plugins", "cache"), nil, 0644)
+
+	svc := NewSkillService(tmpDir)
+	results, err := svc.Bulk(BulkDisable, []BulkTarget{
+		{Type: TargetUser, Name: "a"},
+		{Type: TargetPluginSkill, Plugin: "tools", Name: "lint"},
+	})
+	if !errors.Is(err, ErrBulkFailed) {
+		t.Fatalf("expected ErrBulkFailed, got %v", err)
+	}
+	if results[0].Status != "rolled-back" || results[1].Status != "not-run" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if config.IsPluginSkillDisabled("tools", "lint") {
+		t.Error("the plugin skill should not have been disabled")
+	}
+}
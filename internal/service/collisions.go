@@ -0,0 +1,266 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/wind/skill-router/internal/model"
+)
+
+// Collision kinds, from most to least certain.
+const (
+	CollisionName               = "name"
+	CollisionSimilarName        = "similar-name"
+	CollisionSimilarDescription = "similar-description"
+)
+
+// Thresholds above which two skills count as near-duplicates.
+const (
+	similarNameThreshold        = 0.8
+	similarDescriptionThreshold = 0.6
+	minDescriptionWords         = 3
+)
+
+// Collision is a set of enabled skills that Claude may confuse with each
+// other: they share a frontmatter name or have very similar names or
+// descriptions.
+type Collision struct {
+	Kind        string       `json:"kind"`
+	Name        string       `json:"name"`
+	Similarity  float64      `json:"similarity"`
+	Skills      []SkillRef   `json:"skills"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// SkillRef identifies a skill across sources.
+type SkillRef struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	FileName   string `json:"fileName"`
+	Source     string `json:"source"`
+	PluginName string `json:"pluginName,omitempty"`
+}
+
+// Suggestion is a possible way to resolve a collision.
+type Suggestion struct {
+	Action string `json:"action"` // "disable", "rename" or "fork"
+	Skill  string `json:"skill"`  // SkillRef.ID
+	Reason string `json:"reason"`
+}
+
+// FindCollisions reports collisions between the skills enabled in
+// projectDir (or globally, if empty). Disabled skills cannot trigger, so they
+// never collide.
+func (s *SkillService) FindCollisions(projectDir string) ([]Collision, error) {
+	skills, err := s.ListProjectSkills(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	return s.index.detectCollisions(skills), nil
+}
+
+// MarkSkillCollisions sets Conflicts on skills, as listed by the service,
+// like MarkCollisions. Collisions are only searched for again when the
+// enabled skills changed since the last call.
+func (s *SkillService) MarkSkillCollisions(skills []model.Skill) {
+	MarkCollisions(skills, s.index.detectCollisions(skills))
+}
+
+// DetectCollisions finds exact name collisions and near-duplicates among the
+// enabled skills.
+func DetectCollisions(skills []model.Skill) []Collision {
+	var enabled []model.Skill
+	for _, skill := range skills {
+		if skill.Enabled {
+			enabled = append(enabled, skill)
+		}
+	}
+
+	collisions := []Collision{}
+
+	// Exact collisions: one group per frontmatter name
+	byName := make(map[string][]model.Skill)
+	for _, skill := range enabled {
+		key := strings.ToLower(skill.Name)
+		byName[key] = append(byName[key], skill)
+	}
+	for _, group := range byName {
+		if len(group) > 1 {
+			collisions = append(collisions, newCollision(CollisionName, group[0].Name, 1, group))
+		}
+	}
+
+	// Near-duplicates: pairs, skipping pairs already reported above
+	for i := 0; i < len(enabled); i++ {
+		for j := i + 1; j < len(enabled); j++ {
+			a, b := enabled[i], enabled[j]
+			if strings.EqualFold(a.Name, b.Name) {
+				continue
+			}
+			pair := []model.Skill{a, b}
+			if sim := nameSimilarity(a.Name, b.Name); sim >= similarNameThreshold {
+				collisions = append(collisions, newCollision(CollisionSimilarName, a.Name, sim, pair))
+			} else if sim := descriptionSimilarity(a.Description, b.Description); sim >= similarDescriptionThreshold {
+				collisions = append(collisions, newCollision(CollisionSimilarDescription, a.Name, sim, pair))
+			}
+		}
+	}
+
+	sort.SliceStable(collisions, func(i, j int) bool {
+		if collisions[i].Similarity != collisions[j].Similarity {
+			return collisions[i].Similarity > collisions[j].Similarity
+		}
+		return collisions[i].Name < collisions[j].Name
+	})
+	return collisions
+}
+
+// MarkCollisions sets Conflicts on every skill that is part of a collision
+// to the IDs of the skills it collides with.
+func MarkCollisions(skills []model.Skill, collisions []Collision) {
+	conflicts := make(map[string][]string)
+	for _, c := range collisions {
+		for _, ref := range c.Skills {
+			for _, other := range c.Skills {
+				if other.ID != ref.ID {
+					conflicts[ref.ID] = append(conflicts[ref.ID], other.ID)
+				}
+			}
+		}
+	}
+	for i := range skills {
+		skills[i].Conflicts = conflicts[SkillID(skills[i])]
+	}
+}
+
+// SkillID is a stable identifier for a skill across sources, e.g.
+// "user/lint", "project/lint" or "plugin/tools/lint".
+func SkillID(skill model.Skill) string {
+	if skill.Source == "plugin" {
+		return "plugin/" + skill.PluginName + "/" + skill.FileName
+	}
+	return skill.Source + "/" + skill.FileName
+}
+
+func newCollision(kind, name string, similarity float64, skills []model.Skill) Collision {
+	c := Collision{Kind: kind, Name: name, Similarity: similarity}
+	for _, skill := range skills {
+		c.Skills = append(c.Skills, skillRef(skill))
+	}
+	c.Suggestions = suggestResolutions(skills)
+	return c
+}
+
+func skillRef(skill model.Skill) SkillRef {
+	return SkillRef{
+		ID:         SkillID(skill),
+		Name:       skill.Name,
+		FileName:   skill.FileName,
+		Source:     skill.Source,
+		PluginName: skill.PluginName,
+	}
+}
+
+// suggestResolutions prefers keeping the user's own skills: plugin skills
+// are suggested for disabling, and for forking when only plugins collide.
+func suggestResolutions(skills []model.Skill) []Suggestion {
+	hasOwn := false
+	for _, skill := range skills {
+		if skill.Source != "plugin" {
+			hasOwn = true
+		}
+	}
+
+	suggestions := []Suggestion{}
+	for _, skill := range skills {
+		id := SkillID(skill)
+		switch {
+		case skill.Source == "plugin" && hasOwn:
+			suggestions = append(suggestions, Suggestion{Action: "disable", Skill: id,
+				Reason: "keep your own skill and disable the plugin's"})
+		case skill.Source == "plugin":
+			suggestions = append(suggestions,
+				Suggestion{Action: "disable", Skill: id, Reason: "keep only the other plugin's skill"},
+				Suggestion{Action: "fork", Skill: id, Reason: fmt.Sprintf("copy %s under a distinct name and disable the original", skill.Name)})
+		default:
+			suggestions = append(suggestions,
+				Suggestion{Action: "rename", Skill: id, Reason: "give the skill a distinct name"},
+				Suggestion{Action: "disable", Skill: id, Reason: "keep only the other skill"})
+		}
+	}
+	return suggestions
+}
+
+// nameSimilarity compares names ignoring case and separators, as 1 minus
+// the edit distance relative to the longer name.
+func nameSimilarity(a, b string) float64 {
+	a, b = normalizeName(a), normalizeName(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	return 1 - float64(levenshtein(ra, rb))/float64(max(len(ra), len(rb)))
+}
+
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// descriptionSimilarity is the Jaccard index of the descriptions' word sets,
+// ignoring short words. Descriptions with too few words never match.
+func descriptionSimilarity(a, b string) float64 {
+	wa, wb := descriptionWords(a), descriptionWords(b)
+	if len(wa) < minDescriptionWords || len(wb) < minDescriptionWords {
+		return 0
+	}
+
+	shared := 0
+	for w := range wa {
+		if wb[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wa)+len(wb)-shared)
+}
+
+func descriptionWords(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(w) > 3 {
+			words[w] = true
+		}
+	}
+	return words
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/model"
+)
+
+func TestDetectCollisions(t *testing.T) {
+	skills := []model.Skill{
+		{Name: "lint", FileName: "lint", Source: "user", Enabled: true},
+		{Name: "lint", FileName: "lint", Source: "plugin", PluginName: "tools", Enabled: true},
+		{Name: "pdf-tools", FileName: "pdf-tools", Source: "user", Enabled: true},
+		{Name: "pdf_tool", FileName: "pdf_tool", Source: "plugin", PluginName: "docs", Enabled: true},
+		{Name: "review", Description: "Review pull requests for style and correctness issues", FileName: "review", Source: "user", Enabled: true},
+		{Name: "pr-check", Description: "Review pull requests for correctness and style", FileName: "pr-check", Source: "project", Enabled: true},
+		{Name: "lint", FileName: "lint-old", Source: "user", Enabled: false},
+		{Name: "deploy", Description: "Deploy the app", FileName: "deploy", Source: "user", Enabled: true},
+	}
+
+	collisions := DetectCollisions(skills)
+
+	kinds := make(map[string]Collision)
+	for _, c := range collisions {
+		kinds[c.Kind] = c
+	}
+	if len(collisions) != 3 {
+		t.Fatalf("expected 3 collisions, got %d: %+v", len(collisions), collisions)
+	}
+
+	name := kinds[CollisionName]
+	if len(name.Skills) != 2 {
+		t.Errorf("disabled skills should not collide, got %+v", name.Skills)
+	}
+	if len(name.Suggestions) != 3 || name.Suggestions[2].Action != "disable" || name.Suggestions[2].Skill != "plugin/tools/lint" {
+		t.Errorf("expected the plugin skill to be suggested for disabling, got %+v", name.Suggestions)
+	}
+	if c := kinds[CollisionSimilarName]; c.Skills[0].FileName != "pdf-tools" {
+		t.Errorf("expected pdf-tools to be a near-duplicate, got %+v", c)
+	}
+	if c := kinds[CollisionSimilarDescription]; c.Skills[1].ID != "project/pr-check" {
+		t.Errorf("expected pr-check to be a near-duplicate, got %+v", c)
+	}
+
+	MarkCollisions(skills, collisions)
+	if len(skills[0].Conflicts) != 1 || skills[0].Conflicts[0] != "plugin/tools/lint" {
+		t.Errorf("expected user lint to be marked, got %v", skills[0].Conflicts)
+	}
+	if skills[7].Conflicts != nil {
+		t.Errorf("expected deploy to have no conflicts, got %v", skills[7].Conflicts)
+	}
+}
+
+func TestFindCollisions_IgnoresDisabledPluginSkill(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	setupSkill(t, filepath.Join(tmpDir, "skills"), "lint")
+	pluginSkill := filepath.Join(tmpDir, "plugins", "cache", "acme", "tools", "1.0.0", "skills", "lint")
+	os.MkdirAll(pluginSkill, 0755)
+	os.WriteFile(filepath.Join(pluginSkill, "SKILL.md"), []byte("---\nname: lint\ndescription: Lint\n---\n"), 0644)
+
+	svc := NewSkillService(tmpDir)
+	collisions, err := svc.FindCollisions("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(collisions) != 1 {
+		t.Fatalf("expected 1 collision, got %+v", collisions)
+	}
+
+	config.DisablePluginSkill("tools", "lint")
+	if collisions, _ = svc.FindCollisions(""); len(collisions) != 0 {
+		t.Errorf("expected no collisions once the plugin skill is disabled, got %+v", collisions)
+	}
+}
+
+func TestMarkSkillCollisions_FollowsChanges(t *testing.T) {
+	svc := NewSkillService(t.TempDir())
+	skills := []model.Skill{
+		{Name: "lint", FileName: "lint", Source: "user", Enabled: true},
+		{Name: "lint", FileName: "lint", Source: "plugin", PluginName: "tools", Enabled: true},
+	}
+
+	svc.MarkSkillCollisions(skills)
+	if len(skills[0].Conflicts) != 1 {
+		t.Fatalf("expected a conflict, got %v", skills[0].Conflicts)
+	}
+	// The cached result is reused for the same skills
+	svc.MarkSkillCollisions(skills)
+	if len(skills[0].Conflicts) != 1 {
+		t.Fatalf("expected the conflict again, got %v", skills[0].Conflicts)
+	}
+
+	skills[1].Enabled = false
+	svc.MarkSkillCollisions(skills)
+	if skills[0].Conflicts != nil {
+		t.Errorf("expected no conflict once the plugin skill is disabled, got %v", skills[0].Conflicts)
+	}
+	skills[1].Enabled, skills[1].Name = true, "format"
+	svc.MarkSkillCollisions(skills)
+	if skills[0].Conflicts != nil {
+		t.Errorf("expected no conflict after a rename, got %v", skills[0].Conflicts)
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/wind/skill-router/internal/history"
+	"github.com/wind/skill-router/internal/parser"
+)
+
+var (
+	ErrSkillNotFound = fmt.Errorf("skill %w", ErrNotFound)
+	ErrStaleContent  = errors.New("skill was changed since it was read")
+)
+
+// ContentETag is the entity tag of a SKILL.md: a hash of its content.
+func ContentETag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// ReadSkillContent returns the raw SKILL.md of a user skill, enabled or
+// disabled.
+func (s *SkillService) ReadSkillContent(dirName string) ([]byte, error) {
+	skillDir, err := s.findUserSkill(dirName)
+	if err != nil {
+		return nil, err
+	}
+	return readSkillMarkdown(skillDir)
+}
+
+// ReadPluginSkillContent returns the raw SKILL.md of a plugin skill.
+func (s *SkillService) ReadPluginSkillContent(pluginName, skillName string) ([]byte, error) {
+	skill, err := s.findPluginSkill(pluginName, skillName)
+	if err != nil {
+		return nil, err
+	}
+	return readSkillMarkdown(skill.FilePath)
+}
+
+// WriteSkillContent replaces the SKILL.md of a user skill if its current
+// ETag still equals ifMatch, or ifMatch is "*", and returns the new ETag.
+// The frontmatter must be valid and have a usable name, which may differ
+// from the skill's directory.
+func (s *SkillService) WriteSkillContent(dirName string, content []byte, ifMatch string) (string, error) {
+	fm, err := parser.ValidateFrontmatter(string(content))
+	if err != nil {
+		return "", &kindError{kind: ErrInvalidFrontmatter, err: err}
+	}
+	if !validDirName(fm.Name) {
+		return "", fmt.Errorf("%w: invalid name %q", ErrInvalidFrontmatter, fm.Name)
+	}
+
+	var check func(current []byte) error
+	if ifMatch != "*" {
+		check = func(current []byte) error {
+			if ContentETag(current) != ifMatch {
+				return ErrStaleContent
+			}
+			return nil
+		}
+	}
+
+	s.mu.Lock()
+	previous, err := s.writeSkillContent(dirName, content, check)
+	s.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	s.record(history.Operation{Type: history.OpWriteSkill, Target: dirName, Content: content, Previous: previous})
+	return ContentETag(content), nil
+}
+
+// writeSkillContent replaces a user skill's SKILL.md, if check accepts its
+// current content, and returns what it held before. check may be nil. The
+// caller holds s.mu.
+func (s *SkillService) writeSkillContent(dirName string, content []byte, check func(current []byte) error) ([]byte, error) {
+	skillDir, err := s.findUserSkill(dirName)
+	if err != nil {
+		return nil, err
+	}
+	current, err := readSkillMarkdown(skillDir)
+	if err != nil {
+		return nil, err
+	}
+	if check != nil {
+		if err := check(current); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.WriteFile(skillFilePath(skillDir), content, 0644); err != nil {
+		return nil, err
+	}
+	s.index.invalidate(skillDir)
+	return current, nil
+}
+
+// findUserSkill returns the directory of a user skill in either the enabled
+// or disabled location.
+func (s *SkillService) findUserSkill(dirName string) (string, error) {
+	if !validDirName(dirName) {
+		return "", fmt.Errorf("%w: %s", ErrSkillNotFound, dirName)
+	}
+	for _, dir := range []string{s.enabledDir, s.disabledDir} {
+		skillDir := filepath.Join(dir, dirName)
+		if info, err := os.Stat(skillDir); err == nil && info.IsDir() {
+			return skillDir, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrSkillNotFound, dirName)
+}
+
+// skillFilePath returns the SKILL.md of a skill directory, falling back to
+// the lowercase skill.md if that is what the skill uses.
+func skillFilePath(skillDir string) string {
+	skillFile := filepath.Join(skillDir, "SKILL.md")
+	if _, err := os.Stat(skillFile); err != nil {
+		if lower := filepath.Join(skillDir, "skill.md"); fileExists(lower) {
+			return lower
+		}
+	}
+	return skillFile
+}
+
+func readSkillMarkdown(skillDir string) ([]byte, error) {
+	content, err := os.ReadFile(skillFilePath(skillDir))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s has no SKILL.md", ErrSkillNotFound, filepath.Base(skillDir))
+	}
+	return content, err
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func validDirName(name string) bool {
+	return name != "" && name != "." && name != ".." && filepath.Base(name) == name && !filepath.IsAbs(name)
+}
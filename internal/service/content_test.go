@@ -0,0 +1,67 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteSkillContent(t *testing.T) {
+	tmpDir := t.TempDir()
+	skillDir := filepath.Join(tmpDir, "skills-disabled", "my-skill")
+	os.MkdirAll(skillDir, 0755)
+	original := []byte("---\nname: my-skill\ndescription: Test\n---\nOld")
+	os.WriteFile(filepath.Join(skillDir, "SKILL.md"), original, 0644)
+
+	svc := NewSkillService(tmpDir)
+
+	content, err := svc.ReadSkillContent("my-skill")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	etag := ContentETag(content)
+
+	updated := []byte("---\nname: my-skill\ndescription: Test\n---\nNew")
+	newETag, err := svc.WriteSkillContent("my-skill", updated, etag)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if newETag != ContentETag(updated) {
+		t.Errorf("unexpected etag %s", newETag)
+	}
+
+	// The old ETag is now stale.
+	if _, err := svc.WriteSkillContent("my-skill", original, etag); !errors.Is(err, ErrStaleContent) {
+		t.Errorf("expected ErrStaleContent, got %v", err)
+	}
+
+	// Invalid frontmatter is rejected before anything is written.
+	for _, bad := range []string{
+		"no frontmatter",
+		"---\nname: my-skill\n---\nNo description",
+		"---\nname: ../other\ndescription: Test\n---\n",
+	} {
+		if _, err := svc.WriteSkillContent("my-skill", []byte(bad), newETag); err == nil {
+			t.Errorf("expected validation error for %q", bad)
+		}
+	}
+
+	got, _ := os.ReadFile(filepath.Join(skillDir, "SKILL.md"))
+	if string(got) != string(updated) {
+		t.Errorf("unexpected content on disk: %q", got)
+	}
+
+	// The name may differ from the directory, and "*" matches any content
+	renamed := []byte("---\nname: My Skill\ndescription: Test\n---\nNew")
+	if _, err := svc.WriteSkillContent("my-skill", renamed, "*"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(skillDir, "SKILL.md")); string(got) != string(renamed) {
+		t.Errorf("unexpected content on disk: %q", got)
+	}
+
+	if _, err := svc.ReadSkillContent("../escape"); !errors.Is(err, ErrSkillNotFound) {
+		t.Errorf("expected ErrSkillNotFound for path traversal, got %v", err)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !(unix || windows)
+
+package service
+
+// Other platforms report no distinct error for a cross-device rename, so
+// moves there never fall back to copying.
+
+func isCrossDevice(err error) bool { return false }
@@ -0,0 +1,16 @@
+//go:build windows
+
+package service
+
+import (
+	"errors"
+	"syscall"
+)
+
+// errorNotSameDevice is ERROR_NOT_SAME_DEVICE, which MoveFileEx returns for
+// a move to another volume.
+const errorNotSameDevice syscall.Errno = 17
+
+func isCrossDevice(err error) bool {
+	return errors.Is(err, errorNotSameDevice)
+}
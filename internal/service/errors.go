@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/wind/skill-router/internal/github"
+)
+
+// Kinds of service errors. Specific errors wrap one of them, so callers can
+// tell what went wrong with errors.Is without knowing every sentinel.
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrAlreadyExists      = errors.New("already exists")
+	ErrInvalidName        = errors.New("invalid name")
+	ErrInvalidFrontmatter = errors.New("invalid frontmatter")
+	ErrInvalidRequest     = errors.New("invalid request")
+	ErrUpstream           = errors.New("upstream failure")
+	ErrRateLimited        = errors.New("rate limited")
+)
+
+var ErrPluginNotFound = fmt.Errorf("plugin %w", ErrNotFound)
+
+func invalidSkillName(name string) error {
+	return fmt.Errorf("%w: skill %q", ErrInvalidName, name)
+}
+
+func invalidPluginName(name string) error {
+	return fmt.Errorf("%w: plugin %q", ErrInvalidName, name)
+}
+
+// kindError marks err as one of the kinds above without changing its
+// message.
+type kindError struct {
+	kind, err error
+}
+
+func (e *kindError) Error() string   { return e.err.Error() }
+func (e *kindError) Unwrap() []error { return []error{e.kind, e.err} }
+
+// githubError sorts an error from the GitHub client into a service error
+// kind. Anything it does not recognize is an upstream failure.
+func githubError(err error) error {
+	kind := ErrUpstream
+	switch {
+	case errors.Is(err, github.ErrRateLimited):
+		kind = ErrRateLimited
+	case errors.Is(err, github.ErrNotFound):
+		kind = ErrNotFound
+	case errors.Is(err, github.ErrInvalidURL):
+		kind = ErrInvalidRequest
+	}
+	return &kindError{kind: kind, err: err}
+}
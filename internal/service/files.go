@@ -0,0 +1,293 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wind/skill-router/internal/history"
+)
+
+var ErrInvalidPath error = &kindError{kind: ErrInvalidRequest, err: errors.New("invalid file path")}
+
+// SkillFile describes a file or directory inside a skill directory. Path is
+// relative to the skill directory and always uses forward slashes.
+type SkillFile struct {
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	Mode       string    `json:"mode"`
+	IsDir      bool      `json:"isDir"`
+	Executable bool      `json:"executable"`
+	ModTime    time.Time `json:"modTime"`
+}
+
+// ListSkillFiles lists everything inside a user skill's directory.
+func (s *SkillService) ListSkillFiles(dirName string) ([]SkillFile, error) {
+	root, err := s.openSkillRoot(dirName)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	files := []SkillFile{}
+	err = fs.WalkDir(root.FS(), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == "." {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, skillFileInfo(p, info))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// ReadSkillFile reads a file inside a user skill's directory.
+func (s *SkillService) ReadSkillFile(dirName, filePath string) ([]byte, error) {
+	name, err := localPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := s.openSkillRoot(dirName)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	return root.ReadFile(name)
+}
+
+// WriteSkillFile creates or replaces a file inside a user skill's directory,
+// creating parent directories as needed. SKILL.md itself must be changed
+// through WriteSkillContent so its frontmatter is validated.
+func (s *SkillService) WriteSkillFile(dirName, filePath string, content []byte) (SkillFile, error) {
+	name, err := localPath(filePath)
+	if err != nil {
+		return SkillFile{}, err
+	}
+	if isSkillMarkdown(name) {
+		return SkillFile{}, fmt.Errorf("%w: use the content endpoint to edit %s", ErrInvalidPath, filePath)
+	}
+
+	s.mu.Lock()
+	file, previous, err := s.writeSkillFile(dirName, name, content)
+	s.mu.Unlock()
+	if err != nil {
+		return SkillFile{}, err
+	}
+
+	if previous == nil {
+		s.record(history.Operation{Type: history.OpCreateFile, Target: dirName, Path: file.Path, Content: content})
+	} else {
+		s.record(history.Operation{Type: history.OpWriteFile, Target: dirName, Path: file.Path, Content: content, Previous: previous})
+	}
+	return file, nil
+}
+
+// writeSkillFile writes a file and returns what it held before, or nil if
+// it did not exist. The caller holds s.mu.
+func (s *SkillService) writeSkillFile(dirName, name string, content []byte) (SkillFile, []byte, error) {
+	root, err := s.openSkillRoot(dirName)
+	if err != nil {
+		return SkillFile{}, nil, err
+	}
+	defer root.Close()
+
+	if dir := filepath.Dir(name); dir != "." {
+		if err := root.MkdirAll(dir, 0755); err != nil {
+			return SkillFile{}, nil, err
+		}
+	}
+
+	// Keep the mode of an existing file, e.g. the executable bit of a script
+	perm := os.FileMode(0644)
+	var previous []byte
+	if info, err := root.Stat(name); err == nil {
+		if info.IsDir() {
+			return SkillFile{}, nil, fmt.Errorf("%w: %s is a directory", ErrInvalidPath, filepath.ToSlash(name))
+		}
+		perm = info.Mode().Perm()
+		if previous, err = root.ReadFile(name); err != nil {
+			return SkillFile{}, nil, err
+		}
+		if previous == nil {
+			previous = []byte{}
+		}
+	}
+
+	if err := root.WriteFile(name, content, perm); err != nil {
+		return SkillFile{}, nil, err
+	}
+
+	info, err := root.Stat(name)
+	if err != nil {
+		return SkillFile{}, nil, err
+	}
+	return skillFileInfo(filepath.ToSlash(name), info), previous, nil
+}
+
+// DeleteSkillFile moves a file or directory inside a user skill's directory
+// into the trash. SKILL.md cannot be removed; delete the skill instead.
+func (s *SkillService) DeleteSkillFile(dirName, filePath string) error {
+	name, err := localPath(filePath)
+	if err != nil {
+		return err
+	}
+	if isSkillMarkdown(name) {
+		return fmt.Errorf("%w: cannot delete %s", ErrInvalidPath, filePath)
+	}
+
+	s.mu.Lock()
+	trashID, err := s.trashSkillFile(dirName, name)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.record(history.Operation{Type: history.OpDeleteFile, Target: dirName, Path: filepath.ToSlash(name), TrashID: trashID})
+	return nil
+}
+
+// trashSkillFile moves a file or directory inside a user skill into the
+// trash. The caller holds s.mu.
+func (s *SkillService) trashSkillFile(dirName, name string) (string, error) {
+	root, err := s.openSkillRoot(dirName)
+	if err != nil {
+		return "", err
+	}
+	// Lstat through the root fails for paths that leave the skill directory
+	_, err = root.Lstat(name)
+	skillDir := root.Name()
+	root.Close()
+	if err != nil {
+		return "", err
+	}
+
+	return s.moveToTrash(filepath.Join(skillDir, name), TrashItem{
+		Kind:    TrashFile,
+		Name:    dirName + "/" + filepath.ToSlash(name),
+		Source:  "user",
+		Enabled: filepath.Dir(skillDir) == s.enabledDir,
+	})
+}
+
+// SetSkillFileExecutable sets or clears the executable bits of a file inside
+// a user skill's directory.
+func (s *SkillService) SetSkillFileExecutable(dirName, filePath string, executable bool) (SkillFile, error) {
+	name, err := localPath(filePath)
+	if err != nil {
+		return SkillFile{}, err
+	}
+
+	s.mu.Lock()
+	file, changed, err := s.setSkillFileExecutable(dirName, name, executable)
+	s.mu.Unlock()
+	if err != nil {
+		return SkillFile{}, err
+	}
+
+	if changed {
+		s.record(history.Operation{Type: history.OpChmodFile, Target: dirName, Path: file.Path, Enabled: executable})
+	}
+	return file, nil
+}
+
+// setSkillFileExecutable changes the mode of a file and reports whether it
+// changed. The caller holds s.mu.
+func (s *SkillService) setSkillFileExecutable(dirName, name string, executable bool) (SkillFile, bool, error) {
+	root, err := s.openSkillRoot(dirName)
+	if err != nil {
+		return SkillFile{}, false, err
+	}
+	defer root.Close()
+
+	info, err := root.Stat(name)
+	if err != nil {
+		return SkillFile{}, false, err
+	}
+	if info.IsDir() {
+		return SkillFile{}, false, fmt.Errorf("%w: %s is a directory", ErrInvalidPath, filepath.ToSlash(name))
+	}
+
+	previous := info.Mode().Perm()
+	mode := previous
+	if executable {
+		// Grant execute wherever read is granted, like chmod +x
+		mode |= (mode & 0444) >> 2
+	} else {
+		mode &^= 0111
+	}
+	if err := root.Chmod(name, mode); err != nil {
+		return SkillFile{}, false, err
+	}
+
+	if info, err = root.Stat(name); err != nil {
+		return SkillFile{}, false, err
+	}
+	return skillFileInfo(filepath.ToSlash(name), info), mode != previous, nil
+}
+
+// removeSkillFile removes a file that an undone write created. The caller
+// holds s.mu.
+func (s *SkillService) removeSkillFile(dirName, name string) error {
+	root, err := s.openSkillRoot(dirName)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	return root.Remove(name)
+}
+
+// openSkillRoot opens a user skill's directory as an os.Root so that no
+// operation can reach outside of it, through ".." or through symlinks.
+func (s *SkillService) openSkillRoot(dirName string) (*os.Root, error) {
+	skillDir, err := s.findUserSkill(dirName)
+	if err != nil {
+		return nil, err
+	}
+	return os.OpenRoot(skillDir)
+}
+
+// localPath turns a slash-separated path from a URL into a local path that
+// stays inside the directory it is resolved against.
+func localPath(p string) (string, error) {
+	p = strings.Trim(p, "/")
+	if p == "" || !fs.ValidPath(p) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPath, p)
+	}
+	name := filepath.FromSlash(p)
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPath, p)
+	}
+	return name, nil
+}
+
+func isSkillMarkdown(name string) bool {
+	return strings.EqualFold(name, "SKILL.md")
+}
+
+func skillFileInfo(p string, info os.FileInfo) SkillFile {
+	return SkillFile{
+		Path:       path.Clean(p),
+		Size:       info.Size(),
+		Mode:       info.Mode().String(),
+		IsDir:      info.IsDir(),
+		Executable: !info.IsDir() && info.Mode().Perm()&0111 != 0,
+		ModTime:    info.ModTime(),
+	}
+}
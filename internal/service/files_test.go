@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSkillFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	skillDir := filepath.Join(tmpDir, "skills", "my-skill")
+	os.MkdirAll(skillDir, 0755)
+	os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: my-skill\ndescription: Test\n---\n"), 0644)
+
+	svc := NewSkillService(tmpDir)
+
+	if _, err := svc.WriteSkillFile("my-skill", "scripts/run.sh", []byte("#!/bin/sh\necho hi\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	file, err := svc.SetSkillFileExecutable("my-skill", "scripts/run.sh", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !file.Executable {
+		t.Error("expected run.sh to be executable")
+	}
+
+	// Rewriting keeps the executable bit.
+	file, err = svc.WriteSkillFile("my-skill", "scripts/run.sh", []byte("#!/bin/sh\necho bye\n"))
+	if err != nil || !file.Executable {
+		t.Errorf("expected rewrite to keep mode, got %+v, %v", file, err)
+	}
+
+	files, err := svc.ListSkillFiles("my-skill")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	paths := map[string]bool{}
+	for _, f := range files {
+		paths[f.Path] = true
+	}
+	for _, want := range []string{"SKILL.md", "scripts", "scripts/run.sh"} {
+		if !paths[want] {
+			t.Errorf("expected %s in listing, got %v", want, files)
+		}
+	}
+
+	content, err := svc.ReadSkillFile("my-skill", "scripts/run.sh")
+	if err != nil || string(content) != "#!/bin/sh\necho bye\n" {
+		t.Errorf("unexpected content %q, %v", content, err)
+	}
+
+	if err := svc.DeleteSkillFile("my-skill", "scripts"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(skillDir, "scripts")); !os.IsNotExist(err) {
+		t.Error("scripts directory should be deleted")
+	}
+}
+
+func TestSkillFiles_ConfinedToSkillDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	skillDir := filepath.Join(tmpDir, "skills", "my-skill")
+	os.MkdirAll(skillDir, 0755)
+	os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: my-skill\ndescription: Test\n---\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "secret"), []byte("secret"), 0644)
+	os.Symlink(tmpDir, filepath.Join(skillDir, "link"))
+
+	svc := NewSkillService(tmpDir)
+
+	for _, p := range []string{"../secret", "../../secret", "scripts/../../secret"} {
+		if _, err := svc.ReadSkillFile("my-skill", p); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("expected ErrInvalidPath for %s, got %v", p, err)
+		}
+	}
+	if _, err := svc.ReadSkillFile("my-skill", "link/secret"); err == nil {
+		t.Error("expected reading through a symlink out of the skill to fail")
+	}
+	if _, err := svc.WriteSkillFile("my-skill", "link/evil", []byte("x")); err == nil {
+		t.Error("expected writing through a symlink out of the skill to fail")
+	}
+	if _, err := svc.WriteSkillFile("my-skill", "SKILL.md", []byte("x")); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected SKILL.md writes to be refused, got %v", err)
+	}
+	if err := svc.DeleteSkillFile("my-skill", "SKILL.md"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected SKILL.md delete to be refused, got %v", err)
+	}
+}
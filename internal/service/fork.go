@@ -0,0 +1,159 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/history"
+	"github.com/wind/skill-router/internal/model"
+)
+
+// originFile is written into a forked skill's directory to remember where it
+// was copied from.
+const originFile = ".skill-origin.json"
+
+// SkillOrigin records the plugin skill a user skill was forked from, so it
+// can later be compared with upstream changes.
+type SkillOrigin struct {
+	Plugin   string    `json:"plugin"`
+	Skill    string    `json:"skill"`
+	Version  string    `json:"version"`
+	Path     string    `json:"path"`
+	ForkedAt time.Time `json:"forkedAt"`
+}
+
+// ForkPluginSkill copies a plugin skill's whole directory into the user
+// skills directory as newName (the plugin skill's own name if empty) and
+// records its origin. If disableOriginal is set, the plugin skill is
+// disabled so only the fork loads.
+func (s *SkillService) ForkPluginSkill(pluginName, skillName, newName string, disableOriginal bool) (*SkillOrigin, error) {
+	plugin, err := s.findPluginSkill(pluginName, skillName)
+	if err != nil {
+		return nil, err
+	}
+	if newName == "" {
+		newName = skillName
+	}
+	if !validDirName(newName) {
+		return nil, invalidSkillName(newName)
+	}
+
+	origin, err := s.forkPluginSkill(plugin, newName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Disabling the original is part of the fork, so it is recorded with it
+	// rather than as a separate overrides change
+	op := history.Operation{Type: history.OpCreateSkill, Target: newName}
+	if disableOriginal {
+		op.Change, err = disablePluginSkill(pluginName, skillName)
+	}
+	s.record(op)
+	if err != nil {
+		return nil, err
+	}
+	return origin, nil
+}
+
+// disablePluginSkill disables a plugin skill without reporting the change
+// to the change hook and returns the change made, if any.
+func disablePluginSkill(pluginName, skillName string) (*config.OverridesChange, error) {
+	overrides, err := config.LoadOverrides()
+	if err != nil {
+		return nil, err
+	}
+	key := pluginName + ":" + skillName
+	if slices.Contains(overrides.Disabled, key) {
+		return nil, nil
+	}
+	change := config.OverridesChange{AddedDisabled: []string{key}}
+	if err := config.ApplyOverridesChange(change); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (s *SkillService) forkPluginSkill(plugin *model.Skill, newName string) (*SkillOrigin, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkNameFree(newName); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(s.enabledDir, 0755); err != nil {
+		return nil, err
+	}
+
+	dst := filepath.Join(s.enabledDir, newName)
+	origin := &SkillOrigin{
+		Plugin: plugin.PluginName,
+		Skill:  plugin.FileName,
+		// plugins/cache/<org>/<plugin>/<version>/skills/<skill>
+		Version:  filepath.Base(filepath.Dir(filepath.Dir(plugin.FilePath))),
+		Path:     plugin.FilePath,
+		ForkedAt: time.Now().UTC(),
+	}
+
+	if err := s.copyFork(plugin.FilePath, dst, newName, origin); err != nil {
+		os.RemoveAll(dst)
+		return nil, err
+	}
+	return origin, nil
+}
+
+func (s *SkillService) copyFork(src, dst, name string, origin *SkillOrigin) error {
+	if err := copyDir(src, dst); err != nil {
+		return err
+	}
+	if err := setSkillName(dst, name); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(origin, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dst, originFile), data, 0644)
+}
+
+// ReadSkillOrigin returns where a user skill was forked from, or nil if it
+// was not forked from a plugin skill.
+func (s *SkillService) ReadSkillOrigin(dirName string) (*SkillOrigin, error) {
+	skillDir, err := s.findUserSkill(dirName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(skillDir, originFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var origin SkillOrigin
+	if err := json.Unmarshal(data, &origin); err != nil {
+		return nil, err
+	}
+	return &origin, nil
+}
+
+func (s *SkillService) findPluginSkill(pluginName, skillName string) (*model.Skill, error) {
+	skills, err := s.scanPlugins()
+	if err != nil {
+		return nil, err
+	}
+	for _, skill := range skills {
+		if skill.PluginName == pluginName && skill.FileName == skillName {
+			return &skill, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s:%s", ErrSkillNotFound, pluginName, skillName)
+}
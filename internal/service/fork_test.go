@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wind/skill-router/internal/config"
+)
+
+func TestForkPluginSkill(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	pluginSkill := filepath.Join(tmpDir, "plugins", "cache", "acme", "tools", "1.2.0", "skills", "lint")
+	os.MkdirAll(filepath.Join(pluginSkill, "scripts"), 0755)
+	os.WriteFile(filepath.Join(pluginSkill, "SKILL.md"), []byte("---\nname: lint\ndescription: Lint code\n---\nRun it"), 0644)
+	os.WriteFile(filepath.Join(pluginSkill, "scripts", "lint.sh"), []byte("#!/bin/sh\n"), 0755)
+
+	svc := NewSkillService(tmpDir)
+
+	if _, err := svc.ForkPluginSkill("tools", "missing", "", false); !errors.Is(err, ErrSkillNotFound) {
+		t.Errorf("expected ErrSkillNotFound, got %v", err)
+	}
+
+	origin, err := svc.ForkPluginSkill("tools", "lint", "my-lint", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if origin.Plugin != "tools" || origin.Skill != "lint" || origin.Version != "1.2.0" {
+		t.Errorf("unexpected origin: %+v", origin)
+	}
+
+	forkDir := filepath.Join(tmpDir, "skills", "my-lint")
+	if got := frontmatterName(t, forkDir); got != "my-lint" {
+		t.Errorf("expected frontmatter name my-lint, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(forkDir, "scripts", "lint.sh")); err != nil {
+		t.Errorf("expected supporting files to be copied: %v", err)
+	}
+
+	stored, err := svc.ReadSkillOrigin("my-lint")
+	if err != nil || stored == nil || stored.Version != "1.2.0" {
+		t.Errorf("expected stored origin, got %+v, %v", stored, err)
+	}
+	if !config.IsPluginSkillDisabled("tools", "lint") {
+		t.Error("expected original plugin skill to be disabled")
+	}
+
+	if _, err := svc.ForkPluginSkill("tools", "lint", "my-lint", false); !errors.Is(err, ErrSkillExists) {
+		t.Errorf("expected ErrSkillExists, got %v", err)
+	}
+}
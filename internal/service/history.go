@@ -0,0 +1,293 @@
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/history"
+)
+
+var ErrHistoryDisabled = errors.New("history is not enabled")
+
+// EnableHistory records every operation made through the service, and every
+// change to the global overrides, in j so they can be undone and redone.
+func (s *SkillService) EnableHistory(j *history.Journal) {
+	s.journal = j
+	s.watchOverrides()
+}
+
+// watchOverrides records changes to the global overrides made through
+// config, which the service does not see otherwise.
+func (s *SkillService) watchOverrides() {
+	config.SetChangeHook(func(c config.OverridesChange) {
+		s.record(history.Operation{Type: history.OpOverrides, Change: &c})
+	})
+}
+
+// History returns the journal, oldest entry first, with how many entries
+// can currently be undone and redone.
+func (s *SkillService) History() (entries []history.Entry, undo, redo int, err error) {
+	if s.journal == nil {
+		return nil, 0, 0, ErrHistoryDisabled
+	}
+
+	entries, err = s.journal.Entries()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	undoStack, redoStack := history.Stacks(entries)
+	return entries, len(undoStack), len(redoStack), nil
+}
+
+// Undo reverts the most recent operation that has not been undone yet.
+func (s *SkillService) Undo() (*history.Entry, error) {
+	return s.step(history.KindUndo)
+}
+
+// Redo reapplies the most recently undone operation.
+func (s *SkillService) Redo() (*history.Entry, error) {
+	return s.step(history.KindRedo)
+}
+
+func (s *SkillService) step(kind string) (*history.Entry, error) {
+	if s.journal == nil {
+		return nil, ErrHistoryDisabled
+	}
+
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	entries, err := s.journal.Entries()
+	if err != nil {
+		return nil, err
+	}
+	undo, redo := history.Stacks(entries)
+
+	stack, apply, empty := undo, s.revert, history.ErrNothingToUndo
+	if kind == history.KindRedo {
+		stack, apply, empty = redo, s.reapply, history.ErrNothingToRedo
+	}
+	if len(stack) == 0 {
+		return nil, empty
+	}
+	top := stack[len(stack)-1]
+
+	op, err := s.journal.Load(top.Op)
+	if err != nil {
+		return nil, err
+	}
+	op, err = apply(op)
+	if err != nil {
+		return nil, err
+	}
+	e, err := s.journal.Append(kind, top.Seq, op)
+	if err != nil {
+		return nil, err
+	}
+	if kind == history.KindUndo {
+		s.commitVersion("Undo: " + top.Op.String())
+	} else {
+		s.commitVersion("Redo: " + top.Op.String())
+	}
+	return &e, nil
+}
+
+// revert undoes op without recording it and returns op updated with what a
+// redo needs, such as the trash item a created skill went to.
+func (s *SkillService) revert(op history.Operation) (history.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.revertOp(op)
+}
+
+// revertOp is revert for a caller that holds s.mu.
+func (s *SkillService) revertOp(op history.Operation) (history.Operation, error) {
+	var err error
+	switch op.Type {
+	case history.OpEnableSkill:
+		err = s.moveSkill(op.Target, s.enabledDir, s.disabledDir)
+	case history.OpDisableSkill:
+		err = s.moveSkill(op.Target, s.disabledDir, s.enabledDir)
+	case history.OpDeleteSkill, history.OpDeletePlugin, history.OpDeleteFile:
+		_, err = s.restoreTrash(op.TrashID)
+	case history.OpRestoreSkill, history.OpRestorePlugin, history.OpRestoreFile:
+		op.TrashID, err = s.retrash(op)
+	case history.OpCreateSkill:
+		var skillDir string
+		if skillDir, err = s.findUserSkill(op.Target); err == nil {
+			op.TrashID, err = s.trashSkill(skillDir)
+		}
+	case history.OpWriteSkill:
+		_, err = s.writeSkillContent(op.Target, op.Previous, nil)
+	case history.OpRenameSkill:
+		err = s.renameSkill(op.NewName, op.Target)
+	case history.OpRevertSkill:
+		op.TrashID, err = s.unrevertSkill(op)
+	case history.OpCreateFile:
+		err = s.removeSkillFile(op.Target, filepath.FromSlash(op.Path))
+	case history.OpWriteFile:
+		_, _, err = s.writeSkillFile(op.Target, filepath.FromSlash(op.Path), op.Previous)
+	case history.OpChmodFile:
+		_, _, err = s.setSkillFileExecutable(op.Target, filepath.FromSlash(op.Path), !op.Enabled)
+	case history.OpBulk:
+		steps := slices.Clone(op.Steps)
+		for i := len(steps) - 1; i >= 0 && err == nil; i-- {
+			steps[i], err = s.revertOp(steps[i])
+		}
+		op.Steps = steps
+	case history.OpOverrides:
+		// The change itself is applied below
+	default:
+		err = errors.New("unknown operation: " + op.Type)
+	}
+	// Overrides changed as part of an operation are undone with it
+	if err == nil && op.Change != nil {
+		err = config.ApplyOverridesChange(op.Change.Inverse())
+	}
+	return op, err
+}
+
+// reapply redoes op without recording it and returns op updated with what
+// the next undo needs.
+func (s *SkillService) reapply(op history.Operation) (history.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reapplyOp(op)
+}
+
+// reapplyOp is reapply for a caller that holds s.mu.
+func (s *SkillService) reapplyOp(op history.Operation) (history.Operation, error) {
+	var err error
+	switch op.Type {
+	case history.OpEnableSkill:
+		err = s.moveSkill(op.Target, s.disabledDir, s.enabledDir)
+	case history.OpDisableSkill:
+		err = s.moveSkill(op.Target, s.enabledDir, s.disabledDir)
+	case history.OpDeleteSkill:
+		var skillDir string
+		if skillDir, err = s.findUserSkill(op.Target); err == nil {
+			op.Enabled = filepath.Dir(skillDir) == s.enabledDir
+			var trashID string
+			if trashID, err = s.trashSkill(skillDir); err == nil {
+				op.TrashID, err = s.keepTrashID(trashID, op.TrashID)
+			}
+		}
+	case history.OpDeletePlugin:
+		var trashID string
+		if trashID, err = s.trashPlugin(op.Target); err == nil {
+			op.TrashID, err = s.keepTrashID(trashID, op.TrashID)
+		}
+	case history.OpDeleteFile:
+		var trashID string
+		if trashID, err = s.trashSkillFile(op.Target, filepath.FromSlash(op.Path)); err == nil {
+			op.TrashID, err = s.keepTrashID(trashID, op.TrashID)
+		}
+	case history.OpRestoreSkill, history.OpRestorePlugin, history.OpRestoreFile:
+		_, err = s.restoreTrash(op.TrashID)
+	case history.OpCreateSkill:
+		_, err = s.restoreTrash(op.TrashID)
+		op.TrashID = ""
+	case history.OpWriteSkill:
+		_, err = s.writeSkillContent(op.Target, op.Content, nil)
+	case history.OpRenameSkill:
+		err = s.renameSkill(op.Target, op.NewName)
+	case history.OpRevertSkill:
+		if op.TrashID != "" {
+			_, err = s.restoreTrash(op.TrashID)
+			op.TrashID = ""
+		} else if _, err = s.versionPaths(op.Target); err == nil {
+			op.Saved, err = s.revertSkill(op.Target, op.Version)
+		}
+	case history.OpCreateFile, history.OpWriteFile:
+		_, _, err = s.writeSkillFile(op.Target, filepath.FromSlash(op.Path), op.Content)
+	case history.OpChmodFile:
+		_, _, err = s.setSkillFileExecutable(op.Target, filepath.FromSlash(op.Path), op.Enabled)
+	case history.OpBulk:
+		steps := slices.Clone(op.Steps)
+		for i := 0; i < len(steps) && err == nil; i++ {
+			steps[i], err = s.reapplyOp(steps[i])
+		}
+		op.Steps = steps
+	case history.OpOverrides:
+		// The change itself is applied below
+	default:
+		err = errors.New("unknown operation: " + op.Type)
+	}
+	if err == nil && op.Change != nil {
+		err = config.ApplyOverridesChange(*op.Change)
+	}
+	return op, err
+}
+
+// retrash moves the item a restore operation brought back into the trash
+// again, under the ID it had, so the operation that deleted it can still be
+// undone. The caller holds s.mu.
+func (s *SkillService) retrash(op history.Operation) (string, error) {
+	var trashID string
+	var err error
+	switch op.Type {
+	case history.OpRestoreSkill:
+		var skillDir string
+		if skillDir, err = s.findUserSkill(op.Target); err == nil {
+			trashID, err = s.trashSkill(skillDir)
+		}
+	case history.OpRestorePlugin:
+		trashID, err = s.trashPlugin(op.Target)
+	case history.OpRestoreFile:
+		trashID, err = s.trashSkillFile(op.Target, filepath.FromSlash(op.Path))
+	}
+	if err != nil {
+		return op.TrashID, err
+	}
+	return s.keepTrashID(trashID, op.TrashID)
+}
+
+// Transaction runs fn, which makes changes through the service, and keeps
+// them out of the history until it returns. If fn succeeds they are
+// recorded as usual; if it fails, fn is expected to have rolled them back
+// and they are dropped, so an aborted transaction leaves no entries, nor
+// entries for its rollback.
+func (s *SkillService) Transaction(fn func() error) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	var ops []history.Operation
+	s.pendingMu.Lock()
+	s.pending = &ops
+	s.pendingMu.Unlock()
+
+	err := fn()
+
+	s.pendingMu.Lock()
+	s.pending = nil
+	s.pendingMu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		s.record(op)
+	}
+	return nil
+}
+
+// record appends a completed operation to the journal and commits it when
+// versioning is on. The operation has already happened, so a failure to
+// record it is not reported to the caller. During a transaction it is held
+// back instead.
+func (s *SkillService) record(op history.Operation) {
+	s.pendingMu.Lock()
+	if s.pending != nil {
+		*s.pending = append(*s.pending, op)
+		s.pendingMu.Unlock()
+		return
+	}
+	s.pendingMu.Unlock()
+
+	if s.journal != nil {
+		s.journal.Append(history.KindDo, 0, op)
+	}
+	s.commitVersion(op.String())
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/history"
+)
+
+func newHistoryService(t *testing.T) (*SkillService, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+	t.Cleanup(func() { config.SetChangeHook(nil) })
+
+	svc := NewSkillService(tmpDir)
+	svc.EnableHistory(history.Open(tmpDir))
+	return svc, tmpDir
+}
+
+func TestUndoRedo_SkillOperations(t *testing.T) {
+	svc, tmpDir := newHistoryService(t)
+	enabledDir := filepath.Join(tmpDir, "skills")
+	setupSkill(t, enabledDir, "notes")
+
+	if err := svc.DisableSkill("notes"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DeleteSkill("notes", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Undo the delete, then the disable
+	if _, err := svc.Undo(); err != nil {
+		t.Fatalf("undo delete: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "skills-disabled", "notes")); err != nil {
+		t.Fatalf("expected skill back in the disabled dir: %v", err)
+	}
+	if _, err := svc.Undo(); err != nil {
+		t.Fatalf("undo disable: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(enabledDir, "notes")); err != nil {
+		t.Fatalf("expected skill back in the enabled dir: %v", err)
+	}
+	if _, err := svc.Undo(); !errors.Is(err, history.ErrNothingToUndo) {
+		t.Errorf("expected ErrNothingToUndo, got %v", err)
+	}
+
+	// Redo both again
+	svc.Redo()
+	if _, err := svc.Redo(); err != nil {
+		t.Fatalf("redo delete: %v", err)
+	}
+	if _, err := svc.findUserSkill("notes"); !errors.Is(err, ErrSkillNotFound) {
+		t.Errorf("expected skill deleted again, got %v", err)
+	}
+	if _, err := svc.Redo(); !errors.Is(err, history.ErrNothingToRedo) {
+		t.Errorf("expected ErrNothingToRedo, got %v", err)
+	}
+
+	// And the redone delete can be undone once more
+	if _, err := svc.Undo(); err != nil {
+		t.Fatalf("undo redone delete: %v", err)
+	}
+	if _, err := svc.findUserSkill("notes"); err != nil {
+		t.Errorf("expected skill restored: %v", err)
+	}
+}
+
+func TestUndoRedo_TrashRestore(t *testing.T) {
+	svc, tmpDir := newHistoryService(t)
+	setupSkill(t, filepath.Join(tmpDir, "skills"), "notes")
+
+	svc.DeleteSkill("notes", true)
+	items, _ := svc.ListTrash()
+	if _, err := svc.RestoreTrash(items[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Undoing the restore puts the skill back under the same trash ID, so
+	// the delete before it can be undone too
+	if _, err := svc.Undo(); err != nil {
+		t.Fatalf("undo restore: %v", err)
+	}
+	if trash, _ := svc.ListTrash(); len(trash) != 1 || trash[0].ID != items[0].ID {
+		t.Fatalf("expected the skill back in the trash as %s, got %+v", items[0].ID, trash)
+	}
+	if _, err := svc.Undo(); err != nil {
+		t.Fatalf("undo delete: %v", err)
+	}
+	if _, err := svc.findUserSkill("notes"); err != nil {
+		t.Fatalf("expected skill restored: %v", err)
+	}
+
+	// Both can be redone, and undone again
+	svc.Redo()
+	if _, err := svc.Redo(); err != nil {
+		t.Fatalf("redo restore: %v", err)
+	}
+	if _, err := svc.findUserSkill("notes"); err != nil {
+		t.Fatalf("expected skill restored again: %v", err)
+	}
+	for range 2 {
+		if _, err := svc.Undo(); err != nil {
+			t.Fatalf("undo after redo: %v", err)
+		}
+	}
+	if _, err := svc.findUserSkill("notes"); err != nil {
+		t.Errorf("expected skill back: %v", err)
+	}
+}
+
+func TestUndoRedo_SkillFiles(t *testing.T) {
+	svc, tmpDir := newHistoryService(t)
+	skillDir := filepath.Join(tmpDir, "skills", "notes")
+	setupSkill(t, filepath.Join(tmpDir, "skills"), "notes")
+	script := filepath.Join(skillDir, "bin", "lint.sh")
+
+	svc.WriteSkillFile("notes", "bin/lint.sh", []byte("v1"))
+	svc.WriteSkillFile("notes", "bin/lint.sh", []byte("v2"))
+	svc.SetSkillFileExecutable("notes", "bin/lint.sh", true)
+	// Already executable, so there is nothing to record
+	svc.SetSkillFileExecutable("notes", "scripts/run.sh", true)
+	if err := svc.DeleteSkillFile("notes", "bin"); err != nil {
+		t.Fatal(err)
+	}
+
+	svc.Undo()
+	if info, err := os.Stat(script); err != nil || info.Mode().Perm() != 0755 {
+		t.Fatalf("expected the deleted directory back with an executable script, got %v, %v", info, err)
+	}
+	svc.Undo()
+	if info, _ := os.Stat(script); info.Mode().Perm() != 0644 {
+		t.Errorf("expected chmod undone, got %v", info.Mode())
+	}
+	svc.Undo()
+	if content, _ := os.ReadFile(script); string(content) != "v1" {
+		t.Errorf("expected the previous content, got %q", content)
+	}
+	svc.Undo()
+	if _, err := os.Stat(script); !os.IsNotExist(err) {
+		t.Errorf("expected the created file removed, got %v", err)
+	}
+
+	for range 4 {
+		if _, err := svc.Redo(); err != nil {
+			t.Fatalf("redo: %v", err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(skillDir, "bin")); !os.IsNotExist(err) {
+		t.Errorf("expected the directory deleted again, got %v", err)
+	}
+	if trash, _ := svc.ListTrash(); len(trash) != 1 || trash[0].Kind != TrashFile || trash[0].Name != "notes/bin" {
+		t.Errorf("expected the directory in the trash, got %+v", trash)
+	}
+}
+
+func TestUndoRedo_SaveAndRename(t *testing.T) {
+	svc, tmpDir := newHistoryService(t)
+	enabledDir := filepath.Join(tmpDir, "skills")
+
+	svc.SaveSkill("new", []byte("---\nname: new\ndescription: v1\n---\n"), false)
+	svc.SaveSkill("new", []byte("---\nname: new\ndescription: v2\n---\n"), true)
+	svc.RenameSkill("new", "renamed")
+
+	svc.Undo()
+	if _, err := os.Stat(filepath.Join(enabledDir, "new")); err != nil {
+		t.Fatalf("expected rename undone: %v", err)
+	}
+	svc.Undo()
+	content, _ := os.ReadFile(filepath.Join(enabledDir, "new", "SKILL.md"))
+	if string(content) != "---\nname: new\ndescription: v1\n---\n" {
+		t.Errorf("expected previous content back, got %q", content)
+	}
+	svc.Undo()
+	if _, err := os.Stat(filepath.Join(enabledDir, "new")); !os.IsNotExist(err) {
+		t.Errorf("expected created skill removed, got %v", err)
+	}
+
+	svc.Redo()
+	if _, err := os.Stat(filepath.Join(enabledDir, "new", "SKILL.md")); err != nil {
+		t.Errorf("expected created skill back: %v", err)
+	}
+}
+
+func TestUndoRedo_PluginToggles(t *testing.T) {
+	svc, _ := newHistoryService(t)
+
+	config.DisablePluginSkill("tools", "lint")
+	config.DisablePlugin("tools")
+
+	if _, err := svc.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if config.IsPluginDisabled("tools") || !config.IsPluginSkillDisabled("tools", "lint") {
+		t.Error("expected plugin enabled and its skill override back")
+	}
+	svc.Undo()
+	if config.IsPluginSkillDisabled("tools", "lint") {
+		t.Error("expected plugin skill enabled")
+	}
+
+	entries, undo, redo, err := svc.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 || undo != 0 || redo != 2 {
+		t.Errorf("expected 4 entries, 0 undoable and 2 redoable, got %d, %d, %d", len(entries), undo, redo)
+	}
+}
+
+func TestUndoRedo_PluginDeleteAndFork(t *testing.T) {
+	svc, tmpDir := newHistoryService(t)
+	skillDir := filepath.Join(tmpDir, "plugins", "cache", "acme", "tools", "1.0.0", "skills", "lint")
+	os.MkdirAll(skillDir, 0755)
+	os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: lint\n---\n"), 0644)
+
+	// Forking with the original disabled is a single step
+	if _, err := svc.ForkPluginSkill("tools", "lint", "my-lint", true); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _, _, _ := svc.History(); len(entries) != 1 {
+		t.Fatalf("expected 1 entry for the fork, got %+v", entries)
+	}
+	if _, err := svc.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if config.IsPluginSkillDisabled("tools", "lint") {
+		t.Error("expected undo to enable the original again")
+	}
+	if _, err := svc.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if !config.IsPluginSkillDisabled("tools", "lint") {
+		t.Error("expected redo to disable the original again")
+	}
+
+	// So is deleting the plugin along with its overrides
+	if err := svc.DeletePlugin("tools"); err != nil {
+		t.Fatal(err)
+	}
+	if config.IsPluginSkillDisabled("tools", "lint") {
+		t.Error("expected the plugin's overrides forgotten")
+	}
+	entries, _, _, err := svc.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := entries[len(entries)-1]; last.Op.Type != history.OpDeletePlugin || len(entries) != 4 {
+		t.Fatalf("expected the delete as the only entry after the fork's undo and redo, got %+v", entries)
+	}
+	if _, err := svc.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if !config.IsPluginSkillDisabled("tools", "lint") {
+		t.Error("expected undo to bring the override back")
+	}
+	if _, err := svc.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.findUserSkill("my-lint"); !errors.Is(err, ErrSkillNotFound) {
+		t.Errorf("expected the fork undone, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wind/skill-router/internal/model"
+)
+
+// skillIndex caches parsed SKILL.md files by path. An entry is reused as long
+// as the file's modification time and size are unchanged, so a listing only
+// re-reads the files that changed since the last one.
+type skillIndex struct {
+	mu      sync.Mutex
+	entries map[string]indexEntry
+
+	// collisions found last, for the enabled skills summed up by collisionKey
+	collisionKey string
+	collisions   []Collision
+}
+
+type indexEntry struct {
+	modTime time.Time
+	size    int64
+	skill   model.Skill
+	body    string
+}
+
+func newSkillIndex() *skillIndex {
+	return &skillIndex{entries: make(map[string]indexEntry)}
+}
+
+func (i *skillIndex) get(path string, info os.FileInfo) (model.Skill, string, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	e, ok := i.entries[path]
+	if !ok || !e.modTime.Equal(info.ModTime()) || e.size != info.Size() {
+		return model.Skill{}, "", false
+	}
+	return e.skill, e.body, true
+}
+
+func (i *skillIndex) put(path string, info os.FileInfo, skill model.Skill, body string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.entries[path] = indexEntry{modTime: info.ModTime(), size: info.Size(), skill: skill, body: body}
+}
+
+// invalidate drops every entry at or below dir. Writes made through the
+// service call it so a change is never hidden by an unchanged mtime and size.
+func (i *skillIndex) invalidate(dir string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	prefix := dir + string(filepath.Separator)
+	for path := range i.entries {
+		if path == dir || strings.HasPrefix(path, prefix) {
+			delete(i.entries, path)
+		}
+	}
+}
+
+// prune drops the entries below root whose skill directory is not in seen,
+// so skills that were removed or moved outside the service do not stay
+// cached for the life of the process. A scan of root calls it with the
+// directories it found.
+func (i *skillIndex) prune(root string, seen map[string]bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	prefix := root + string(filepath.Separator)
+	for path := range i.entries {
+		if strings.HasPrefix(path, prefix) && !seen[filepath.Dir(path)] {
+			delete(i.entries, path)
+		}
+	}
+}
+
+// detectCollisions returns DetectCollisions(skills), reusing the previous
+// result while the enabled skills and their names and descriptions are the
+// same, so listings do not compare every pair of skills each time.
+func (i *skillIndex) detectCollisions(skills []model.Skill) []Collision {
+	h := sha256.New()
+	for _, skill := range skills {
+		if skill.Enabled {
+			fmt.Fprintf(h, "%s\x00%s\x00%s\x00", SkillID(skill), skill.Name, skill.Description)
+		}
+	}
+	key := hex.EncodeToString(h.Sum(nil))
+
+	i.mu.Lock()
+	cached, ok := i.collisions, i.collisions != nil && i.collisionKey == key
+	i.mu.Unlock()
+	if ok {
+		return cached
+	}
+
+	collisions := DetectCollisions(skills)
+	i.mu.Lock()
+	i.collisionKey, i.collisions = key, collisions
+	i.mu.Unlock()
+	return collisions
+}
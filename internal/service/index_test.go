@@ -0,0 +1,140 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wind/skill-router/internal/config"
+)
+
+func TestListSkills_IndexPicksUpChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	skillFile := filepath.Join(tmpDir, "skills", "my-skill", "SKILL.md")
+	os.MkdirAll(filepath.Dir(skillFile), 0755)
+	os.WriteFile(skillFile, []byte("---\nname: my-skill\ndescription: Old\n---\n"), 0644)
+
+	svc := NewSkillService(tmpDir)
+	skills, err := svc.ListSkills()
+	if err != nil || len(skills) != 1 || skills[0].Description != "Old" {
+		t.Fatalf("unexpected listing: %v, %v", skills, err)
+	}
+
+	// Outside edit: detected through mtime and size.
+	os.WriteFile(skillFile, []byte("---\nname: my-skill\ndescription: Newer\n---\n"), 0644)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(skillFile, future, future)
+
+	skills, _ = svc.ListSkills()
+	if skills[0].Description != "Newer" {
+		t.Errorf("expected outside edit to be picked up, got %q", skills[0].Description)
+	}
+
+	// Write through the service with identical size and mtime: detected
+	// through invalidation.
+	content := []byte("---\nname: my-skill\ndescription: Other\n---\n")
+	if err := svc.SaveSkill("my-skill", content, true); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(skillFile, future, future)
+
+	skills, _ = svc.ListSkills()
+	if skills[0].Description != "Other" {
+		t.Errorf("expected service write to be picked up, got %q", skills[0].Description)
+	}
+}
+
+func TestListSkills_IndexDropsRemovedSkills(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	userDir := filepath.Join(tmpDir, "skills", "gone")
+	pluginDir := filepath.Join(tmpDir, "plugins", "cache", "org", "tools", "1.0.0", "skills", "old")
+	for _, dir := range []string{userDir, pluginDir, filepath.Join(tmpDir, "skills", "kept")} {
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: x\ndescription: X\n---\n"), 0644)
+	}
+
+	svc := NewSkillService(tmpDir)
+	if _, err := svc.ListSkills(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(svc.index.entries); n != 3 {
+		t.Fatalf("expected 3 cached skills, got %d", n)
+	}
+
+	// Removed outside the service, and a plugin update to a new version.
+	os.RemoveAll(userDir)
+	newDir := filepath.Join(tmpDir, "plugins", "cache", "org", "tools", "2.0.0", "skills", "old")
+	os.MkdirAll(newDir, 0755)
+	os.WriteFile(filepath.Join(newDir, "SKILL.md"), []byte("---\nname: x\ndescription: X\n---\n"), 0644)
+
+	if _, err := svc.ListSkills(); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{userDir, pluginDir} {
+		if _, ok := svc.index.entries[filepath.Join(dir, "SKILL.md")]; ok {
+			t.Errorf("expected %s to be dropped from the index", dir)
+		}
+	}
+	if n := len(svc.index.entries); n != 2 {
+		t.Errorf("expected 2 cached skills, got %d", n)
+	}
+}
+
+// generateTree creates plugins with skills each, plus as many user skills.
+func generateTree(b *testing.B, plugins, skills int) string {
+	b.Helper()
+	tmpDir := b.TempDir()
+
+	for p := 0; p < plugins; p++ {
+		for s := 0; s < skills; s++ {
+			dir := filepath.Join(tmpDir, "plugins", "cache", "org", fmt.Sprintf("plugin-%d", p), "1.0.0", "skills", fmt.Sprintf("skill-%d", s))
+			os.MkdirAll(dir, 0755)
+			content := fmt.Sprintf("---\nname: plugin-%d-skill-%d\ndescription: Generated skill %d of plugin %d\n---\n\n# Body\n\nSome instructions.\n", p, s, s, p)
+			os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0644)
+		}
+	}
+	for s := 0; s < skills; s++ {
+		dir := filepath.Join(tmpDir, "skills", fmt.Sprintf("user-%d", s))
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: user\ndescription: User skill\n---\n"), 0644)
+	}
+
+	config.Init(tmpDir)
+	for p := 0; p < plugins; p += 3 {
+		config.DisablePluginSkill(fmt.Sprintf("plugin-%d", p), "skill-0")
+	}
+	return tmpDir
+}
+
+func BenchmarkListSkills_Cold(b *testing.B) {
+	tmpDir := generateTree(b, 20, 25)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		svc := NewSkillService(tmpDir)
+		if _, err := svc.ListSkills(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkListSkills_Warm(b *testing.B) {
+	tmpDir := generateTree(b, 20, 25)
+	svc := NewSkillService(tmpDir)
+	if _, err := svc.ListSkills(); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := svc.ListSkills(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
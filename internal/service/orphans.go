@@ -0,0 +1,94 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/wind/skill-router/internal/config"
+)
+
+// FindOrphanedOverrides cross-checks skill-overrides.json against the
+// installed plugins and reports entries that no longer match anything.
+func (s *SkillService) FindOrphanedOverrides() (*config.OrphanedOverrides, error) {
+	overrides, err := config.LoadOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	pluginSkills, err := s.scanPlugins()
+	if err != nil {
+		return nil, err
+	}
+	installedSkills := make(map[string]bool)
+	for _, skill := range pluginSkills {
+		installedSkills[skill.PluginName+":"+skill.FileName] = true
+	}
+
+	installedPlugins, err := s.installedPlugins()
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := &config.OrphanedOverrides{Disabled: []string{}, DisabledPlugins: []string{}}
+	for _, key := range overrides.Disabled {
+		if !installedSkills[key] {
+			orphans.Disabled = append(orphans.Disabled, key)
+		}
+	}
+	for _, name := range overrides.DisabledPlugins {
+		if !installedPlugins[name] {
+			orphans.DisabledPlugins = append(orphans.DisabledPlugins, name)
+		}
+	}
+
+	return orphans, nil
+}
+
+// PruneOrphanedOverrides removes the entries FindOrphanedOverrides reports
+// and returns them.
+func (s *SkillService) PruneOrphanedOverrides() (*config.OrphanedOverrides, error) {
+	orphans, err := s.FindOrphanedOverrides()
+	if err != nil {
+		return nil, err
+	}
+	if orphans.Empty() {
+		return orphans, nil
+	}
+
+	if err := config.RemoveOverrides(orphans); err != nil {
+		return nil, err
+	}
+	return orphans, nil
+}
+
+// installedPlugins returns the names of all plugin directories in the cache,
+// whether or not they contain skills.
+func (s *SkillService) installedPlugins() (map[string]bool, error) {
+	plugins := make(map[string]bool)
+
+	orgs, err := os.ReadDir(s.pluginsDir)
+	if os.IsNotExist(err) {
+		return plugins, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, org := range orgs {
+		if !org.IsDir() {
+			continue
+		}
+
+		entries, err := os.ReadDir(filepath.Join(s.pluginsDir, org.Name()))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				plugins[entry.Name()] = true
+			}
+		}
+	}
+
+	return plugins, nil
+}
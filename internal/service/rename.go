@@ -0,0 +1,176 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/wind/skill-router/internal/history"
+	"github.com/wind/skill-router/internal/parser"
+)
+
+var ErrSkillExists = fmt.Errorf("skill %w", ErrAlreadyExists)
+
+// RenameSkill moves a user skill to a new directory name, in whichever of
+// the enabled and disabled locations it lives, and rewrites the frontmatter
+// name to match.
+func (s *SkillService) RenameSkill(dirName, newName string) error {
+	s.mu.Lock()
+	err := s.renameSkill(dirName, newName)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.record(history.Operation{Type: history.OpRenameSkill, Target: dirName, NewName: newName})
+	return nil
+}
+
+// renameSkill is RenameSkill for callers that hold s.mu.
+func (s *SkillService) renameSkill(dirName, newName string) error {
+	src, dst, err := s.prepareCopy(dirName, newName)
+	if err != nil {
+		return err
+	}
+
+	s.index.invalidate(src)
+	if err := os.Rename(src, dst); err != nil {
+		return err
+	}
+	if err := setSkillName(dst, newName); err != nil {
+		// Put the directory back so name and folder stay in sync
+		os.Rename(dst, src)
+		return err
+	}
+	return nil
+}
+
+// DuplicateSkill copies a user skill, with all its files, to a new name next
+// to the original and rewrites the copy's frontmatter name.
+func (s *SkillService) DuplicateSkill(dirName, newName string) error {
+	s.mu.Lock()
+	err := s.duplicateSkill(dirName, newName)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.record(history.Operation{Type: history.OpCreateSkill, Target: newName})
+	return nil
+}
+
+func (s *SkillService) duplicateSkill(dirName, newName string) error {
+	src, dst, err := s.prepareCopy(dirName, newName)
+	if err != nil {
+		return err
+	}
+
+	if err := copyDir(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	if err := setSkillName(dst, newName); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return nil
+}
+
+// prepareCopy resolves the source directory of a user skill and the
+// destination for newName next to it, failing if newName is taken in either
+// location.
+func (s *SkillService) prepareCopy(dirName, newName string) (src, dst string, err error) {
+	if !validDirName(newName) {
+		return "", "", invalidSkillName(newName)
+	}
+
+	src, err = s.findUserSkill(dirName)
+	if err != nil {
+		return "", "", err
+	}
+	if err := s.checkNameFree(newName); err != nil {
+		return "", "", err
+	}
+	return src, filepath.Join(filepath.Dir(src), newName), nil
+}
+
+// checkNameFree fails if a user skill directory called name exists, enabled
+// or disabled.
+func (s *SkillService) checkNameFree(name string) error {
+	for _, dir := range []string{s.enabledDir, s.disabledDir} {
+		if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+			return fmt.Errorf("%w: %s", ErrSkillExists, name)
+		}
+	}
+	return nil
+}
+
+func setSkillName(skillDir, name string) error {
+	skillFile := skillFilePath(skillDir)
+	content, err := os.ReadFile(skillFile)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(skillFile)
+	if err != nil {
+		return err
+	}
+	updated := parser.SetFrontmatterField(string(content), "name", name)
+	return os.WriteFile(skillFile, []byte(updated), info.Mode().Perm())
+}
+
+// copyDir copies the tree at src to dst, keeping file modes. Symlinks are
+// recreated rather than followed.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		}
+		return nil
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
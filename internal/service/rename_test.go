@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wind/skill-router/internal/parser"
+)
+
+func setupSkill(t *testing.T, dir, name string) string {
+	t.Helper()
+	skillDir := filepath.Join(dir, name)
+	os.MkdirAll(filepath.Join(skillDir, "scripts"), 0755)
+	os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: "+name+"\ndescription: Test\n---\nBody"), 0644)
+	os.WriteFile(filepath.Join(skillDir, "scripts", "run.sh"), []byte("#!/bin/sh\n"), 0755)
+	return skillDir
+}
+
+func frontmatterName(t *testing.T, skillDir string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(skillDir, "SKILL.md"))
+	if err != nil {
+		t.Fatalf("read SKILL.md: %v", err)
+	}
+	fm, _ := parser.ParseFrontmatter(string(content))
+	return fm.Name
+}
+
+func TestRenameSkill(t *testing.T) {
+	tmpDir := t.TempDir()
+	disabledDir := filepath.Join(tmpDir, "skills-disabled")
+	setupSkill(t, disabledDir, "old-name")
+	setupSkill(t, filepath.Join(tmpDir, "skills"), "taken")
+
+	svc := NewSkillService(tmpDir)
+
+	if err := svc.RenameSkill("old-name", "taken"); !errors.Is(err, ErrSkillExists) {
+		t.Fatalf("expected ErrSkillExists, got %v", err)
+	}
+	if err := svc.RenameSkill("old-name", "new-name"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Stays in the disabled location.
+	newDir := filepath.Join(disabledDir, "new-name")
+	if got := frontmatterName(t, newDir); got != "new-name" {
+		t.Errorf("expected frontmatter name new-name, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(disabledDir, "old-name")); !os.IsNotExist(err) {
+		t.Error("old directory should be gone")
+	}
+}
+
+func TestDuplicateSkill(t *testing.T) {
+	tmpDir := t.TempDir()
+	enabledDir := filepath.Join(tmpDir, "skills")
+	setupSkill(t, enabledDir, "original")
+
+	svc := NewSkillService(tmpDir)
+	if err := svc.DuplicateSkill("original", "copy"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.DuplicateSkill("original", "copy"); !errors.Is(err, ErrSkillExists) {
+		t.Errorf("expected ErrSkillExists, got %v", err)
+	}
+
+	if got := frontmatterName(t, filepath.Join(enabledDir, "original")); got != "original" {
+		t.Errorf("original should keep its name, got %q", got)
+	}
+	copyDir := filepath.Join(enabledDir, "copy")
+	if got := frontmatterName(t, copyDir); got != "copy" {
+		t.Errorf("expected frontmatter name copy, got %q", got)
+	}
+	info, err := os.Stat(filepath.Join(copyDir, "scripts", "run.sh"))
+	if err != nil {
+		t.Fatalf("expected supporting files to be copied: %v", err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Error("expected file mode to be kept")
+	}
+}
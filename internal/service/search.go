@@ -0,0 +1,215 @@
+package service
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wind/skill-router/internal/model"
+)
+
+// snippetRadius is how much context, in bytes, is kept on each side of the
+// first match in a snippet.
+const snippetRadius = 80
+
+type SearchQuery struct {
+	Text    string
+	Project string
+	Source  string // "user", "project" or "plugin"
+	Plugin  string
+	Enabled *bool
+	Tools   []string // every tool must be in allowed-tools
+	Tags    []string // every tag must be present
+}
+
+type SearchResult struct {
+	Skill   model.Skill   `json:"skill"`
+	Score   int           `json:"score"`
+	Snippet []SnippetPart `json:"snippet"`
+}
+
+// SnippetPart is a piece of a snippet; Match marks the highlighted parts.
+type SnippetPart struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match,omitempty"`
+}
+
+// Search filters the skills from ListProjectSkills and ranks them by how
+// well they match the query text across name, description and body. All
+// terms of the query must match somewhere. Without text, every skill that
+// passes the filters is returned, sorted by name.
+func (s *SkillService) Search(q SearchQuery) ([]SearchResult, error) {
+	skills, err := s.ListProjectSkills(q.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := strings.Fields(strings.ToLower(q.Text))
+	results := []SearchResult{}
+
+	for _, skill := range skills {
+		if !matchesFilters(skill, q) {
+			continue
+		}
+
+		_, body := s.readSkillDoc(skill.FilePath, skill.FileName)
+		score, ok := scoreSkill(skill, body, terms)
+		if !ok {
+			continue
+		}
+
+		r := SearchResult{Skill: skill, Score: score}
+		if len(terms) > 0 {
+			r.Snippet = snippet(skill.Description, body, terms)
+		}
+		results = append(results, r)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Skill.Name < results[j].Skill.Name
+	})
+	return results, nil
+}
+
+func matchesFilters(skill model.Skill, q SearchQuery) bool {
+	if q.Source != "" && skill.Source != q.Source {
+		return false
+	}
+	if q.Plugin != "" && skill.PluginName != q.Plugin {
+		return false
+	}
+	if q.Enabled != nil && skill.Enabled != *q.Enabled {
+		return false
+	}
+	for _, tool := range q.Tools {
+		if !containsFold(skill.AllowedTools, tool) {
+			return false
+		}
+	}
+	for _, tag := range q.Tags {
+		if !containsFold(skill.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(items []string, item string) bool {
+	return slices.ContainsFunc(items, func(i string) bool { return strings.EqualFold(i, item) })
+}
+
+// scoreSkill weighs name matches above description matches above body
+// matches. It reports false if any term does not match at all.
+func scoreSkill(skill model.Skill, body string, terms []string) (int, bool) {
+	name := strings.ToLower(skill.Name)
+	description := strings.ToLower(skill.Description)
+	body = strings.ToLower(body)
+
+	score := 0
+	for _, term := range terms {
+		termScore := 0
+		switch {
+		case name == term:
+			termScore += 20
+		case strings.Contains(name, term):
+			termScore += 10
+		}
+		if strings.Contains(strings.ToLower(skill.PluginName), term) {
+			termScore += 4
+		}
+		termScore += 3 * min(strings.Count(description, term), 3)
+		termScore += min(strings.Count(body, term), 5)
+
+		if termScore == 0 {
+			return 0, false
+		}
+		score += termScore
+	}
+	return score, true
+}
+
+// snippet returns the text around the first match in the description or,
+// failing that, the body, with every term occurrence marked.
+func snippet(description, body string, terms []string) []SnippetPart {
+	text := description
+	start := firstMatch(text, terms)
+	if start < 0 {
+		text = body
+		start = firstMatch(text, terms)
+	}
+	if start < 0 {
+		return nil
+	}
+
+	from := max(start-snippetRadius, 0)
+	to := min(start+snippetRadius, len(text))
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from--
+	}
+	for to < len(text) && !utf8.RuneStart(text[to]) {
+		to++
+	}
+
+	window := strings.Join(strings.Fields(text[from:to]), " ")
+	var parts []SnippetPart
+	if from > 0 {
+		parts = append(parts, SnippetPart{Text: "…"})
+	}
+	parts = append(parts, highlight(window, terms)...)
+	if to < len(text) {
+		parts = append(parts, SnippetPart{Text: "…"})
+	}
+	return parts
+}
+
+func firstMatch(text string, terms []string) int {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		// Lowercasing changed byte offsets; fall back to exact matching.
+		lower = text
+	}
+
+	first := -1
+	for _, term := range terms {
+		if i := strings.Index(lower, term); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	return first
+}
+
+func highlight(text string, terms []string) []SnippetPart {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		lower = text
+	}
+
+	var parts []SnippetPart
+	last := 0
+	for i := 0; i < len(text); {
+		matched := 0
+		for _, term := range terms {
+			if strings.HasPrefix(lower[i:], term) && len(term) > matched {
+				matched = len(term)
+			}
+		}
+		if matched == 0 {
+			i++
+			continue
+		}
+		if i > last {
+			parts = append(parts, SnippetPart{Text: text[last:i]})
+		}
+		parts = append(parts, SnippetPart{Text: text[i : i+matched], Match: true})
+		i += matched
+		last = i
+	}
+	if last < len(text) {
+		parts = append(parts, SnippetPart{Text: text[last:]})
+	}
+	return parts
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wind/skill-router/internal/config"
+)
+
+func TestSearch(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	write := func(dir, content string) {
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0644)
+	}
+	write(filepath.Join(tmpDir, "skills", "code-review"), `---
+name: code-review
+description: Review pull requests
+allowed-tools: Read, Grep
+tags: [review, git]
+---
+Look at the diff and leave review comments.`)
+	write(filepath.Join(tmpDir, "skills", "writing"), `---
+name: writing
+description: Help with prose
+---
+Suggest edits. Never review code.`)
+	write(filepath.Join(tmpDir, "plugins", "cache", "acme", "tools", "1.0.0", "skills", "lint"), `---
+name: lint
+description: Run linters
+allowed-tools: Bash
+---
+Run the linter before review.`)
+
+	svc := NewSkillService(tmpDir)
+
+	results, err := svc.Search(SearchQuery{Text: "review"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[0].Skill.Name != "code-review" {
+		t.Errorf("expected code-review to rank first, got %s", results[0].Skill.Name)
+	}
+
+	var highlighted bool
+	for _, part := range results[0].Snippet {
+		if part.Match && part.Text == "Review" {
+			highlighted = true
+		}
+	}
+	if !highlighted {
+		t.Errorf("expected highlighted match in snippet, got %+v", results[0].Snippet)
+	}
+
+	results, _ = svc.Search(SearchQuery{Text: "review", Source: "plugin"})
+	if len(results) != 1 || results[0].Skill.Name != "lint" {
+		t.Errorf("expected only lint for plugin source, got %v", results)
+	}
+
+	results, _ = svc.Search(SearchQuery{Tools: []string{"grep"}, Tags: []string{"git"}})
+	if len(results) != 1 || results[0].Skill.Name != "code-review" {
+		t.Errorf("expected only code-review for tool and tag filters, got %v", results)
+	}
+
+	results, _ = svc.Search(SearchQuery{Text: "review linter"})
+	if len(results) != 1 || results[0].Skill.Name != "lint" {
+		t.Errorf("expected all terms to be required, got %v", results)
+	}
+}
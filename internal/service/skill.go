@@ -4,10 +4,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/github"
+	"github.com/wind/skill-router/internal/history"
 	"github.com/wind/skill-router/internal/model"
 	"github.com/wind/skill-router/internal/parser"
+	"github.com/wind/skill-router/internal/usage"
+	"github.com/wind/skill-router/internal/versioning"
+	"github.com/wind/skill-router/internal/watcher"
 )
 
 type SkillService struct {
@@ -15,6 +23,29 @@ type SkillService struct {
 	enabledDir  string
 	disabledDir string
 	pluginsDir  string
+	trashDir    string
+	index       *skillIndex
+
+	trashRetention time.Duration
+
+	// journal records operations for undo and redo; nil disables history
+	journal   *history.Journal
+	historyMu sync.Mutex
+
+	// pending holds the operations of the running transaction, if any,
+	// until it succeeds; txMu serializes transactions
+	pending   *[]history.Operation
+	pendingMu sync.Mutex
+	txMu      sync.Mutex
+
+	// versions commits changes to git when versioning is enabled
+	versions *versioning.Repo
+
+	// usage finds skill invocations in transcripts; nil disables analytics
+	usage *usage.Tracker
+
+	// mu serializes read-check-write cycles on skill files
+	mu sync.Mutex
 }
 
 func NewSkillService(baseDir string) *SkillService {
@@ -23,10 +54,33 @@ func NewSkillService(baseDir string) *SkillService {
 		enabledDir:  filepath.Join(baseDir, "skills"),
 		disabledDir: filepath.Join(baseDir, "skills-disabled"),
 		pluginsDir:  filepath.Join(baseDir, "plugins", "cache"),
+		trashDir:    filepath.Join(baseDir, "skill-trash"),
+		index:       newSkillIndex(),
+
+		trashRetention: DefaultTrashRetention,
+	}
+}
+
+// WatchTargets returns the paths that ListSkills reads, for watching them for
+// outside changes.
+func (s *SkillService) WatchTargets() []watcher.Target {
+	return []watcher.Target{
+		{Path: s.enabledDir, Depth: 1},
+		{Path: s.disabledDir, Depth: 1},
+		// <org>/<plugin>/<version>/skills/<skill>
+		{Path: s.pluginsDir, Depth: 5},
+		{Path: filepath.Dir(config.OverridesPath()), Files: []string{filepath.Base(config.OverridesPath())}},
 	}
 }
 
 func (s *SkillService) ListSkills() ([]model.Skill, error) {
+	return s.ListProjectSkills("")
+}
+
+// ListProjectSkills lists skills as seen from a project directory: the
+// project's own .claude/skills are included and its skill-overrides.json is
+// layered on top of the global state. An empty projectDir means no project.
+func (s *SkillService) ListProjectSkills(projectDir string) ([]model.Skill, error) {
 	var skills []model.Skill
 
 	// Scan user enabled skills
@@ -50,12 +104,42 @@ func (s *SkillService) ListSkills() ([]model.Skill, error) {
 	}
 	skills = append(skills, pluginSkills...)
 
+	if projectDir == "" {
+		return skills, nil
+	}
+
+	projectSkills, err := s.scanUserDir(filepath.Join(projectDir, ".claude", "skills"), true)
+	if err != nil {
+		return nil, err
+	}
+	for i := range projectSkills {
+		projectSkills[i].Source = "project"
+		projectSkills[i].StateLayer = config.LayerDefault
+	}
+	skills = append(skills, projectSkills...)
+
+	overrides, err := config.LoadProjectOverrides(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	for i := range skills {
+		sk := &skills[i]
+		if sk.Source == "plugin" {
+			sk.Enabled, sk.StateLayer = overrides.ResolvePluginSkill(sk.PluginName, sk.FileName, sk.Enabled, sk.StateLayer)
+		} else {
+			sk.Enabled, sk.StateLayer = overrides.ResolveSkill(sk.FileName, sk.Enabled, sk.StateLayer)
+		}
+	}
+
 	return skills, nil
 }
 
 func (s *SkillService) scanUserDir(dir string, enabled bool) ([]model.Skill, error) {
 	var skills []model.Skill
 
+	seen := make(map[string]bool)
+	defer s.index.prune(dir, seen)
+
 	entries, err := os.ReadDir(dir)
 	if os.IsNotExist(err) {
 		return skills, nil
@@ -69,10 +153,13 @@ func (s *SkillService) scanUserDir(dir string, enabled bool) ([]model.Skill, err
 			continue
 		}
 
-		skill := s.readSkillDir(filepath.Join(dir, entry.Name()), entry.Name())
+		skillDir := filepath.Join(dir, entry.Name())
+		seen[skillDir] = true
+		skill := s.readSkillDir(skillDir, entry.Name())
 		if skill != nil {
 			skill.Enabled = enabled
 			skill.Source = "user"
+			skill.StateLayer = config.LayerGlobal
 			skills = append(skills, *skill)
 		}
 	}
@@ -92,6 +179,15 @@ func (s *SkillService) scanPlugins() ([]model.Skill, error) {
 		return nil, err
 	}
 
+	// Load the overrides once for the whole scan
+	overrides, err := config.LoadOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	defer s.index.prune(s.pluginsDir, seen)
+
 	for _, org := range orgs {
 		if !org.IsDir() {
 			continue
@@ -139,9 +235,15 @@ func (s *SkillService) scanPlugins() ([]model.Skill, error) {
 					continue
 				}
 
-				skill := s.readSkillDir(filepath.Join(skillsPath, skillDir.Name()), skillDir.Name())
+				skillPath := filepath.Join(skillsPath, skillDir.Name())
+				seen[skillPath] = true
+				skill := s.readSkillDir(skillPath, skillDir.Name())
 				if skill != nil {
-					skill.Enabled = !config.IsPluginSkillDisabled(pluginName, skillDir.Name())
+					skill.Enabled = !overrides.IsPluginSkillDisabled(pluginName, skillDir.Name())
+					skill.StateLayer = config.LayerDefault
+					if !skill.Enabled {
+						skill.StateLayer = config.LayerGlobal
+					}
 					skill.Source = "plugin"
 					skill.PluginName = pluginName
 					skills = append(skills, *skill)
@@ -154,88 +256,222 @@ func (s *SkillService) scanPlugins() ([]model.Skill, error) {
 }
 
 func (s *SkillService) readSkillDir(skillDir, dirName string) *model.Skill {
+	skill, _ := s.readSkillDoc(skillDir, dirName)
+	return skill
+}
+
+// readSkillDoc is readSkillDir that also returns the markdown body.
+func (s *SkillService) readSkillDoc(skillDir, dirName string) (*model.Skill, string) {
 	skillFile := filepath.Join(skillDir, "SKILL.md")
 
-	content, err := os.ReadFile(skillFile)
+	info, err := os.Stat(skillFile)
 	if err != nil {
 		// Try lowercase
 		skillFile = filepath.Join(skillDir, "skill.md")
-		content, err = os.ReadFile(skillFile)
+		info, err = os.Stat(skillFile)
 		if err != nil {
-			return nil
+			return nil, ""
 		}
 	}
 
+	if skill, body, ok := s.index.get(skillFile, info); ok {
+		return &skill, body
+	}
+
+	content, err := os.ReadFile(skillFile)
+	if err != nil {
+		return nil, ""
+	}
+
 	fm, _ := parser.ParseFrontmatter(string(content))
 	name := fm.Name
 	if name == "" {
 		name = dirName
 	}
 
-	return &model.Skill{
-		Name:        name,
-		Description: fm.Description,
-		FileName:    dirName,
-		FilePath:    skillDir,
+	skill := model.Skill{
+		Name:         name,
+		Description:  fm.Description,
+		FileName:     dirName,
+		FilePath:     skillDir,
+		AllowedTools: fm.AllowedTools,
+		Tags:         fm.Tags,
+		Tokens:       MetadataTokens(name, fm.Description),
 	}
+	body := parser.Body(string(content))
+	s.index.put(skillFile, info, skill, body)
+	return &skill, body
 }
 
 func (s *SkillService) DisableSkill(dirName string) error {
-	src := filepath.Join(s.enabledDir, dirName)
-	dst := filepath.Join(s.disabledDir, dirName)
-
-	if err := os.MkdirAll(s.disabledDir, 0755); err != nil {
+	if err := s.moveSkill(dirName, s.enabledDir, s.disabledDir); err != nil {
 		return err
 	}
-
-	return os.Rename(src, dst)
+	s.record(history.Operation{Type: history.OpDisableSkill, Target: dirName})
+	return nil
 }
 
 func (s *SkillService) EnableSkill(dirName string) error {
-	src := filepath.Join(s.disabledDir, dirName)
-	dst := filepath.Join(s.enabledDir, dirName)
+	if err := s.moveSkill(dirName, s.disabledDir, s.enabledDir); err != nil {
+		return err
+	}
+	s.record(history.Operation{Type: history.OpEnableSkill, Target: dirName})
+	return nil
+}
+
+func (s *SkillService) moveSkill(dirName, fromDir, toDir string) error {
+	if !validDirName(dirName) {
+		return invalidSkillName(dirName)
+	}
+	src := filepath.Join(fromDir, dirName)
+	dst := filepath.Join(toDir, dirName)
+	if _, err := os.Lstat(src); os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", ErrSkillNotFound, dirName)
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%w: %s", ErrSkillExists, dirName)
+	}
 
-	if err := os.MkdirAll(s.enabledDir, 0755); err != nil {
+	if err := os.MkdirAll(toDir, 0755); err != nil {
 		return err
 	}
 
+	s.index.invalidate(src)
 	return os.Rename(src, dst)
 }
 
+// DeleteSkill moves a user skill into the trash, from where it can be
+// restored until the trash is emptied or the item expires.
 func (s *SkillService) DeleteSkill(dirName string, enabled bool) error {
+	if !validDirName(dirName) {
+		return invalidSkillName(dirName)
+	}
+
 	var dirPath string
 	if enabled {
 		dirPath = filepath.Join(s.enabledDir, dirName)
 	} else {
 		dirPath = filepath.Join(s.disabledDir, dirName)
 	}
-	return os.RemoveAll(dirPath)
+
+	s.mu.Lock()
+	if _, err := os.Lstat(dirPath); os.IsNotExist(err) {
+		s.mu.Unlock()
+		return nil
+	}
+	trashID, err := s.trashSkill(dirPath)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.record(history.Operation{Type: history.OpDeleteSkill, Target: dirName, Enabled: enabled, TrashID: trashID})
+	return nil
+}
+
+// trashSkill moves a user skill directory into the trash. The caller holds
+// s.mu.
+func (s *SkillService) trashSkill(skillDir string) (string, error) {
+	s.index.invalidate(skillDir)
+	return s.moveToTrash(skillDir, TrashItem{
+		Kind:    TrashSkill,
+		Name:    filepath.Base(skillDir),
+		Source:  "user",
+		Enabled: filepath.Dir(skillDir) == s.enabledDir,
+	})
 }
 
 func (s *SkillService) SaveSkill(skillDirName string, content []byte, overwrite bool) error {
+	if !validDirName(skillDirName) {
+		return invalidSkillName(skillDirName)
+	}
 	skillDir := filepath.Join(s.enabledDir, skillDirName)
 	skillFile := filepath.Join(skillDir, "SKILL.md")
 
-	if !overwrite {
-		if _, err := os.Stat(skillDir); err == nil {
-			return fmt.Errorf("skill already exists")
-		}
+	_, err := os.Stat(skillDir)
+	existed := err == nil
+	if existed && !overwrite {
+		return fmt.Errorf("%w: %s", ErrSkillExists, skillDirName)
 	}
+	previous, _ := os.ReadFile(skillFile)
 
 	if err := os.MkdirAll(skillDir, 0755); err != nil {
 		return err
 	}
 
-	return os.WriteFile(skillFile, content, 0644)
+	if err := os.WriteFile(skillFile, content, 0644); err != nil {
+		return err
+	}
+	s.index.invalidate(skillDir)
+
+	if existed {
+		s.record(history.Operation{Type: history.OpWriteSkill, Target: skillDirName, Content: content, Previous: previous})
+	} else {
+		s.record(history.Operation{Type: history.OpCreateSkill, Target: skillDirName})
+	}
+	return nil
+}
+
+// InstallFromGitHub saves the skills of a GitHub repository as user skills
+// and returns how many were installed. Skills that already exist are left
+// alone.
+func (s *SkillService) InstallFromGitHub(repoURL string) (int, error) {
+	files, err := github.FetchSkillFiles(repoURL)
+	if err != nil {
+		return 0, githubError(err)
+	}
+
+	installed := 0
+	var downloadErr error
+	for _, f := range files {
+		content, err := github.DownloadFile(f.DownloadURL)
+		if err != nil {
+			if downloadErr == nil {
+				downloadErr = githubError(err)
+			}
+			continue
+		}
+		if err := s.SaveSkill(f.Name, content, false); err != nil {
+			continue
+		}
+		installed++
+	}
+	if installed == 0 && downloadErr != nil {
+		return 0, downloadErr
+	}
+	return installed, nil
 }
 
+// DeletePlugin moves a plugin, with all its cached versions, into the trash
+// and forgets its overrides.
 func (s *SkillService) DeletePlugin(pluginName string) error {
-	// Find and delete the plugin directory
-	orgs, err := os.ReadDir(s.pluginsDir)
+	s.mu.Lock()
+	trashID, err := s.trashPlugin(pluginName)
+	s.mu.Unlock()
 	if err != nil {
 		return err
 	}
 
+	// The forgotten overrides go into the same operation, so one undo
+	// brings back both
+	change, err := s.forgetPlugin(pluginName)
+	s.record(history.Operation{Type: history.OpDeletePlugin, Target: pluginName, TrashID: trashID, Change: change})
+	return err
+}
+
+// trashPlugin moves a plugin into the trash and returns the trash item ID.
+// The caller holds s.mu.
+func (s *SkillService) trashPlugin(pluginName string) (string, error) {
+	if !validDirName(pluginName) {
+		return "", invalidPluginName(pluginName)
+	}
+
+	// Find and delete the plugin directory
+	orgs, err := os.ReadDir(s.pluginsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
 	for _, org := range orgs {
 		if !org.IsDir() {
 			continue
@@ -243,9 +479,44 @@ func (s *SkillService) DeletePlugin(pluginName string) error {
 
 		pluginPath := filepath.Join(s.pluginsDir, org.Name(), pluginName)
 		if _, err := os.Stat(pluginPath); err == nil {
-			return os.RemoveAll(pluginPath)
+			overrides, err := pluginOverrides(pluginName)
+			if err != nil {
+				return "", err
+			}
+
+			s.index.invalidate(pluginPath)
+			return s.moveToTrash(pluginPath, TrashItem{
+				Kind:      TrashPlugin,
+				Name:      pluginName,
+				Source:    "plugin",
+				Enabled:   !slices.Contains(overrides.DisabledPlugins, pluginName),
+				Overrides: overrides,
+			})
 		}
 	}
 
-	return fmt.Errorf("plugin not found: %s", pluginName)
+	return "", fmt.Errorf("%w: %s", ErrPluginNotFound, pluginName)
+}
+
+// forgetPlugin drops the overrides of a deleted plugin so a later reinstall
+// starts out enabled. They are kept if another org still ships a plugin with
+// the same name. It returns the change made, if any, without reporting it to
+// the change hook: the caller records it as part of its own operation.
+func (s *SkillService) forgetPlugin(pluginName string) (*config.OverridesChange, error) {
+	installed, err := s.installedPlugins()
+	if err != nil || installed[pluginName] {
+		return nil, err
+	}
+	entries, err := pluginOverrides(pluginName)
+	if err != nil {
+		return nil, err
+	}
+	change := config.OverridesChange{RemovedDisabled: entries.Disabled, RemovedDisabledPlugins: entries.DisabledPlugins}
+	if change.Empty() {
+		return nil, nil
+	}
+	if err := config.ApplyOverridesChange(change); err != nil {
+		return nil, err
+	}
+	return &change, nil
 }
@@ -1,9 +1,13 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/wind/skill-router/internal/config"
 )
 
 func TestListSkills(t *testing.T) {
@@ -167,3 +171,165 @@ Content`)
 		t.Fatalf("unexpected saved content: %q", string(got))
 	}
 }
+
+func TestSkillErrors_HaveKinds(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+	setupSkill(t, filepath.Join(tmpDir, "skills"), "notes")
+	svc := NewSkillService(tmpDir)
+
+	if err := svc.DisableSkill("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("disable missing: expected ErrNotFound, got %v", err)
+	}
+	if err := svc.DisableSkill("../notes"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("disable ../notes: expected ErrInvalidName, got %v", err)
+	}
+	if err := svc.SaveSkill("notes", []byte("---\nname: notes\ndescription: x\n---\n"), false); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("save existing: expected ErrAlreadyExists, got %v", err)
+	}
+	// Uploads and installs accept any SKILL.md; only content edits are strict
+	if err := svc.SaveSkill("plain", []byte("no frontmatter"), false); err != nil {
+		t.Errorf("save without frontmatter: expected it to be accepted, got %v", err)
+	}
+	if err := svc.DeletePlugin("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("delete missing plugin: expected ErrNotFound, got %v", err)
+	}
+	if ErrSkillNotFound.Error() != "skill not found" || ErrSkillExists.Error() != "skill already exists" {
+		t.Errorf("unexpected sentinel messages: %q, %q", ErrSkillNotFound, ErrSkillExists)
+	}
+}
+
+func TestListSkills_ReportsUnreadableOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	skillDir := filepath.Join(tmpDir, "plugins", "cache", "acme", "tools", "1.0.0", "skills", "lint")
+	os.MkdirAll(skillDir, 0755)
+	os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: lint\n---\n"), 0644)
+	future := fmt.Sprintf(`{"version": %d, "disabled": ["tools:lint"]}`, config.CurrentVersion+1)
+	os.WriteFile(filepath.Join(tmpDir, "skill-overrides.json"), []byte(future), 0644)
+
+	svc := NewSkillService(tmpDir)
+	if _, err := svc.ListSkills(); !errors.Is(err, config.ErrUnsupportedVersion) {
+		t.Errorf("expected ErrUnsupportedVersion, got %v", err)
+	}
+}
+
+func TestListProjectSkills_AppliesProjectLayer(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := t.TempDir()
+	config.Init(tmpDir)
+
+	writeSkill := func(dir, name string) {
+		os.MkdirAll(filepath.Join(dir, name), 0755)
+		content := "---\nname: " + name + "\ndescription: Test\n---\nContent"
+		os.WriteFile(filepath.Join(dir, name, "SKILL.md"), []byte(content), 0644)
+	}
+	writeSkill(filepath.Join(tmpDir, "skills"), "user-skill")
+	writeSkill(filepath.Join(tmpDir, "plugins", "cache", "acme", "tools", "1.0.0", "skills"), "lint")
+	writeSkill(filepath.Join(tmpDir, "plugins", "cache", "acme", "tools", "1.0.0", "skills"), "format")
+	writeSkill(filepath.Join(projectDir, ".claude", "skills"), "local")
+
+	if err := config.DisablePluginSkill("tools", "format"); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SetProjectOverride(projectDir, "", "user-skill", config.StateDisabled); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SetProjectOverride(projectDir, "tools", "", config.StateDisabled); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SetProjectOverride(projectDir, "tools", "format", config.StateEnabled); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewSkillService(tmpDir)
+	skills, err := svc.ListProjectSkills(projectDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type state struct {
+		enabled bool
+		layer   string
+	}
+	want := map[string]state{
+		"user-skill": {false, config.LayerProject},
+		"lint":       {false, config.LayerProject},
+		"format":     {true, config.LayerProject},
+		"local":      {true, config.LayerDefault},
+	}
+	if len(skills) != len(want) {
+		t.Fatalf("expected %d skills, got %d", len(want), len(skills))
+	}
+	for _, s := range skills {
+		w := want[s.FileName]
+		if s.Enabled != w.enabled || s.StateLayer != w.layer {
+			t.Errorf("%s: expected enabled=%v layer=%s, got enabled=%v layer=%s", s.FileName, w.enabled, w.layer, s.Enabled, s.StateLayer)
+		}
+	}
+
+	// Without a project the global state applies.
+	skills, err = svc.ListSkills()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range skills {
+		if s.FileName == "format" && (s.Enabled || s.StateLayer != config.LayerGlobal) {
+			t.Errorf("format should be disabled by the global layer, got enabled=%v layer=%s", s.Enabled, s.StateLayer)
+		}
+	}
+}
+
+func TestOrphanedOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	skillDir := filepath.Join(tmpDir, "plugins", "cache", "acme", "tools", "1.0.0", "skills", "lint")
+	os.MkdirAll(skillDir, 0755)
+	os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: lint\n---\n"), 0644)
+
+	config.DisablePluginSkill("tools", "lint")
+	config.DisablePluginSkill("tools", "removed")
+	config.DisablePluginSkill("gone", "skill")
+	config.DisablePlugin("uninstalled")
+
+	svc := NewSkillService(tmpDir)
+	orphans, err := svc.PruneOrphanedOverrides()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(orphans.Disabled) != 2 || len(orphans.DisabledPlugins) != 1 {
+		t.Fatalf("unexpected orphans: %+v", orphans)
+	}
+
+	overrides, _ := config.LoadOverrides()
+	if len(overrides.Disabled) != 1 || overrides.Disabled[0] != "tools:lint" {
+		t.Errorf("expected only tools:lint to remain, got %v", overrides.Disabled)
+	}
+	if len(overrides.DisabledPlugins) != 0 {
+		t.Errorf("expected no disabled plugins, got %v", overrides.DisabledPlugins)
+	}
+}
+
+func TestDeletePlugin_RemovesOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	skillDir := filepath.Join(tmpDir, "plugins", "cache", "acme", "tools", "1.0.0", "skills", "lint")
+	os.MkdirAll(skillDir, 0755)
+	os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: lint\n---\n"), 0644)
+
+	config.DisablePluginSkill("tools", "lint")
+	config.DisablePluginSkill("other", "skill")
+
+	svc := NewSkillService(tmpDir)
+	if err := svc.DeletePlugin("tools"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	overrides, _ := config.LoadOverrides()
+	if len(overrides.Disabled) != 1 || overrides.Disabled[0] != "other:skill" {
+		t.Errorf("expected only other:skill to remain, got %v", overrides.Disabled)
+	}
+}
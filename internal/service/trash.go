@@ -0,0 +1,279 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/history"
+)
+
+// DefaultTrashRetention is how long deleted items stay in the trash before
+// they are purged for good.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+const (
+	TrashSkill  = "skill"
+	TrashPlugin = "plugin"
+	TrashFile   = "file"
+
+	trashMetaFile = "meta.json"
+	trashItemDir  = "item"
+)
+
+var ErrTrashItemNotFound = fmt.Errorf("trash item %w", ErrNotFound)
+
+// TrashItem describes something deleted into the trash. Overrides holds the
+// global override entries that applied to a deleted plugin; they are put
+// back when it is restored.
+type TrashItem struct {
+	ID           string                    `json:"id"`
+	Kind         string                    `json:"kind"`   // "skill", "plugin" or "file"
+	Name         string                    `json:"name"`   // for a file, "<skill>/<path>"
+	Source       string                    `json:"source"` // "user" or "plugin"
+	OriginalPath string                    `json:"originalPath"`
+	Enabled      bool                      `json:"enabled"`
+	DeletedAt    time.Time                 `json:"deletedAt"`
+	Overrides    *config.OrphanedOverrides `json:"overrides,omitempty"`
+}
+
+// SetTrashRetention changes how long deleted items are kept. Zero or less
+// keeps them until the trash is emptied.
+func (s *SkillService) SetTrashRetention(d time.Duration) {
+	s.trashRetention = d
+}
+
+// ListTrash returns the items in the trash, newest first. Expired items are
+// purged first.
+func (s *SkillService) ListTrash() ([]TrashItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.purgeTrash(time.Now()); err != nil {
+		return nil, err
+	}
+	return s.readTrash()
+}
+
+// RestoreTrash moves an item back to where it was deleted from and, for a
+// plugin, reinstates its overrides. It fails with ErrSkillExists if
+// something has taken its place since.
+func (s *SkillService) RestoreTrash(id string) (*TrashItem, error) {
+	s.mu.Lock()
+	item, err := s.restoreTrash(id)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	op := history.Operation{Type: history.OpRestoreSkill, Target: item.Name, TrashID: id}
+	switch item.Kind {
+	case TrashPlugin:
+		op.Type = history.OpRestorePlugin
+	case TrashFile:
+		op.Type = history.OpRestoreFile
+		op.Target, op.Path, _ = strings.Cut(item.Name, "/")
+	}
+	s.record(op)
+	return item, nil
+}
+
+// restoreTrash is RestoreTrash for callers that hold s.mu.
+func (s *SkillService) restoreTrash(id string) (*TrashItem, error) {
+	item, err := s.readTrashItem(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(item.OriginalPath); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSkillExists, item.OriginalPath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(item.OriginalPath), 0755); err != nil {
+		return nil, err
+	}
+	if err := moveDir(filepath.Join(s.trashDir, id, trashItemDir), item.OriginalPath); err != nil {
+		return nil, err
+	}
+	if item.Overrides != nil && !item.Overrides.Empty() {
+		err := config.ApplyOverridesChange(config.OverridesChange{
+			AddedDisabled:        item.Overrides.Disabled,
+			AddedDisabledPlugins: item.Overrides.DisabledPlugins,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return item, os.RemoveAll(filepath.Join(s.trashDir, id))
+}
+
+// DeleteTrash removes a single item from the trash for good.
+func (s *SkillService) DeleteTrash(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.readTrashItem(id); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(s.trashDir, id))
+}
+
+// EmptyTrash removes everything in the trash for good.
+func (s *SkillService) EmptyTrash() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return os.RemoveAll(s.trashDir)
+}
+
+// moveToTrash moves path into a new trash item described by item and
+// returns the item's ID. The caller holds s.mu.
+func (s *SkillService) moveToTrash(path string, item TrashItem) (string, error) {
+	item.DeletedAt = time.Now().UTC()
+	item.OriginalPath = path
+	item.ID = item.DeletedAt.Format("20060102T150405.000000000") + "-" + item.Kind + "-" + strings.ReplaceAll(item.Name, "/", "_")
+
+	itemDir := filepath.Join(s.trashDir, item.ID)
+	if err := os.MkdirAll(itemDir, 0755); err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(itemDir, trashMetaFile), data, 0644); err != nil {
+		os.RemoveAll(itemDir)
+		return "", err
+	}
+	if err := moveDir(path, filepath.Join(itemDir, trashItemDir)); err != nil {
+		os.RemoveAll(itemDir)
+		return "", err
+	}
+
+	// Deleting is a good moment to drop what has expired
+	s.purgeTrash(item.DeletedAt)
+	return item.ID, nil
+}
+
+// keepTrashID renames the trash item newID to id, so that an item trashed
+// again by undo or redo keeps the ID other journal entries know it by. It
+// returns the ID the item ends up with. The caller holds s.mu.
+func (s *SkillService) keepTrashID(newID, id string) (string, error) {
+	if id == "" || id == newID {
+		return newID, nil
+	}
+	if err := os.Rename(filepath.Join(s.trashDir, newID), filepath.Join(s.trashDir, id)); err != nil {
+		return newID, err
+	}
+	return id, nil
+}
+
+// purgeTrash removes items deleted more than the retention period before
+// now. The caller holds s.mu.
+func (s *SkillService) purgeTrash(now time.Time) error {
+	if s.trashRetention <= 0 {
+		return nil
+	}
+
+	items, err := s.readTrash()
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if now.Sub(item.DeletedAt) > s.trashRetention {
+			if err := os.RemoveAll(filepath.Join(s.trashDir, item.ID)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *SkillService) readTrash() ([]TrashItem, error) {
+	entries, err := os.ReadDir(s.trashDir)
+	if os.IsNotExist(err) {
+		return []TrashItem{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items := []TrashItem{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		item, err := s.readTrashItem(entry.Name())
+		if err != nil {
+			// Skip anything that is not a complete trash item
+			continue
+		}
+		items = append(items, *item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+func (s *SkillService) readTrashItem(id string) (*TrashItem, error) {
+	if !validDirName(id) {
+		return nil, fmt.Errorf("%w: %s", ErrTrashItemNotFound, id)
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.trashDir, id, trashMetaFile))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrTrashItemNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var item TrashItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+	item.ID = id
+	return &item, nil
+}
+
+// pluginOverrides returns the global override entries that mention a plugin.
+func pluginOverrides(pluginName string) (*config.OrphanedOverrides, error) {
+	overrides, err := config.LoadOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := &config.OrphanedOverrides{Disabled: []string{}, DisabledPlugins: []string{}}
+	for _, key := range overrides.Disabled {
+		if strings.HasPrefix(key, pluginName+":") {
+			entries.Disabled = append(entries.Disabled, key)
+		}
+	}
+	if slices.Contains(overrides.DisabledPlugins, pluginName) {
+		entries.DisabledPlugins = append(entries.DisabledPlugins, pluginName)
+	}
+	return entries, nil
+}
+
+// moveDir renames src to dst, copying instead when they are on different
+// file systems.
+func moveDir(src, dst string) error {
+	err := os.Rename(src, dst)
+	if !isCrossDevice(err) {
+		return err
+	}
+
+	if err := copyDir(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
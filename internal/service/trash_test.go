@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wind/skill-router/internal/config"
+)
+
+func TestDeleteSkill_MovesToTrashAndRestores(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+	disabledDir := filepath.Join(tmpDir, "skills-disabled")
+	setupSkill(t, disabledDir, "notes")
+
+	svc := NewSkillService(tmpDir)
+	if err := svc.DeleteSkill("notes", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(disabledDir, "notes")); !os.IsNotExist(err) {
+		t.Fatal("skill should be gone from its directory")
+	}
+
+	items, err := svc.ListTrash()
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected 1 trash item, got %+v, %v", items, err)
+	}
+	item := items[0]
+	if item.Kind != TrashSkill || item.Name != "notes" || item.Enabled {
+		t.Errorf("unexpected trash item: %+v", item)
+	}
+
+	// Something new took the name in the meantime
+	setupSkill(t, disabledDir, "notes")
+	if _, err := svc.RestoreTrash(item.ID); !errors.Is(err, ErrSkillExists) {
+		t.Fatalf("expected ErrSkillExists, got %v", err)
+	}
+	os.RemoveAll(filepath.Join(disabledDir, "notes"))
+
+	if _, err := svc.RestoreTrash(item.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(disabledDir, "notes", "scripts", "run.sh")); err != nil {
+		t.Errorf("expected skill to be restored with its files: %v", err)
+	}
+	if items, _ := svc.ListTrash(); len(items) != 0 {
+		t.Errorf("expected empty trash, got %+v", items)
+	}
+	if _, err := svc.RestoreTrash(item.ID); !errors.Is(err, ErrTrashItemNotFound) {
+		t.Errorf("expected ErrTrashItemNotFound, got %v", err)
+	}
+}
+
+func TestDeletePlugin_RestoresOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	skillDir := filepath.Join(tmpDir, "plugins", "cache", "acme", "tools", "1.0.0", "skills", "lint")
+	os.MkdirAll(skillDir, 0755)
+	os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: lint\n---\n"), 0644)
+	config.DisablePluginSkill("tools", "lint")
+
+	svc := NewSkillService(tmpDir)
+	if err := svc.DeletePlugin("tools"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.IsPluginSkillDisabled("tools", "lint") {
+		t.Fatal("expected overrides to be forgotten on delete")
+	}
+
+	items, _ := svc.ListTrash()
+	if len(items) != 1 || items[0].Kind != TrashPlugin {
+		t.Fatalf("expected plugin in trash, got %+v", items)
+	}
+	if _, err := svc.RestoreTrash(items[0].ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(skillDir, "SKILL.md")); err != nil {
+		t.Errorf("expected plugin to be restored: %v", err)
+	}
+	if !config.IsPluginSkillDisabled("tools", "lint") {
+		t.Error("expected overrides to be restored")
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+	setupSkill(t, filepath.Join(tmpDir, "skills"), "old")
+	setupSkill(t, filepath.Join(tmpDir, "skills"), "new")
+
+	svc := NewSkillService(tmpDir)
+	svc.DeleteSkill("old", true)
+	time.Sleep(20 * time.Millisecond)
+	svc.DeleteSkill("new", true)
+
+	svc.SetTrashRetention(10 * time.Millisecond)
+	items, _ := svc.readTrash()
+	if err := svc.purgeTrash(items[0].DeletedAt.Add(5 * time.Millisecond)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	items, _ = svc.readTrash()
+	if len(items) != 1 || items[0].Name != "new" {
+		t.Errorf("expected only the newer item to be kept, got %+v", items)
+	}
+
+	if err := svc.EmptyTrash(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if items, _ := svc.ListTrash(); len(items) != 0 {
+		t.Errorf("expected empty trash, got %+v", items)
+	}
+}
+
+func TestMoveDir_KeepsExistingDestination(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := setupSkill(t, filepath.Join(tmpDir, "src"), "notes")
+	dst := setupSkill(t, filepath.Join(tmpDir, "dst"), "notes")
+
+	// Renaming onto a non-empty directory fails, and is not retried as a copy
+	if err := moveDir(src, dst); err == nil {
+		t.Fatal("expected an error moving onto an existing directory")
+	}
+	for _, dir := range []string{src, dst} {
+		if _, err := os.Stat(filepath.Join(dir, "SKILL.md")); err != nil {
+			t.Errorf("expected %s to be left alone: %v", dir, err)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wind/skill-router/internal/model"
+	"github.com/wind/skill-router/internal/usage"
+)
+
+var ErrUsageDisabled = errors.New("usage tracking is not enabled")
+
+// DefaultUnusedAfter is how long a skill may stay enabled without being
+// used before it is flagged as unused.
+const DefaultUnusedAfter = 90 * 24 * time.Hour
+
+// Usage windows, counted back from now.
+const (
+	week    = 7 * 24 * time.Hour
+	month   = 30 * 24 * time.Hour
+	quarter = 90 * 24 * time.Hour
+)
+
+// SkillUsage is how often a skill was invoked in the recorded transcripts.
+type SkillUsage struct {
+	SkillRef
+	Enabled      bool       `json:"enabled"`
+	EnabledSince *time.Time `json:"enabledSince,omitempty"`
+	LastUsed     *time.Time `json:"lastUsed,omitempty"`
+	Last7Days    int        `json:"last7Days"`
+	Last30Days   int        `json:"last30Days"`
+	Last90Days   int        `json:"last90Days"`
+	Total        int        `json:"total"`
+	Unused       bool       `json:"unused"` // enabled for longer than the threshold without being used in it
+}
+
+// UnknownUsage counts invocations of a name no installed skill has, such as
+// a skill that was deleted since.
+type UnknownUsage struct {
+	Skill    string    `json:"skill"`
+	Total    int       `json:"total"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+type UsageReport struct {
+	UnusedAfterDays int            `json:"unusedAfterDays"`
+	FirstRecorded   *time.Time     `json:"firstRecorded,omitempty"` // oldest invocation on record
+	Skills          []SkillUsage   `json:"skills"`
+	Unknown         []UnknownUsage `json:"unknown"`
+}
+
+// EnableUsage attributes the skill invocations t finds in Claude Code
+// transcripts to installed skills.
+func (s *SkillService) EnableUsage(t *usage.Tracker) {
+	s.usage = t
+}
+
+// SkillUsage scans the transcripts and reports the usage of every installed
+// skill, unused ones first, then the most used.
+func (s *SkillService) SkillUsage(unusedAfter time.Duration) (*UsageReport, error) {
+	if s.usage == nil {
+		return nil, ErrUsageDisabled
+	}
+
+	skills, err := s.ListSkills()
+	if err != nil {
+		return nil, err
+	}
+	invocations, err := s.usage.Scan()
+	if err != nil {
+		return nil, err
+	}
+	firstScan, err := s.usage.FirstScan()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	var enabled []string
+	for _, skill := range skills {
+		if skill.Enabled {
+			enabled = append(enabled, SkillID(skill))
+		}
+	}
+	since, err := s.usage.EnabledSince(enabled, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return summarizeUsage(skills, invocations, since, firstScan, now, unusedAfter), nil
+}
+
+// summarizeUsage attributes invocations to skills. Nothing is flagged as
+// unused until the record, which starts at the first scan or the oldest
+// invocation found, covers the whole unusedAfter window.
+func summarizeUsage(skills []model.Skill, invocations []usage.Invocation, since map[string]time.Time, firstScan, now time.Time, unusedAfter time.Duration) *UsageReport {
+	report := &UsageReport{
+		UnusedAfterDays: int(unusedAfter / (24 * time.Hour)),
+		Skills:          make([]SkillUsage, len(skills)),
+		Unknown:         []UnknownUsage{},
+	}
+
+	for i, skill := range skills {
+		u := SkillUsage{SkillRef: skillRef(skill), Enabled: skill.Enabled}
+		if t, ok := since[u.ID]; ok {
+			u.EnabledSince = &t
+		}
+		report.Skills[i] = u
+	}
+
+	unknown := make(map[string]*UnknownUsage)
+	for _, inv := range invocations {
+		if report.FirstRecorded == nil {
+			t := inv.Time
+			report.FirstRecorded = &t
+		}
+
+		i := attribute(skills, inv.Skill)
+		if i < 0 {
+			// Slash commands include built-ins like /clear, which are not skills
+			if inv.Via != usage.ViaTool {
+				continue
+			}
+			u, ok := unknown[inv.Skill]
+			if !ok {
+				u = &UnknownUsage{Skill: inv.Skill}
+				unknown[inv.Skill] = u
+			}
+			u.Total++
+			u.LastUsed = inv.Time
+			continue
+		}
+
+		u := &report.Skills[i]
+		u.Total++
+		t := inv.Time
+		u.LastUsed = &t
+		age := now.Sub(inv.Time)
+		if age <= quarter {
+			u.Last90Days++
+		}
+		if age <= month {
+			u.Last30Days++
+		}
+		if age <= week {
+			u.Last7Days++
+		}
+	}
+
+	recordedSince := firstScan
+	if report.FirstRecorded != nil && (recordedSince.IsZero() || report.FirstRecorded.Before(recordedSince)) {
+		recordedSince = *report.FirstRecorded
+	}
+	covered := !recordedSince.IsZero() && now.Sub(recordedSince) >= unusedAfter
+
+	for i := range report.Skills {
+		u := &report.Skills[i]
+		u.Unused = covered && u.Enabled && u.EnabledSince != nil && now.Sub(*u.EnabledSince) >= unusedAfter &&
+			(u.LastUsed == nil || now.Sub(*u.LastUsed) >= unusedAfter)
+	}
+	sort.SliceStable(report.Skills, func(i, j int) bool {
+		a, b := report.Skills[i], report.Skills[j]
+		if a.Unused != b.Unused {
+			return a.Unused
+		}
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.ID < b.ID
+	})
+
+	for _, u := range unknown {
+		report.Unknown = append(report.Unknown, *u)
+	}
+	sort.Slice(report.Unknown, func(i, j int) bool { return report.Unknown[i].Skill < report.Unknown[j].Skill })
+
+	return report
+}
+
+// attribute returns the index of the skill an invocation name refers to, or
+// -1. "plugin:name" names a plugin skill. A bare name prefers user skills,
+// which shadow plugin skills of the same name, then a plugin skill if only
+// one has that name.
+func attribute(skills []model.Skill, name string) int {
+	matches := func(skill model.Skill, n string) bool {
+		return strings.EqualFold(skill.Name, n) || strings.EqualFold(skill.FileName, n)
+	}
+
+	if plugin, n, ok := strings.Cut(name, ":"); ok {
+		for i, skill := range skills {
+			if skill.Source == "plugin" && skill.PluginName == plugin && matches(skill, n) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	var plugins []int
+	for i, skill := range skills {
+		if !matches(skill, name) {
+			continue
+		}
+		if skill.Source != "plugin" {
+			return i
+		}
+		plugins = append(plugins, i)
+	}
+	if len(plugins) != 1 {
+		return -1
+	}
+	return plugins[0]
+}
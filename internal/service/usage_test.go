@@ -0,0 +1,135 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/model"
+	"github.com/wind/skill-router/internal/usage"
+)
+
+func TestAttribute(t *testing.T) {
+	skills := []model.Skill{
+		{Name: "lint", FileName: "lint", Source: "plugin", PluginName: "tools"},
+		{Name: "lint", FileName: "my-lint", Source: "user"},
+		{Name: "format", FileName: "format", Source: "plugin", PluginName: "tools"},
+		{Name: "format", FileName: "format", Source: "plugin", PluginName: "style"},
+		{Name: "PDF Tools", FileName: "pdf", Source: "plugin", PluginName: "docs"},
+	}
+	cases := map[string]int{
+		"lint":         1, // the user skill shadows the plugin one
+		"tools:lint":   0,
+		"style:format": 3,
+		"format":       -1, // ambiguous between two plugins
+		"pdf":          4,
+		"docs:pdf":     4,
+		"other:lint":   -1,
+		"clear":        -1,
+	}
+	for name, want := range cases {
+		if got := attribute(skills, name); got != want {
+			t.Errorf("attribute(%q) = %d, want %d", name, got, want)
+		}
+	}
+}
+
+func TestSkillUsage(t *testing.T) {
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+
+	writeSkill := func(dir, name string) {
+		os.MkdirAll(filepath.Join(dir, name), 0755)
+		os.WriteFile(filepath.Join(dir, name, "SKILL.md"), []byte("---\nname: "+name+"\ndescription: Test\n---\nContent"), 0644)
+	}
+	for _, name := range []string{"notes", "essay", "stale", "fresh"} {
+		writeSkill(filepath.Join(tmpDir, "skills"), name)
+	}
+	writeSkill(filepath.Join(tmpDir, "skills-disabled"), "idle")
+	writeSkill(filepath.Join(tmpDir, "plugins", "cache", "acme", "tools", "1.0.0", "skills"), "lint")
+
+	svc := NewSkillService(tmpDir)
+	if _, err := svc.SkillUsage(DefaultUnusedAfter); !errors.Is(err, ErrUsageDisabled) {
+		t.Fatalf("expected ErrUsageDisabled, got %v", err)
+	}
+	// All but fresh were already seen enabled six months ago
+	tracker := usage.Open(tmpDir)
+	tracker.EnabledSince([]string{"user/notes", "user/essay", "user/stale", "plugin/tools/lint"}, time.Now().AddDate(0, -6, 0))
+	svc.EnableUsage(tracker)
+
+	line := func(id, skill string, age time.Duration) string {
+		ts := time.Now().Add(-age).UTC().Format(time.RFC3339)
+		return `{"type":"assistant","timestamp":"` + ts + `","message":{"content":[{"type":"tool_use","id":"` + id + `","name":"Skill","input":{"skill":"` + skill + `"}}]}}` + "\n"
+	}
+	day := 24 * time.Hour
+	transcript := line("1", "notes", 200*day) + line("2", "notes", 40*day) + line("3", "notes", 2*day) +
+		line("4", "tools:lint", 10*day) + line("5", "essay", 120*day) + line("6", "deleted", 5*day)
+	os.MkdirAll(filepath.Join(tmpDir, "projects", "app"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "projects", "app", "s.jsonl"), []byte(transcript), 0644)
+
+	report, err := svc.SkillUsage(DefaultUnusedAfter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := make(map[string]SkillUsage)
+	for _, u := range report.Skills {
+		byID[u.ID] = u
+	}
+
+	notes := byID["user/notes"]
+	if notes.Total != 3 || notes.Last90Days != 2 || notes.Last30Days != 1 || notes.Last7Days != 1 || notes.Unused {
+		t.Errorf("unexpected notes usage %+v", notes)
+	}
+	if lint := byID["plugin/tools/lint"]; lint.Total != 1 || lint.Last30Days != 1 || lint.Unused {
+		t.Errorf("unexpected lint usage %+v", lint)
+	}
+	if !byID["user/stale"].Unused || byID["user/stale"].LastUsed != nil {
+		t.Errorf("expected stale to be flagged, got %+v", byID["user/stale"])
+	}
+	if !byID["user/essay"].Unused {
+		t.Error("expected essay, last used 120 days ago, to be flagged")
+	}
+	if byID["user/fresh"].Unused || byID["user/idle"].Unused || byID["user/idle"].EnabledSince != nil {
+		t.Error("expected recently added and disabled skills not to be flagged")
+	}
+	if !report.Skills[0].Unused || !report.Skills[1].Unused || report.Skills[2].ID != "user/notes" {
+		t.Errorf("expected unused skills first, then the most used, got %+v", report.Skills[:3])
+	}
+	if len(report.Unknown) != 1 || report.Unknown[0].Skill != "deleted" {
+		t.Errorf("expected the deleted skill to be unknown, got %+v", report.Unknown)
+	}
+	if report.FirstRecorded == nil || time.Since(*report.FirstRecorded) < 199*day {
+		t.Errorf("expected the oldest invocation, got %v", report.FirstRecorded)
+	}
+	if report.UnusedAfterDays != 90 {
+		t.Errorf("expected 90 days, got %d", report.UnusedAfterDays)
+	}
+}
+
+func TestSummarizeUsage_NeedsFullRecord(t *testing.T) {
+	now := time.Now().UTC()
+	day := 24 * time.Hour
+	skills := []model.Skill{
+		{Name: "notes", FileName: "notes", Source: "user", Enabled: true},
+		{Name: "essay", FileName: "essay", Source: "user", Enabled: true},
+	}
+	since := map[string]time.Time{"user/notes": now.Add(-200 * day), "user/essay": now.Add(-200 * day)}
+	// notes was last used 60 days ago, but the transcripts only go back 30
+	invocations := []usage.Invocation{{ID: "1", Skill: "essay", Via: usage.ViaTool, Time: now.Add(-30 * day)}}
+
+	report := summarizeUsage(skills, invocations, since, now.Add(-time.Hour), now, DefaultUnusedAfter)
+	for _, u := range report.Skills {
+		if u.Unused {
+			t.Errorf("expected nothing flagged with 30 days on record, got %+v", u)
+		}
+	}
+
+	// Once the record covers the window, notes is flagged
+	report = summarizeUsage(skills, invocations, since, now.Add(-100*day), now, DefaultUnusedAfter)
+	if !report.Skills[0].Unused || report.Skills[0].ID != "user/notes" || report.Skills[1].Unused {
+		t.Errorf("expected only notes flagged, got %+v", report.Skills)
+	}
+}
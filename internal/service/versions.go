@@ -0,0 +1,133 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/wind/skill-router/internal/history"
+	"github.com/wind/skill-router/internal/versioning"
+)
+
+// SetVersioning commits every change made through the service to repo while
+// versioning is enabled on it.
+func (s *SkillService) SetVersioning(repo *versioning.Repo) {
+	s.versions = repo
+	s.watchOverrides()
+}
+
+// Versioning returns the repository set with SetVersioning, or nil.
+func (s *SkillService) Versioning() *versioning.Repo {
+	return s.versions
+}
+
+// SkillVersions lists the commits that changed a user skill, newest first.
+func (s *SkillService) SkillVersions(dirName string) ([]versioning.Version, error) {
+	paths, err := s.versionPaths(dirName)
+	if err != nil {
+		return nil, err
+	}
+	return s.versions.Log(paths...)
+}
+
+// DiffSkillVersions diffs a user skill between two versions, or between a
+// version and the current files if to is empty.
+func (s *SkillService) DiffSkillVersions(dirName, from, to string) (string, error) {
+	paths, err := s.versionPaths(dirName)
+	if err != nil {
+		return "", err
+	}
+	return s.versions.Diff(from, to, paths...)
+}
+
+// RevertSkill replaces a user skill's files with those of an earlier version
+// and commits the result. A skill that still exists keeps its enabled state;
+// a deleted one comes back where it was at that version.
+func (s *SkillService) RevertSkill(dirName, version string) error {
+	if _, err := s.versionPaths(dirName); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	saved, err := s.revertSkill(dirName, version)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.record(history.Operation{Type: history.OpRevertSkill, Target: dirName, Version: version, Saved: saved})
+	return nil
+}
+
+// revertSkill restores a skill from version and returns the commit holding
+// its files from before. The caller holds s.mu.
+func (s *SkillService) revertSkill(dirName, version string) (string, error) {
+	enabledPath := filepath.Join("skills", dirName)
+	disabledPath := filepath.Join("skills-disabled", dirName)
+
+	src := enabledPath
+	ok, err := s.versions.Exists(version, enabledPath)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		src = disabledPath
+	}
+
+	dst := src
+	if current, err := s.findUserSkill(dirName); err == nil {
+		dst, _ = filepath.Rel(s.baseDir, current)
+	}
+
+	s.index.invalidate(filepath.Join(s.baseDir, src))
+	s.index.invalidate(filepath.Join(s.baseDir, dst))
+	return s.versions.Restore(version, src, dst)
+}
+
+// unrevertSkill undoes a revert by restoring the skill from the commit saved
+// before it, or by moving it to the trash if it did not exist then. It
+// returns the trash item ID in the latter case. The caller holds s.mu.
+func (s *SkillService) unrevertSkill(op history.Operation) (string, error) {
+	paths, err := s.versionPaths(op.Target)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range paths {
+		ok, err := s.versions.Exists(op.Saved, p)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			_, err = s.revertSkill(op.Target, op.Saved)
+			return "", err
+		}
+	}
+
+	skillDir, err := s.findUserSkill(op.Target)
+	if err != nil {
+		return "", err
+	}
+	return s.trashSkill(skillDir)
+}
+
+func (s *SkillService) versionPaths(dirName string) ([]string, error) {
+	if s.versions == nil || !s.versions.Enabled() {
+		return nil, versioning.ErrDisabled
+	}
+	if !validDirName(dirName) {
+		return nil, fmt.Errorf("%w: %s", ErrSkillNotFound, dirName)
+	}
+	return []string{"skills/" + dirName, "skills-disabled/" + dirName}, nil
+}
+
+// commitVersion commits the tracked files if versioning is on. Like the
+// journal, it runs after the change has happened, so failures are not
+// reported to the caller.
+func (s *SkillService) commitVersion(message string) {
+	if s.versions == nil {
+		return
+	}
+	if err := s.versions.Commit(message); err != nil {
+		fmt.Fprintf(os.Stderr, "versioning: %v\n", err)
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wind/skill-router/internal/config"
+	"github.com/wind/skill-router/internal/history"
+	"github.com/wind/skill-router/internal/versioning"
+)
+
+func TestVersioning_CommitsAndReverts(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+	t.Cleanup(func() { config.SetChangeHook(nil) })
+
+	svc := NewSkillService(tmpDir)
+	svc.EnableHistory(history.Open(tmpDir))
+	repo := versioning.Open(tmpDir)
+	svc.SetVersioning(repo)
+	if err := repo.Enable(); err != nil {
+		t.Fatal(err)
+	}
+
+	svc.SaveSkill("notes", []byte("---\nname: notes\ndescription: v1\n---\n"), false)
+	svc.SaveSkill("notes", []byte("---\nname: notes\ndescription: v2\n---\n"), true)
+	svc.DisableSkill("notes")
+	config.DisablePluginSkill("tools", "lint")
+
+	versions, err := svc.SkillVersions("notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 3 || versions[0].Message != "Disable skill notes" || versions[2].Message != "Add skill notes" {
+		t.Fatalf("unexpected versions: %+v", versions)
+	}
+	all, _ := repo.Log()
+	if all[0].Message != "Disable tools:lint" {
+		t.Errorf("expected overrides change to be committed, got %q", all[0].Message)
+	}
+
+	diff, err := svc.DiffSkillVersions("notes", versions[2].Hash, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "+description: v2") {
+		t.Errorf("unexpected diff: %s", diff)
+	}
+
+	// Revert keeps the skill disabled
+	if err := svc.RevertSkill("notes", versions[2].Hash); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(tmpDir, "skills-disabled", "notes", "SKILL.md"))
+	if err != nil || !strings.Contains(string(content), "v1") {
+		t.Errorf("expected v1 in the disabled location, got %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "skills", "notes")); !os.IsNotExist(err) {
+		t.Error("revert should not bring back the enabled copy")
+	}
+
+	// The revert is journaled and can be undone and redone
+	if _, err := svc.Undo(); err != nil {
+		t.Fatalf("undo revert: %v", err)
+	}
+	content, _ = os.ReadFile(filepath.Join(tmpDir, "skills-disabled", "notes", "SKILL.md"))
+	if !strings.Contains(string(content), "v2") {
+		t.Errorf("expected v2 back after undo, got %q", content)
+	}
+	if _, err := svc.Redo(); err != nil {
+		t.Fatalf("redo revert: %v", err)
+	}
+	content, _ = os.ReadFile(filepath.Join(tmpDir, "skills-disabled", "notes", "SKILL.md"))
+	if !strings.Contains(string(content), "v1") {
+		t.Errorf("expected v1 again after redo, got %q", content)
+	}
+}
+
+func TestRevertSkill_KeepsUncommittedChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	tmpDir := t.TempDir()
+	config.Init(tmpDir)
+	t.Cleanup(func() { config.SetChangeHook(nil) })
+
+	svc := NewSkillService(tmpDir)
+	svc.EnableHistory(history.Open(tmpDir))
+	repo := versioning.Open(tmpDir)
+	svc.SetVersioning(repo)
+	if err := repo.Enable(); err != nil {
+		t.Fatal(err)
+	}
+
+	svc.SaveSkill("notes", []byte("---\nname: notes\ndescription: v1\n---\n"), false)
+	versions, _ := svc.SkillVersions("notes")
+	// Edited in place, without going through the service
+	skillFile := filepath.Join(tmpDir, "skills", "notes", "SKILL.md")
+	os.WriteFile(skillFile, []byte("---\nname: notes\ndescription: by hand\n---\n"), 0644)
+
+	if err := svc.RevertSkill("notes", versions[0].Hash); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Undo(); err != nil {
+		t.Fatalf("undo revert: %v", err)
+	}
+	if content, _ := os.ReadFile(skillFile); !strings.Contains(string(content), "by hand") {
+		t.Errorf("expected the hand edit back after undo, got %q", content)
+	}
+}
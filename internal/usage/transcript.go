@@ -0,0 +1,99 @@
+package usage
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// How a skill was invoked.
+const (
+	ViaTool    = "tool"    // Claude called the Skill tool
+	ViaCommand = "command" // the user typed /name
+)
+
+// Invocation is one use of a skill found in a transcript.
+type Invocation struct {
+	ID    string    `json:"id"`    // tool use ID or message UUID, the same across resumed sessions
+	Skill string    `json:"skill"` // as invoked: "name" or "plugin:name"
+	Via   string    `json:"via"`
+	Time  time.Time `json:"time"`
+}
+
+// Byte patterns a transcript line must contain to be worth decoding.
+var (
+	toolMarker    = []byte(`"Skill"`)
+	commandMarker = []byte(`<command-name>`)
+)
+
+var commandName = regexp.MustCompile(`<command-name>/?([^<\s]+)</command-name>`)
+
+type transcriptLine struct {
+	Type      string    `json:"type"`
+	UUID      string    `json:"uuid"`
+	Timestamp time.Time `json:"timestamp"`
+	Message   struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+type contentBlock struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Text  string `json:"text"`
+	Input struct {
+		Skill   string `json:"skill"`
+		Command string `json:"command"`
+	} `json:"input"`
+}
+
+// parseLine returns the skill invocations in one line of a transcript.
+// Lines that are not messages or cannot be decoded have none.
+func parseLine(line []byte) []Invocation {
+	if !bytes.Contains(line, toolMarker) && !bytes.Contains(line, commandMarker) {
+		return nil
+	}
+
+	var l transcriptLine
+	if err := json.Unmarshal(line, &l); err != nil || l.Timestamp.IsZero() {
+		return nil
+	}
+
+	var blocks []contentBlock
+	var text string
+	if err := json.Unmarshal(l.Message.Content, &text); err != nil {
+		if err := json.Unmarshal(l.Message.Content, &blocks); err != nil {
+			return nil
+		}
+	}
+
+	var found []Invocation
+	switch l.Type {
+	case "assistant":
+		for _, b := range blocks {
+			if b.Type != "tool_use" || b.Name != "Skill" {
+				continue
+			}
+			skill := b.Input.Skill
+			if skill == "" {
+				skill = b.Input.Command
+			}
+			if skill = strings.TrimPrefix(skill, "/"); skill != "" {
+				found = append(found, Invocation{ID: b.ID, Skill: skill, Via: ViaTool, Time: l.Timestamp})
+			}
+		}
+	case "user":
+		for _, b := range blocks {
+			if b.Type == "text" {
+				text += b.Text
+			}
+		}
+		if m := commandName.FindStringSubmatch(text); m != nil {
+			found = append(found, Invocation{ID: l.UUID, Skill: m[1], Via: ViaCommand, Time: l.Timestamp})
+		}
+	}
+	return found
+}
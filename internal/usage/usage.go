@@ -0,0 +1,218 @@
+// Package usage finds skill invocations in the session transcripts Claude
+// Code writes under ~/.claude/projects. Transcripts are read incrementally:
+// the offset reached in each file and the invocations found so far are kept
+// in a state file, so invocations outlive transcripts that Claude Code
+// cleans up.
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wind/skill-router/internal/config"
+)
+
+type fileState struct {
+	Offset      int64        `json:"offset"`
+	Invocations []Invocation `json:"invocations"`
+}
+
+type state struct {
+	Files        map[string]*fileState `json:"files"` // keyed by path relative to the transcripts directory
+	FirstScan    time.Time             `json:"firstScan,omitzero"`
+	Observed     time.Time             `json:"observed,omitzero"`
+	EnabledSince map[string]time.Time  `json:"enabledSince"` // skill ID to when it was first seen enabled
+}
+
+type Tracker struct {
+	transcripts string
+	path        string
+	mu          sync.Mutex
+}
+
+func Open(baseDir string) *Tracker {
+	return &Tracker{
+		transcripts: filepath.Join(baseDir, "projects"),
+		path:        filepath.Join(baseDir, "skill-usage.json"),
+	}
+}
+
+// Scan reads what was appended to the transcripts since the last scan and
+// returns every invocation recorded so far, oldest first. An invocation
+// that appears in several transcripts, as in resumed sessions, is returned
+// once.
+func (t *Tracker) Scan() ([]Invocation, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+
+	err = filepath.WalkDir(t.transcripts, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".jsonl") {
+			return nil
+		}
+		rel, err := filepath.Rel(t.transcripts, path)
+		if err != nil {
+			return err
+		}
+		file := st.Files[filepath.ToSlash(rel)]
+		if file == nil {
+			file = &fileState{}
+			st.Files[filepath.ToSlash(rel)] = file
+		}
+		return scanFile(path, file)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if st.FirstScan.IsZero() {
+		st.FirstScan = time.Now().UTC()
+	}
+	if err := t.save(st); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	invocations := []Invocation{}
+	for _, f := range st.Files {
+		for _, inv := range f.Invocations {
+			if inv.ID != "" && seen[inv.ID] {
+				continue
+			}
+			seen[inv.ID] = true
+			invocations = append(invocations, inv)
+		}
+	}
+	sort.SliceStable(invocations, func(i, j int) bool { return invocations[i].Time.Before(invocations[j].Time) })
+	return invocations, nil
+}
+
+// FirstScan returns when the transcripts were first scanned, or the zero
+// time if they never were. Invocations from before then are only on record
+// if their transcripts were still around.
+func (t *Tracker) FirstScan() (time.Time, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, err := t.load()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return st.FirstScan, nil
+}
+
+// scanFile parses the complete lines after st.Offset. A file that shrank
+// was rewritten, so it is parsed again from the start.
+func scanFile(path string, st *fileState) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < st.Offset {
+		*st = fileState{}
+	}
+	if info.Size() == st.Offset {
+		return nil
+	}
+	if _, err := f.Seek(st.Offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// A partial last line is still being written; read it next time
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		st.Offset += int64(len(line))
+		st.Invocations = append(st.Invocations, parseLine(line)...)
+	}
+}
+
+// EnabledSince records which skills are enabled now and returns when each
+// was first seen enabled. A skill that is not in enabled is forgotten, so it
+// starts over when it is enabled again. Nothing is known about skills before
+// they are first observed, so they start now, including on the first call.
+func (t *Tracker) EnabledSince(enabled []string, now time.Time) (map[string]time.Time, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+
+	since := make(map[string]time.Time, len(enabled))
+	for _, id := range enabled {
+		s, ok := st.EnabledSince[id]
+		if !ok {
+			s = now
+		}
+		since[id] = s
+	}
+	st.EnabledSince = since
+	st.Observed = now
+
+	if err := t.save(st); err != nil {
+		return nil, err
+	}
+	return since, nil
+}
+
+func (t *Tracker) load() (*state, error) {
+	st := &state{}
+	data, err := os.ReadFile(t.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, st); err != nil {
+			return nil, err
+		}
+	}
+	if st.Files == nil {
+		st.Files = make(map[string]*fileState)
+	}
+	if st.EnabledSince == nil {
+		st.EnabledSince = make(map[string]time.Time)
+	}
+	return st, nil
+}
+
+// save replaces the state file through a rename, so a crash never leaves a
+// truncated one behind.
+func (t *Tracker) save(st *state) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return config.WriteFileAtomic(t.path, data, 0644)
+}
@@ -0,0 +1,145 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func toolLine(id, skill, timestamp string) string {
+	return `{"type":"assistant","timestamp":"` + timestamp + `","message":{"role":"assistant","content":[` +
+		`{"type":"text","text":"Using a skill"},` +
+		`{"type":"tool_use","id":"` + id + `","name":"Skill","input":{"skill":"` + skill + `"}}]}}` + "\n"
+}
+
+func TestParseLine(t *testing.T) {
+	cases := []struct {
+		line string
+		want []Invocation
+	}{
+		{toolLine("t1", "pdf", "2025-03-01T10:00:00Z"), []Invocation{{ID: "t1", Skill: "pdf", Via: ViaTool}}},
+		{toolLine("t2", "tools:lint", "2025-03-01T10:00:00Z"), []Invocation{{ID: "t2", Skill: "tools:lint", Via: ViaTool}}},
+		{`{"type":"assistant","timestamp":"2025-03-01T10:00:00Z","message":{"content":[{"type":"tool_use","id":"t3","name":"Skill","input":{"command":"/notes"}}]}}`,
+			[]Invocation{{ID: "t3", Skill: "notes", Via: ViaTool}}},
+		{`{"type":"user","uuid":"u1","timestamp":"2025-03-01T10:00:00Z","message":{"role":"user","content":"<command-message>notes is running</command-message>\n<command-name>/notes</command-name>"}}`,
+			[]Invocation{{ID: "u1", Skill: "notes", Via: ViaCommand}}},
+		{`{"type":"user","uuid":"u2","timestamp":"2025-03-01T10:00:00Z","message":{"content":[{"type":"text","text":"<command-name>/tools:lint</command-name>"}]}}`,
+			[]Invocation{{ID: "u2", Skill: "tools:lint", Via: ViaCommand}}},
+		// A tool result that mentions the Skill tool is not an invocation
+		{`{"type":"user","timestamp":"2025-03-01T10:00:00Z","message":{"content":[{"type":"tool_result","content":"\"Skill\""}]}}`, nil},
+		{`{"type":"assistant","timestamp":"2025-03-01T10:00:00Z","message":{"content":[{"type":"tool_use","id":"t4","name":"Read","input":{}}]}}`, nil},
+		{`{"type":"summary","summary":"Skill"}`, nil},
+		{`{"type":"assistant","timestamp":"2025-03-01T10:00:00Z","message":{"content":"Skill`, nil},
+	}
+	for _, c := range cases {
+		got := parseLine([]byte(c.line))
+		if len(got) != len(c.want) {
+			t.Errorf("%s: expected %v, got %v", c.line, c.want, got)
+			continue
+		}
+		for i := range got {
+			want := c.want[i]
+			want.Time = time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+			if !got[i].Time.Equal(want.Time) || got[i].ID != want.ID || got[i].Skill != want.Skill || got[i].Via != want.Via {
+				t.Errorf("%s: expected %+v, got %+v", c.line, want, got[i])
+			}
+		}
+	}
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func skills(invocations []Invocation) []string {
+	var names []string
+	for _, inv := range invocations {
+		names = append(names, inv.Skill)
+	}
+	return names
+}
+
+func TestTracker_ScanIsIncremental(t *testing.T) {
+	tmpDir := t.TempDir()
+	session := filepath.Join(tmpDir, "projects", "-home-me-app", "a.jsonl")
+	second := toolLine("t2", "lint", "2025-03-02T10:00:00Z")
+
+	appendFile(t, session, toolLine("t1", "pdf", "2025-03-01T10:00:00Z")+second[:20])
+	tracker := Open(tmpDir)
+	got, err := tracker.Scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Skill != "pdf" {
+		t.Fatalf("expected pdf only, got %v", skills(got))
+	}
+
+	// The rest of the partial line arrives, and a resumed session repeats t1
+	appendFile(t, session, second[20:])
+	appendFile(t, filepath.Join(tmpDir, "projects", "-home-me-app", "b.jsonl"),
+		toolLine("t1", "pdf", "2025-03-01T10:00:00Z")+toolLine("t3", "notes", "2025-03-03T10:00:00Z"))
+	got, err = Open(tmpDir).Scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := skills(got); len(names) != 3 || names[0] != "pdf" || names[1] != "lint" || names[2] != "notes" {
+		t.Fatalf("expected pdf, lint and notes in order, got %v", names)
+	}
+
+	// A rewritten file is read again; a deleted one keeps its invocations
+	if err := os.WriteFile(session, []byte(toolLine("t4", "essay", "2025-03-04T10:00:00Z")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(tmpDir, "projects", "-home-me-app", "b.jsonl"))
+	got, err = tracker.Scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := skills(got); len(names) != 3 || names[0] != "pdf" || names[1] != "notes" || names[2] != "essay" {
+		t.Fatalf("expected pdf, notes and essay, got %v", names)
+	}
+}
+
+func TestTracker_ScanWithoutTranscripts(t *testing.T) {
+	got, err := Open(t.TempDir()).Scan()
+	if err != nil || len(got) != 0 {
+		t.Fatalf("expected no invocations, got %v, %v", got, err)
+	}
+}
+
+func TestTracker_EnabledSince(t *testing.T) {
+	tracker := Open(t.TempDir())
+	day1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	since, err := tracker.EnabledSince([]string{"user/a", "user/b"}, day1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !since["user/a"].Equal(day1) || !since["user/b"].Equal(day1) {
+		t.Fatalf("expected the first observation to start now, got %v", since)
+	}
+
+	// b is disabled, c is enabled later
+	day2 := day1.AddDate(0, 0, 1)
+	if _, err := tracker.EnabledSince([]string{"user/a"}, day2); err != nil {
+		t.Fatal(err)
+	}
+	day3 := day2.AddDate(0, 0, 1)
+	since, err = tracker.EnabledSince([]string{"user/a", "user/b", "user/c"}, day3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !since["user/a"].Equal(day1) || !since["user/b"].Equal(day3) || !since["user/c"].Equal(day3) {
+		t.Fatalf("expected b and c to start on day 3, got %v", since)
+	}
+}
@@ -0,0 +1,274 @@
+package versioning
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDisabled       = errors.New("versioning is not enabled")
+	ErrInvalidVersion = errors.New("invalid version")
+)
+
+// tracked are the paths, relative to the Claude directory, kept under
+// version control. Everything else in the directory is ignored.
+var tracked = []string{"skills", "skills-disabled", "skill-overrides.json"}
+
+var versionPattern = regexp.MustCompile(`^[0-9a-f]{4,40}$`)
+
+// Version is a commit that touched a path.
+type Version struct {
+	Hash    string    `json:"hash"`
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// Repo keeps the user skills and the overrides file in a git repository
+// whose git directory lives next to them, so the Claude directory itself
+// does not become a work tree for other tools.
+type Repo struct {
+	gitDir   string
+	workTree string
+	mu       sync.Mutex
+}
+
+func Open(baseDir string) *Repo {
+	return &Repo{gitDir: filepath.Join(baseDir, "skill-versions.git"), workTree: baseDir}
+}
+
+// Enabled reports whether versioning has been turned on.
+func (r *Repo) Enabled() bool {
+	info, err := os.Stat(r.gitDir)
+	return err == nil && info.IsDir()
+}
+
+// Enable creates the repository and commits the current state. It does
+// nothing if versioning is already on.
+func (r *Repo) Enable() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Enabled() {
+		return nil
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("versioning needs git: %w", err)
+	}
+
+	if out, err := exec.Command("git", "init", "--quiet", "--bare", r.gitDir).CombinedOutput(); err != nil {
+		return fmt.Errorf("git init: %s", strings.TrimSpace(string(out)))
+	}
+
+	exclude := "/*\n"
+	for _, p := range tracked {
+		exclude += "!/" + p + "\n"
+	}
+	if err := os.WriteFile(filepath.Join(r.gitDir, "info", "exclude"), []byte(exclude), 0644); err != nil {
+		os.RemoveAll(r.gitDir)
+		return err
+	}
+
+	if err := r.commit("Enable versioning"); err != nil {
+		os.RemoveAll(r.gitDir)
+		return err
+	}
+	return nil
+}
+
+// Commit records the current state of the tracked paths with message. It
+// does nothing if nothing changed or versioning is off.
+func (r *Repo) Commit(message string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.Enabled() {
+		return nil
+	}
+	return r.commit(message)
+}
+
+func (r *Repo) commit(message string) error {
+	if _, err := r.git("add", "--all"); err != nil {
+		return err
+	}
+	// Exit status 1 means there are staged changes
+	if _, err := r.git("diff", "--cached", "--quiet"); err == nil {
+		return nil
+	}
+	_, err := r.git("commit", "--quiet", "--allow-empty-message", "-m", message)
+	return err
+}
+
+// Log lists the commits that touched any of paths, newest first.
+func (r *Repo) Log(paths ...string) ([]Version, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.Enabled() {
+		return nil, ErrDisabled
+	}
+
+	versions := []Version{}
+	if _, err := r.git("rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		// Nothing has been committed yet
+		return versions, nil
+	}
+
+	out, err := r.git(append([]string{"log", "--format=%H%x1f%aI%x1f%s", "--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		t, _ := time.Parse(time.RFC3339, fields[1])
+		versions = append(versions, Version{Hash: fields[0], Time: t, Message: fields[2]})
+	}
+	return versions, nil
+}
+
+// Diff returns a unified diff of paths between two versions. An empty to
+// compares against the current files.
+func (r *Repo) Diff(from, to string, paths ...string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.Enabled() {
+		return "", ErrDisabled
+	}
+	if !versionPattern.MatchString(from) || (to != "" && !versionPattern.MatchString(to)) {
+		return "", ErrInvalidVersion
+	}
+
+	args := []string{"diff", "--find-renames", from}
+	if to != "" {
+		args = append(args, to)
+	} else {
+		// Include new files in the comparison with the work tree
+		if _, err := r.git("add", "--all", "--intent-to-add"); err != nil {
+			return "", err
+		}
+	}
+	return r.git(append(append(args, "--"), paths...)...)
+}
+
+// Exists reports whether path, relative to the work tree, exists in version.
+func (r *Repo) Exists(version, path string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.Enabled() {
+		return false, ErrDisabled
+	}
+	return r.exists(version, path)
+}
+
+func (r *Repo) exists(version, path string) (bool, error) {
+	if !versionPattern.MatchString(version) {
+		return false, ErrInvalidVersion
+	}
+	out, err := r.git("ls-tree", "--name-only", version, "--", filepath.ToSlash(path))
+	if err != nil {
+		return false, fmt.Errorf("%w: %s", ErrInvalidVersion, version)
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+// Restore replaces dst, relative to the work tree, with the content path
+// had in version. The current files are committed first, so changes made
+// behind the repository's back are not lost, and the hash of the commit
+// they are in is returned.
+func (r *Repo) Restore(version, path, dst string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.Enabled() {
+		return "", ErrDisabled
+	}
+	ok, err := r.exists(version, path)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: %s does not exist in %s", ErrInvalidVersion, path, version)
+	}
+
+	if err := r.commit(fmt.Sprintf("Save %s before restoring %.7s", filepath.ToSlash(dst), version)); err != nil {
+		return "", err
+	}
+	head, err := r.git("rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	// Check the version out into a scratch work tree with its own index
+	// first, so a failed checkout leaves the current files alone
+	tmp, err := os.MkdirTemp(r.workTree, ".skill-restore-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(tmp, ".index")}
+	if _, err := r.gitIn(tmp, env, "checkout", version, "--", filepath.ToSlash(path)); err != nil {
+		return "", err
+	}
+
+	// Swap it in, putting the current files back if that fails
+	target := filepath.Join(r.workTree, dst)
+	old := filepath.Join(tmp, ".old")
+	if err := os.Rename(target, old); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		os.Rename(old, target)
+		return "", err
+	}
+	if err := os.Rename(filepath.Join(tmp, path), target); err != nil {
+		os.Rename(old, target)
+		return "", err
+	}
+	return strings.TrimSpace(head), nil
+}
+
+func (r *Repo) git(args ...string) (string, error) {
+	return r.gitIn(r.workTree, nil, args...)
+}
+
+// gitIn runs git against workTree instead of the Claude directory, with env
+// added to the environment.
+func (r *Repo) gitIn(workTree string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{
+		"--git-dir=" + r.gitDir,
+		"--work-tree=" + workTree,
+		"-c", "user.name=Skill Router",
+		"-c", "user.email=skill-router@localhost",
+		"-c", "commit.gpgsign=false",
+	}, args...)...)
+	cmd.Dir = workTree
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.String(), fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return stdout.String(), fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,117 @@
+package versioning
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newRepo(t *testing.T) (*Repo, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	return Open(dir), dir
+}
+
+func TestRepo(t *testing.T) {
+	repo, dir := newRepo(t)
+	skillFile := filepath.Join(dir, "skills", "notes", "SKILL.md")
+	os.MkdirAll(filepath.Dir(skillFile), 0755)
+	os.WriteFile(skillFile, []byte("v1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "settings.json"), []byte("{}"), 0644)
+
+	if _, err := repo.Log("skills/notes"); !errors.Is(err, ErrDisabled) {
+		t.Fatalf("expected ErrDisabled, got %v", err)
+	}
+	if err := repo.Commit("ignored"); err != nil {
+		t.Fatalf("commit while disabled should be a no-op, got %v", err)
+	}
+
+	if err := repo.Enable(); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	os.WriteFile(skillFile, []byte("v2\n"), 0644)
+	if err := repo.Commit("Update skill notes"); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	// Nothing changed: no new commit
+	repo.Commit("Nothing")
+
+	versions, err := repo.Log("skills/notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].Message != "Update skill notes" {
+		t.Fatalf("unexpected versions: %+v", versions)
+	}
+
+	all, _ := repo.Log()
+	for _, v := range all {
+		out, _ := repo.git("show", "--name-only", "--format=", v.Hash)
+		if strings.Contains(out, "settings.json") {
+			t.Errorf("untracked file committed in %s", v.Message)
+		}
+	}
+
+	diff, err := repo.Diff(versions[1].Hash, versions[0].Hash, "skills/notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "-v1") || !strings.Contains(diff, "+v2") {
+		t.Errorf("unexpected diff: %s", diff)
+	}
+	if _, err := repo.Diff("--output=/tmp/x", "", "skills/notes"); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("expected ErrInvalidVersion, got %v", err)
+	}
+
+	// An edit made outside the repository is committed before the restore
+	os.WriteFile(skillFile, []byte("v3\n"), 0644)
+	saved, err := repo.Restore(versions[1].Hash, "skills/notes", "skills/notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(skillFile)
+	if string(content) != "v1\n" {
+		t.Errorf("expected v1 after restore, got %q", content)
+	}
+	if out, _ := repo.git("show", saved+":skills/notes/SKILL.md"); out != "v3\n" {
+		t.Errorf("expected the unsaved edit in %s, got %q", saved, out)
+	}
+
+	// Restoring to another place leaves the original path alone and
+	// replaces what was there
+	os.MkdirAll(filepath.Join(dir, "skills-disabled", "notes"), 0755)
+	os.WriteFile(filepath.Join(dir, "skills-disabled", "notes", "stale.md"), []byte("x"), 0644)
+	if _, err := repo.Restore(versions[0].Hash, "skills/notes", "skills-disabled/notes"); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "skills-disabled", "notes", "SKILL.md")); string(content) != "v2\n" {
+		t.Errorf("expected v2 in the restored copy, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "skills-disabled", "notes", "stale.md")); !os.IsNotExist(err) {
+		t.Errorf("expected the replaced files gone, got %v", err)
+	}
+	if content, _ := os.ReadFile(skillFile); string(content) != "v1\n" {
+		t.Errorf("expected the original left alone, got %q", content)
+	}
+	if scratch, _ := filepath.Glob(filepath.Join(dir, ".skill-restore-*")); len(scratch) != 0 {
+		t.Errorf("expected no scratch directories left, got %v", scratch)
+	}
+}
+
+func TestRepo_LogWithoutCommits(t *testing.T) {
+	repo, _ := newRepo(t)
+	// Nothing to track, so enabling commits nothing
+	if err := repo.Enable(); err != nil {
+		t.Fatal(err)
+	}
+	versions, err := repo.Log("skills/notes")
+	if err != nil || len(versions) != 0 {
+		t.Errorf("expected no versions, got %v, %v", versions, err)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package watcher
+
+func newBackend(targets []Target) (backend, error) {
+	return newPoller(targets, pollInterval), nil
+}
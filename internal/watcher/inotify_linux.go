@@ -0,0 +1,216 @@
+//go:build linux
+
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_CLOSE_WRITE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_ATTRIB |
+	syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+// rootRetryInterval is how often targets whose directory does not exist yet
+// are checked again.
+const rootRetryInterval = 2 * time.Second
+
+func newBackend(targets []Target) (backend, error) {
+	b, err := newInotify(targets, rootRetryInterval)
+	if err != nil {
+		// inotify can fail when the user's watch limit is exhausted.
+		return newPoller(targets, pollInterval), nil
+	}
+	return b, nil
+}
+
+type inotifyWatch struct {
+	path   string
+	target int
+	level  int
+}
+
+type inotify struct {
+	fd      int
+	file    *os.File
+	targets []Target
+	changes chan string
+	stop    chan struct{}
+	retry   sync.WaitGroup // retryRoots, which must be done before changes is closed
+
+	mu      sync.Mutex
+	watches map[int32]inotifyWatch
+	roots   map[int]int32 // target index -> watch descriptor of its root
+}
+
+func newInotify(targets []Target, retryInterval time.Duration) (*inotify, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &inotify{
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		targets: targets,
+		changes: make(chan string, 64),
+		stop:    make(chan struct{}),
+		watches: make(map[int32]inotifyWatch),
+		roots:   make(map[int]int32),
+	}
+
+	b.mu.Lock()
+	b.addMissingRoots()
+	b.mu.Unlock()
+
+	b.retry.Add(1)
+	go b.retryRoots(retryInterval)
+	go b.read()
+	return b, nil
+}
+
+func (b *inotify) Changes() <-chan string {
+	return b.changes
+}
+
+func (b *inotify) Close() error {
+	close(b.stop)
+	return b.file.Close()
+}
+
+// addMissingRoots watches every target root that exists but is not watched
+// yet and reports the paths it started watching. Must hold b.mu.
+func (b *inotify) addMissingRoots() []string {
+	var added []string
+	for i, t := range b.targets {
+		if _, ok := b.roots[i]; ok {
+			continue
+		}
+		if wd, ok := b.addTree(t.Path, i, 0); ok {
+			b.roots[i] = wd
+			added = append(added, t.Path)
+		}
+	}
+	return added
+}
+
+// addTree watches dir and its subdirectories down to the target's depth.
+// Must hold b.mu.
+func (b *inotify) addTree(dir string, target, level int) (int32, bool) {
+	wd, err := syscall.InotifyAddWatch(b.fd, dir, inotifyMask|syscall.IN_ONLYDIR)
+	if err != nil {
+		return 0, false
+	}
+	b.watches[int32(wd)] = inotifyWatch{path: dir, target: target, level: level}
+
+	if level < b.targets[target].Depth {
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if e.IsDir() {
+				b.addTree(filepath.Join(dir, e.Name()), target, level+1)
+			}
+		}
+	}
+	return int32(wd), true
+}
+
+func (b *inotify) retryRoots(interval time.Duration) {
+	defer b.retry.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			b.mu.Lock()
+			added := b.addMissingRoots()
+			b.mu.Unlock()
+			for _, path := range added {
+				b.send(path)
+			}
+		}
+	}
+}
+
+func (b *inotify) read() {
+	defer func() {
+		// retryRoots may still be sending until it sees b.stop
+		b.retry.Wait()
+		close(b.changes)
+	}()
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := b.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameBytes := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+int(raw.Len)]
+			offset += syscall.SizeofInotifyEvent + int(raw.Len)
+
+			name := string(nameBytes)
+			for len(name) > 0 && name[len(name)-1] == 0 {
+				name = name[:len(name)-1]
+			}
+
+			if path, ok := b.handle(raw.Wd, raw.Mask, name); ok {
+				b.send(path)
+			}
+		}
+	}
+}
+
+func (b *inotify) handle(wd int32, mask uint32, name string) (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if mask&syscall.IN_Q_OVERFLOW != 0 && len(b.targets) > 0 {
+		// Events were dropped; report that something changed.
+		return b.targets[0].Path, true
+	}
+
+	w, ok := b.watches[wd]
+	if !ok {
+		return "", false
+	}
+	t := b.targets[w.target]
+
+	if mask&syscall.IN_IGNORED != 0 {
+		delete(b.watches, wd)
+		if w.level == 0 {
+			delete(b.roots, w.target)
+		}
+		return "", false
+	}
+
+	if name == "" {
+		// Event on the watched directory itself.
+		return w.path, true
+	}
+	if w.level == 0 && !t.accepts(name) {
+		return "", false
+	}
+
+	path := filepath.Join(w.path, name)
+	if mask&syscall.IN_ISDIR != 0 && mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 && w.level < t.Depth {
+		b.addTree(path, w.target, w.level+1)
+	}
+	return path, true
+}
+
+func (b *inotify) send(path string) {
+	select {
+	case b.changes <- path:
+	case <-b.stop:
+	}
+}
@@ -0,0 +1,33 @@
+//go:build linux
+
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInotify_CloseWhileRetryingRoots(t *testing.T) {
+	for i := range 300 {
+		dir := t.TempDir()
+		var targets []Target
+		for j := range 20 {
+			targets = append(targets, Target{Path: filepath.Join(dir, string(rune('a'+j)))})
+		}
+		b, err := newInotify(targets, 100*time.Microsecond)
+		if err != nil {
+			t.Skipf("inotify not available: %v", err)
+		}
+
+		// The roots appear, so retryRoots starts watching and reporting them
+		for _, target := range targets {
+			os.Mkdir(target.Path, 0755)
+		}
+		time.Sleep(time.Duration(i%3) * 500 * time.Microsecond)
+		b.Close()
+		for range b.Changes() {
+		}
+	}
+}
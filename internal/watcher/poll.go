@@ -0,0 +1,108 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// pollInterval is used where inotify is not available.
+const pollInterval = 2 * time.Second
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// poller compares directory snapshots at a fixed interval.
+type poller struct {
+	targets []Target
+	changes chan string
+	stop    chan struct{}
+}
+
+func newPoller(targets []Target, interval time.Duration) *poller {
+	p := &poller{
+		targets: targets,
+		changes: make(chan string, 64),
+		stop:    make(chan struct{}),
+	}
+	go p.run(interval)
+	return p
+}
+
+func (p *poller) Changes() <-chan string {
+	return p.changes
+}
+
+func (p *poller) Close() error {
+	close(p.stop)
+	return nil
+}
+
+func (p *poller) run(interval time.Duration) {
+	defer close(p.changes)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	prev := p.snapshot()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+
+		cur := p.snapshot()
+		for path, st := range cur {
+			if old, ok := prev[path]; !ok || old != st {
+				p.send(path)
+			}
+		}
+		for path := range prev {
+			if _, ok := cur[path]; !ok {
+				p.send(path)
+			}
+		}
+		prev = cur
+	}
+}
+
+func (p *poller) send(path string) {
+	select {
+	case p.changes <- path:
+	case <-p.stop:
+	}
+}
+
+func (p *poller) snapshot() map[string]fileState {
+	snap := make(map[string]fileState)
+	for _, t := range p.targets {
+		walk(snap, t, t.Path, 0)
+	}
+	return snap
+}
+
+func walk(snap map[string]fileState, t Target, dir string, level int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		if level == 0 && !t.accepts(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+
+		path := filepath.Join(dir, e.Name())
+		snap[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		if e.IsDir() && level < t.Depth {
+			walk(snap, t, path, level+1)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package watcher
+
+import (
+	"sync"
+	"time"
+)
+
+// Target is a directory to watch. Depth is how many directory levels below
+// Path are watched as well (0 watches only Path itself). If Files is set,
+// only events for those names directly inside Path are reported.
+type Target struct {
+	Path  string
+	Depth int
+	Files []string
+}
+
+// Event is sent to subscribers once changes have settled.
+type Event struct {
+	Paths []string  `json:"paths"`
+	Time  time.Time `json:"time"`
+}
+
+// backend reports the path of every change it sees on Changes until Close.
+type backend interface {
+	Changes() <-chan string
+	Close() error
+}
+
+// Watcher watches a set of targets and fans debounced change events out to
+// subscribers.
+type Watcher struct {
+	debounce time.Duration
+	maxWait  time.Duration
+	backend  backend
+
+	mu     sync.Mutex
+	subs   map[chan Event]struct{}
+	closed bool
+	done   chan struct{}
+}
+
+// New starts watching targets. A burst of changes produces a single Event,
+// sent once no change has been seen for debounce (or at most every
+// 10*debounce while changes keep coming).
+func New(targets []Target, debounce time.Duration) (*Watcher, error) {
+	b, err := newBackend(targets)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		debounce: debounce,
+		maxWait:  10 * debounce,
+		backend:  b,
+		subs:     make(map[chan Event]struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.loop()
+	return w, nil
+}
+
+// Subscribe returns a channel of events and a function to stop receiving
+// them. Slow subscribers miss events rather than blocking the watcher.
+func (w *Watcher) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 1)
+
+	w.mu.Lock()
+	if w.closed {
+		close(ch)
+	} else {
+		w.subs[ch] = struct{}{}
+	}
+	w.mu.Unlock()
+
+	return ch, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if _, ok := w.subs[ch]; ok {
+			delete(w.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	err := w.backend.Close()
+	<-w.done
+	return err
+}
+
+func (w *Watcher) loop() {
+	defer close(w.done)
+	defer w.closeSubscribers()
+
+	var (
+		pending = make(map[string]struct{})
+		quiet   *time.Timer
+		quietC  <-chan time.Time
+		first   time.Time
+	)
+
+	for {
+		select {
+		case path, ok := <-w.backend.Changes():
+			if !ok {
+				return
+			}
+			if len(pending) == 0 {
+				first = time.Now()
+			}
+			pending[path] = struct{}{}
+
+			wait := w.debounce
+			if remaining := w.maxWait - time.Since(first); remaining < wait {
+				wait = max(remaining, 0)
+			}
+			if quiet == nil {
+				quiet = time.NewTimer(wait)
+			} else {
+				quiet.Reset(wait)
+			}
+			quietC = quiet.C
+
+		case <-quietC:
+			quietC = nil
+			w.publish(pending)
+			pending = make(map[string]struct{})
+		}
+	}
+}
+
+func (w *Watcher) publish(pending map[string]struct{}) {
+	ev := Event{Paths: make([]string, 0, len(pending)), Time: time.Now()}
+	for path := range pending {
+		ev.Paths = append(ev.Paths, path)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch := range w.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+func (w *Watcher) closeSubscribers() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch := range w.subs {
+		close(ch)
+		delete(w.subs, ch)
+	}
+}
+
+func (t Target) accepts(name string) bool {
+	if len(t.Files) == 0 {
+		return true
+	}
+	for _, f := range t.Files {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func waitEvent(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return Event{}
+}
+
+func TestWatcher_ReportsChangesInNestedDirs(t *testing.T) {
+	root := t.TempDir()
+	skillsDir := filepath.Join(root, "skills")
+	os.MkdirAll(skillsDir, 0755)
+
+	w, err := New([]Target{{Path: skillsDir, Depth: 1}}, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	events, unsubscribe := w.Subscribe()
+	defer unsubscribe()
+
+	// A new skill directory is picked up and then watched itself.
+	skillDir := filepath.Join(skillsDir, "my-skill")
+	os.Mkdir(skillDir, 0755)
+	waitEvent(t, events)
+
+	time.Sleep(100 * time.Millisecond)
+	os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: my-skill\n---\n"), 0644)
+
+	ev := waitEvent(t, events)
+	found := false
+	for _, p := range ev.Paths {
+		if p == filepath.Join(skillDir, "SKILL.md") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected SKILL.md in event paths, got %v", ev.Paths)
+	}
+}
+
+func TestWatcher_FiltersFilesAndDebounces(t *testing.T) {
+	root := t.TempDir()
+
+	w, err := New([]Target{{Path: root, Files: []string{"skill-overrides.json"}}}, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	events, unsubscribe := w.Subscribe()
+	defer unsubscribe()
+
+	os.WriteFile(filepath.Join(root, "unrelated.json"), []byte("{}"), 0644)
+	for i := 0; i < 5; i++ {
+		os.WriteFile(filepath.Join(root, "skill-overrides.json"), []byte("{}"), 0644)
+	}
+
+	ev := waitEvent(t, events)
+	for _, p := range ev.Paths {
+		if filepath.Base(p) != "skill-overrides.json" {
+			t.Errorf("unexpected path in event: %s", p)
+		}
+	}
+
+	select {
+	case ev := <-events:
+		t.Errorf("expected writes to be coalesced into one event, got another: %v", ev.Paths)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestPoller_DetectsChanges(t *testing.T) {
+	root := t.TempDir()
+
+	p := newPoller([]Target{{Path: root}}, 20*time.Millisecond)
+	defer p.Close()
+
+	time.Sleep(50 * time.Millisecond)
+	os.WriteFile(filepath.Join(root, "SKILL.md"), []byte("x"), 0644)
+
+	select {
+	case path := <-p.Changes():
+		if filepath.Base(path) != "SKILL.md" {
+			t.Errorf("unexpected path: %s", path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for change")
+	}
+}
@@ -7,11 +7,16 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
-	"strings"
+	"time"
 
 	"github.com/wind/skill-router/internal/config"
 	"github.com/wind/skill-router/internal/handler"
+	"github.com/wind/skill-router/internal/history"
+	"github.com/wind/skill-router/internal/profile"
 	"github.com/wind/skill-router/internal/service"
+	"github.com/wind/skill-router/internal/usage"
+	"github.com/wind/skill-router/internal/versioning"
+	"github.com/wind/skill-router/internal/watcher"
 )
 
 func main() {
@@ -19,59 +24,35 @@ func main() {
 	claudeDir := filepath.Join(homeDir, ".claude")
 
 	config.Init(claudeDir)
+	if migrated, err := config.MigrateOverrides(); err != nil {
+		fmt.Fprintf(os.Stderr, "skill-overrides.json: %v\n", err)
+		os.Exit(1)
+	} else if migrated {
+		fmt.Fprintf(os.Stderr, "Upgraded skill-overrides.json to version %d\n", config.CurrentVersion)
+	}
 	svc := service.NewSkillService(claudeDir)
-	h := handler.NewSkillHandler(svc)
-
-	http.HandleFunc("/api/skills", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == "GET" {
-			h.List(w, r)
-		}
-	})
+	svc.EnableHistory(history.Open(claudeDir))
+	svc.SetVersioning(versioning.Open(claudeDir))
+	svc.EnableUsage(usage.Open(claudeDir))
+	store := profile.NewStore(claudeDir)
 
-	http.HandleFunc("/api/skills/upload", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == "POST" {
-			h.Upload(w, r)
-		}
-	})
-
-	http.HandleFunc("/api/skills/install", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == "POST" {
-			h.Install(w, r)
-		}
-	})
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(svc, store, os.Args[1], os.Args[2:]))
+	}
 
-	http.HandleFunc("/api/skills/", func(w http.ResponseWriter, r *http.Request) {
-		path := r.URL.Path
-		switch {
-		case strings.HasSuffix(path, "/disable") && r.Method == "POST":
-			h.Disable(w, r)
-		case strings.HasSuffix(path, "/enable") && r.Method == "POST":
-			h.Enable(w, r)
-		case r.Method == "DELETE":
-			h.Delete(w, r)
-		default:
-			http.NotFound(w, r)
-		}
-	})
+	h := handler.NewSkillHandler(svc)
+	ph := handler.NewProfileHandler(svc, store)
+
+	// Live updates
+	var eh *handler.EventsHandler
+	if wt, err := watcher.New(svc.WatchTargets(), 300*time.Millisecond); err != nil {
+		fmt.Fprintf(os.Stderr, "File watcher disabled: %v\n", err)
+	} else {
+		defer wt.Close()
+		eh = handler.NewEventsHandler(wt)
+	}
 
-	// Plugin skill routes
-	http.HandleFunc("/api/plugins/", func(w http.ResponseWriter, r *http.Request) {
-		path := r.URL.Path
-		switch {
-		case strings.Contains(path, "/skills/") && strings.HasSuffix(path, "/disable") && r.Method == "POST":
-			h.DisablePluginSkill(w, r)
-		case strings.Contains(path, "/skills/") && strings.HasSuffix(path, "/enable") && r.Method == "POST":
-			h.EnablePluginSkill(w, r)
-		case strings.HasSuffix(path, "/disable") && r.Method == "POST":
-			h.DisablePlugin(w, r)
-		case strings.HasSuffix(path, "/enable") && r.Method == "POST":
-			h.EnablePlugin(w, r)
-		case r.Method == "DELETE":
-			h.DeletePlugin(w, r)
-		default:
-			http.NotFound(w, r)
-		}
-	})
+	http.Handle("/api/", handler.NewRouter(h, ph, eh))
 
 	// Serve static files
 	fileServer := http.FileServer(getFileSystem())
@@ -1,32 +1,111 @@
-import type { Skill } from '../types/skill'
+import type { Skill, SearchParams, SearchResult, Collision, ContextBudget, UsageReport, TrashItem, History, HistoryEntry, SkillVersion, RestoreReport, BulkTarget, BulkResponse, ErrorCode, Problem } from '../types/skill'
 
 const API_BASE = '/api'
 
+export class ApiError extends Error {
+  status: number
+  code?: ErrorCode
+
+  constructor(message: string, status: number, code?: ErrorCode) {
+    super(message)
+    this.status = status
+    this.code = code
+  }
+}
+
+// apiError turns a failed response into an ApiError, using the detail of a
+// problem body when the server sent one.
+async function apiError(res: Response, fallback: string): Promise<ApiError> {
+  if (res.headers.get('Content-Type')?.startsWith('application/problem+json')) {
+    const problem: Problem = await res.json()
+    return new ApiError(problem.detail || fallback, res.status, problem.code)
+  }
+  return new ApiError(fallback, res.status)
+}
+
 export async function listSkills(): Promise<Skill[]> {
   const res = await fetch(`${API_BASE}/skills`)
-  if (!res.ok) throw new Error('Failed to fetch skills')
+  if (!res.ok) throw await apiError(res, 'Failed to fetch skills')
+  return res.json()
+}
+
+export async function getCollisions(): Promise<Collision[]> {
+  const res = await fetch(`${API_BASE}/skills/collisions`)
+  if (!res.ok) throw await apiError(res, 'Failed to fetch collisions')
+  return res.json()
+}
+
+export async function getContextBudget(budget?: number): Promise<ContextBudget> {
+  const query = new URLSearchParams()
+  if (budget) query.set('budget', String(budget))
+
+  const res = await fetch(`${API_BASE}/skills/budget?${query}`)
+  if (!res.ok) throw await apiError(res, 'Failed to fetch context budget')
   return res.json()
 }
 
+export async function getSkillUsage(unusedDays?: number): Promise<UsageReport> {
+  const query = new URLSearchParams()
+  if (unusedDays) query.set('unusedDays', String(unusedDays))
+
+  const res = await fetch(`${API_BASE}/skills/usage?${query}`)
+  if (!res.ok) throw await apiError(res, 'Failed to fetch skill usage')
+  return res.json()
+}
+
+export async function searchSkills(params: SearchParams): Promise<SearchResult[]> {
+  const query = new URLSearchParams()
+  if (params.q) query.set('q', params.q)
+  if (params.source) query.set('source', params.source)
+  if (params.plugin) query.set('plugin', params.plugin)
+  if (params.enabled !== undefined) query.set('enabled', String(params.enabled))
+  params.tool?.forEach(tool => query.append('tool', tool))
+  params.tag?.forEach(tag => query.append('tag', tag))
+
+  const res = await fetch(`${API_BASE}/skills/search?${query}`)
+  if (!res.ok) throw await apiError(res, 'Failed to search skills')
+  return res.json()
+}
+
+export async function getSkillContent(fileName: string): Promise<{ content: string; etag: string }> {
+  const res = await fetch(`${API_BASE}/skills/${fileName}/content`)
+  if (!res.ok) throw await apiError(res, 'Failed to load skill content')
+  return { content: await res.text(), etag: res.headers.get('ETag') || '' }
+}
+
+export async function saveSkillContent(fileName: string, content: string, etag: string): Promise<string> {
+  const res = await fetch(`${API_BASE}/skills/${fileName}/content`, {
+    method: 'PUT',
+    headers: { 'Content-Type': 'text/markdown', 'If-Match': etag },
+    body: content
+  })
+  if (!res.ok) {
+    const err = await apiError(res, 'Failed to save skill content')
+    if (err.code === 'stale-content') err.message = 'Skill was changed elsewhere, reload it first'
+    throw err
+  }
+  return res.headers.get('ETag') || ''
+}
+
 export async function disableSkill(fileName: string): Promise<void> {
   const res = await fetch(`${API_BASE}/skills/${fileName}/disable`, {
     method: 'POST'
   })
-  if (!res.ok) throw new Error('Failed to disable skill')
+  if (!res.ok) throw await apiError(res, 'Failed to disable skill')
 }
 
 export async function enableSkill(fileName: string): Promise<void> {
   const res = await fetch(`${API_BASE}/skills/${fileName}/enable`, {
     method: 'POST'
   })
-  if (!res.ok) throw new Error('Failed to enable skill')
+  if (!res.ok) throw await apiError(res, 'Failed to enable skill')
 }
 
 export async function deleteSkill(fileName: string, enabled: boolean): Promise<void> {
   const res = await fetch(`${API_BASE}/skills/${fileName}?enabled=${enabled}`, {
     method: 'DELETE'
   })
-  if (!res.ok) throw new Error('Failed to delete skill')
+  if (!res.ok) throw await apiError(res, 'Failed to delete skill')
 }
 
 export async function uploadSkill(file: File, overwrite: boolean = false): Promise<void> {
@@ -39,21 +118,37 @@ export async function uploadSkill(file: File, overwrite: boolean = false): Promi
     body: formData
   })
   if (!res.ok) {
-    if (res.status === 409) throw new Error('File already exists')
-    throw new Error('Failed to upload skill')
+    const err = await apiError(res, 'Failed to upload skill')
+    if (err.code === 'already-exists') err.message = 'File already exists'
+    throw err
   }
 }
 
+export async function renameSkill(fileName: string, name: string): Promise<void> {
+  const res = await fetch(`${API_BASE}/skills/${fileName}/rename`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ name })
+  })
+  if (!res.ok) throw await apiError(res, 'Failed to rename skill')
+}
+
+export async function duplicateSkill(fileName: string, name: string): Promise<void> {
+  const res = await fetch(`${API_BASE}/skills/${fileName}/duplicate`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ name })
+  })
+  if (!res.ok) throw await apiError(res, 'Failed to duplicate skill')
+}
+
 export async function installFromGithub(url: string): Promise<{ installed: number }> {
   const res = await fetch(`${API_BASE}/skills/install`, {
     method: 'POST',
     headers: { 'Content-Type': 'application/json' },
     body: JSON.stringify({ url })
   })
-  if (!res.ok) {
-    const text = await res.text()
-    throw new Error(text || 'Failed to install skills')
-  }
+  if (!res.ok) throw await apiError(res, 'Failed to install skills')
   return res.json()
 }
 
@@ -61,33 +156,158 @@ export async function disablePluginSkill(pluginName: string, skillName: string):
   const res = await fetch(`${API_BASE}/plugins/${pluginName}/skills/${skillName}/disable`, {
     method: 'POST'
   })
-  if (!res.ok) throw new Error('Failed to disable plugin skill')
+  if (!res.ok) throw await apiError(res, 'Failed to disable plugin skill')
 }
 
 export async function enablePluginSkill(pluginName: string, skillName: string): Promise<void> {
   const res = await fetch(`${API_BASE}/plugins/${pluginName}/skills/${skillName}/enable`, {
     method: 'POST'
   })
-  if (!res.ok) throw new Error('Failed to enable plugin skill')
+  if (!res.ok) throw await apiError(res, 'Failed to enable plugin skill')
+}
+
+export async function forkPluginSkill(pluginName: string, skillName: string, name: string = '', disableOriginal: boolean = true): Promise<void> {
+  const res = await fetch(`${API_BASE}/plugins/${pluginName}/skills/${skillName}/fork`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ name, disableOriginal })
+  })
+  if (!res.ok) throw await apiError(res, 'Failed to fork plugin skill')
 }
 
 export async function disablePlugin(pluginName: string): Promise<void> {
   const res = await fetch(`${API_BASE}/plugins/${pluginName}/disable`, {
     method: 'POST'
   })
-  if (!res.ok) throw new Error('Failed to disable plugin')
+  if (!res.ok) throw await apiError(res, 'Failed to disable plugin')
 }
 
 export async function enablePlugin(pluginName: string): Promise<void> {
   const res = await fetch(`${API_BASE}/plugins/${pluginName}/enable`, {
     method: 'POST'
   })
-  if (!res.ok) throw new Error('Failed to enable plugin')
+  if (!res.ok) throw await apiError(res, 'Failed to enable plugin')
 }
 
 export async function deletePlugin(pluginName: string): Promise<void> {
   const res = await fetch(`${API_BASE}/plugins/${pluginName}`, {
     method: 'DELETE'
   })
-  if (!res.ok) throw new Error('Failed to delete plugin')
+  if (!res.ok) throw await apiError(res, 'Failed to delete plugin')
+}
+
+export function subscribeToChanges(onChange: () => void): () => void {
+  const source = new EventSource(`${API_BASE}/events`)
+  source.addEventListener('change', onChange)
+  return () => source.close()
+}
+
+export async function listTrash(): Promise<TrashItem[]> {
+  const res = await fetch(`${API_BASE}/trash`)
+  if (!res.ok) throw await apiError(res, 'Failed to fetch trash')
+  return res.json()
+}
+
+export async function restoreTrashItem(id: string): Promise<void> {
+  const res = await fetch(`${API_BASE}/trash/${encodeURIComponent(id)}/restore`, {
+    method: 'POST'
+  })
+  if (!res.ok) {
+    const err = await apiError(res, 'Failed to restore item')
+    if (err.code === 'already-exists') err.message = 'Something already exists at the original location'
+    throw err
+  }
+}
+
+export async function deleteTrashItem(id: string): Promise<void> {
+  const res = await fetch(`${API_BASE}/trash/${encodeURIComponent(id)}`, {
+    method: 'DELETE'
+  })
+  if (!res.ok) throw await apiError(res, 'Failed to delete item')
+}
+
+export async function emptyTrash(): Promise<void> {
+  const res = await fetch(`${API_BASE}/trash`, {
+    method: 'DELETE'
+  })
+  if (!res.ok) throw await apiError(res, 'Failed to empty trash')
+}
+
+export async function getHistory(limit: number = 50): Promise<History> {
+  const res = await fetch(`${API_BASE}/history?limit=${limit}`)
+  if (!res.ok) throw await apiError(res, 'Failed to fetch history')
+  return res.json()
+}
+
+export async function undo(): Promise<HistoryEntry> {
+  const res = await fetch(`${API_BASE}/history/undo`, { method: 'POST' })
+  if (!res.ok) throw await apiError(res, 'Failed to undo')
+  return res.json()
+}
+
+export async function redo(): Promise<HistoryEntry> {
+  const res = await fetch(`${API_BASE}/history/redo`, { method: 'POST' })
+  if (!res.ok) throw await apiError(res, 'Failed to redo')
+  return res.json()
+}
+
+export async function getVersioning(): Promise<{ enabled: boolean }> {
+  const res = await fetch(`${API_BASE}/versioning`)
+  if (!res.ok) throw await apiError(res, 'Failed to fetch versioning status')
+  return res.json()
+}
+
+export async function enableVersioning(): Promise<void> {
+  const res = await fetch(`${API_BASE}/versioning`, { method: 'POST' })
+  if (!res.ok) throw await apiError(res, 'Failed to enable versioning')
+}
+
+export async function getSkillVersions(fileName: string): Promise<SkillVersion[]> {
+  const res = await fetch(`${API_BASE}/skills/${fileName}/versions`)
+  if (!res.ok) throw await apiError(res, 'Failed to fetch skill versions')
+  return res.json()
+}
+
+export async function diffSkillVersions(fileName: string, from: string, to: string = ''): Promise<string> {
+  const query = new URLSearchParams({ from })
+  if (to) query.set('to', to)
+  const res = await fetch(`${API_BASE}/skills/${fileName}/diff?${query}`)
+  if (!res.ok) throw await apiError(res, 'Failed to diff skill versions')
+  return res.text()
+}
+
+export async function revertSkill(fileName: string, version: string): Promise<void> {
+  const res = await fetch(`${API_BASE}/skills/${fileName}/revert`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ version })
+  })
+  if (!res.ok) throw await apiError(res, 'Failed to revert skill')
+}
+
+export function backupUrl(): string {
+  return `${API_BASE}/backup`
+}
+
+export async function restoreBackup(file: File, mode: 'merge' | 'replace', dryRun: boolean): Promise<RestoreReport> {
+  const formData = new FormData()
+  formData.append('file', file)
+
+  const query = new URLSearchParams({ mode, dryRun: String(dryRun) })
+  const res = await fetch(`${API_BASE}/backup/restore?${query}`, {
+    method: 'POST',
+    body: formData
+  })
+  if (!res.ok) throw await apiError(res, 'Failed to restore backup')
+  return res.json()
+}
+
+export async function bulkAction(action: 'enable' | 'disable' | 'delete', targets: BulkTarget[]): Promise<BulkResponse> {
+  const res = await fetch(`${API_BASE}/skills/bulk`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ action, targets })
+  })
+  if (!res.ok && res.status !== 422) throw await apiError(res, 'Failed to apply bulk action')
+  return res.json()
 }
@@ -4,6 +4,195 @@ export interface Skill {
   fileName: string
   filePath: string
   enabled: boolean
-  source: 'user' | 'plugin'
+  source: 'user' | 'project' | 'plugin'
   pluginName: string
+  stateLayer: 'default' | 'global' | 'project'
+  tokens: number
+  allowedTools?: string[]
+  tags?: string[]
+  conflicts?: string[]
+}
+
+export interface SkillRef {
+  id: string
+  name: string
+  fileName: string
+  source: Skill['source']
+  pluginName?: string
+}
+
+export interface Collision {
+  kind: 'name' | 'similar-name' | 'similar-description'
+  name: string
+  similarity: number
+  skills: SkillRef[]
+  suggestions: { action: 'disable' | 'rename' | 'fork'; skill: string; reason: string }[]
+}
+
+export interface BudgetGroup {
+  name: string
+  skills: number
+  tokens: number
+}
+
+export interface BudgetWarning {
+  kind: 'over-budget' | 'long-description'
+  skill?: string
+  message: string
+}
+
+export interface ContextBudget {
+  budget: number
+  total: number
+  skills: number
+  bySource: BudgetGroup[]
+  byPlugin: BudgetGroup[]
+  warnings: BudgetWarning[]
+}
+
+export interface SkillUsage {
+  id: string
+  name: string
+  fileName: string
+  source: Skill['source']
+  pluginName?: string
+  enabled: boolean
+  enabledSince?: string
+  lastUsed?: string
+  last7Days: number
+  last30Days: number
+  last90Days: number
+  total: number
+  unused: boolean
+}
+
+export interface UnknownUsage {
+  skill: string
+  total: number
+  lastUsed: string
+}
+
+export interface UsageReport {
+  unusedAfterDays: number
+  firstRecorded?: string
+  skills: SkillUsage[]
+  unknown: UnknownUsage[]
+}
+
+export interface SnippetPart {
+  text: string
+  match?: boolean
+}
+
+export interface SearchResult {
+  skill: Skill
+  score: number
+  snippet: SnippetPart[] | null
+}
+
+export interface SearchParams {
+  q?: string
+  source?: Skill['source']
+  plugin?: string
+  enabled?: boolean
+  tool?: string[]
+  tag?: string[]
+}
+
+export interface TrashItem {
+  id: string
+  kind: 'skill' | 'plugin' | 'file'
+  name: string
+  source: 'user' | 'plugin'
+  originalPath: string
+  enabled: boolean
+  deletedAt: string
+  overrides?: { disabled: string[]; disabledPlugins: string[] }
+}
+
+export interface HistoryEntry {
+  seq: number
+  time: string
+  kind: 'do' | 'undo' | 'redo'
+  ref?: number
+  op: {
+    type: string
+    target?: string
+    path?: string
+    newName?: string
+    trashId?: string
+    version?: string
+    saved?: string
+    change?: {
+      addedDisabled?: string[]
+      removedDisabled?: string[]
+      addedDisabledPlugins?: string[]
+      removedDisabledPlugins?: string[]
+    }
+  }
+}
+
+export interface History {
+  entries: HistoryEntry[]
+  canUndo: number
+  canRedo: number
+}
+
+export interface SkillVersion {
+  hash: string
+  time: string
+  message: string
+}
+
+export interface PluginInfo {
+  org: string
+  name: string
+  version: string
+}
+
+export interface RestoreReport {
+  mode: 'merge' | 'replace'
+  dryRun: boolean
+  skills: { name: string; enabled: boolean; action: 'add' | 'replace' | 'keep' | 'remove' }[]
+  overrides: {
+    addedDisabled?: string[]
+    removedDisabled?: string[]
+    addedDisabledPlugins?: string[]
+    removedDisabledPlugins?: string[]
+  }
+  missingPlugins: PluginInfo[]
+  warnings: string[]
+}
+
+export interface BulkTarget {
+  type: 'user' | 'plugin-skill' | 'plugin'
+  name?: string
+  plugin?: string
+}
+
+export interface BulkResponse {
+  applied: boolean
+  error?: string
+  results: { target: BulkTarget; status: 'ok' | 'unchanged' | 'failed' | 'rolled-back' | 'not-run'; error?: string }[]
+}
+
+export type ErrorCode =
+  | 'not-found'
+  | 'already-exists'
+  | 'invalid-name'
+  | 'invalid-frontmatter'
+  | 'invalid-request'
+  | 'upstream-failure'
+  | 'rate-limited'
+  | 'stale-content'
+  | 'precondition-required'
+  | 'payload-too-large'
+  | 'conflict'
+  | 'internal-error'
+
+export interface Problem {
+  title: string
+  status: number
+  code: ErrorCode
+  detail?: string
 }